@@ -1,18 +1,21 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
+	"github.com/Beluga-Whale/management-api/internal/logging"
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
@@ -20,141 +23,200 @@ var DB *gorm.DB
 
 var TestDB *gorm.DB
 
-func LoadEnv() {
-	env := os.Getenv("APP_ENV")
-
+func LoadEnv(env string) error {
 	if env == "production" {
-		fmt.Println("✅ Running in production mode: using ENV variables only")
-		return
-	}
-
-	if env == "" {
-		env = "development"
+		slog.Info("Running in production mode: using ENV variables only")
+		return nil
 	}
 
 	envFileMap := map[string]string{
 		"development":    ".env",
 		"test":           ".env.test",
 		"test.localhost": ".env.test.localhost",
+		"test.sqlite":    ".env.test.sqlite",
 		"production":     ".env.production",
 	}
 
 	envFile, ok := envFileMap[env]
 	if !ok {
-		log.Fatalf("❌ Invalid APP_ENV: %s", env)
+		return fmt.Errorf("❌ Invalid APP_ENV: %s", env)
 	}
 
 	// ✅ ใช้ runtime.Caller เพื่อให้ไม่หลุด directory เวลา go test
 	_, currentFile, _, ok := runtime.Caller(0)
 	if !ok {
-		log.Fatal("❌ Failed to get current file path")
+		return errors.New("❌ Failed to get current file path")
 	}
 
 	// currentFile → /path/to/project/server/config/config.go
 	serverDir := filepath.Join(filepath.Dir(currentFile), "..") // เดินขึ้นจาก /config → /server
 	envPath := filepath.Join(serverDir, envFile)
 
-	fmt.Println("🔧 Loading env from:", envPath)
+	slog.Info("Loading env file", "path", envPath)
 
 	if err := godotenv.Load(envPath); err != nil {
-		log.Fatalf("❌ Failed to load env: %v", err)
+		return fmt.Errorf("❌ Failed to load env: %w", err)
 	}
+	return nil
 }
 
 
-func ConnectDB() {
-	var err error
+func ConnectDB(cfg DatabaseConfig) {
+	if err := OpenDB(cfg); err != nil {
+		fatal("Fail to connect DB", err)
+	}
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-	os.Getenv("HOST"),
-	os.Getenv("USER_NAME"),
-	os.Getenv("PASSWORD"),
-	os.Getenv("DATABASE_NAME"),
-	os.Getenv("PORT"),
-	os.Getenv("SSL_MODE"),
-	)
-
-	fmt.Println("🔍 ENV:", os.Getenv("HOST"), os.Getenv("PORT"), os.Getenv("DATABASE_NAME"), os.Getenv("USER_NAME"))
-
-
-	// New logger for detailed SQL logging
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-		SlowThreshold: time.Second, // Slow SQL threshold
-		LogLevel:      logger.Info, // Log level
-		Colorful:      true,        // Enable color
-		},
-	)
-
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: newLogger, // add Logger
-	})
+	if err := Migrate(DB); err != nil {
+		fatal("Failed to migrate database", err)
+	}
+}
+
+// NOTE - ต่ออย่างเดียวไม่ migrate admin CLI ใช้ตัวนี้ schema เปลี่ยนได้แค่ตอน serve หรือสั่ง migrate
+func OpenDB(cfg DatabaseConfig) error {
+	slog.Info("Connecting to database", "driver", cfg.Driver, "host", cfg.Host, "port", cfg.Port, "name", cfg.Name, "path", cfg.Path)
 
+	db, err := openDB(cfg)
 	if err != nil {
-		log.Fatal("Fail to connect DB : ",err)
+		return err
 	}
+	DB = db
 
-	fmt.Println("Connect DB Success!")
+	slog.Info("Connect DB Success!")
+	return nil
+}
 
-	DB.Exec("CREATE TYPE user_role AS ENUM ('admin', 'user');")
-	DB.Exec("CREATE TYPE task_status AS ENUM ('active', 'inactive');")
-	DB.Exec("CREATE TYPE task_priority AS ENUM ('low', 'medium', 'high');")
+// AutoMigrate จะตรวจสอบและอัปเดตฐานข้อมูล
+// NOTE - DB เดิมที่ role/status/priority เป็น ENUM ของ Postgres จะถูก ALTER เป็น varchar แล้วเพิ่ม check constraint ให้เอง
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(models.All()...); err != nil {
+		return err
+	}
+	return protectAuditLog(db)
+}
+
+func ConnectTestDB(cfg DatabaseConfig) {
+	var err error
+
+	cfg.SSLMode = "disable"
+
+	// เชื่อมต่อกับ database สำหรับการทดสอบ
+	TestDB, err = openDB(cfg)
 
-	// AutoMigrate จะตรวจสอบและอัปเดตฐานข้อมูล
-	err = DB.AutoMigrate(
-		&models.Users{},   // ให้ตรวจสอบตาราง Users
-		&models.Tasks{},   // ให้ตรวจสอบตาราง Tasks
-	)
 	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		fatal("Fail to connect to test DB", err)
 	}
 
+	slog.Info("Connected to Test DB Successfully!")
+
+	// ใช้ AutoMigrate เพื่ออัปเดตฐานข้อมูลสำหรับการทดสอบ
+	if err := Migrate(TestDB); err != nil {
+		fatal("Failed to migrate database for test", err)
+	}
 }
 
-func ConnectTestDB() {
-	
-	
-	var err error
+// NOTE - SQL ไปออกที่ slog ระดับ debug ระดับ log คุมด้วย LOG_LEVEL
+func openDB(cfg DatabaseConfig) (*gorm.DB, error) {
+	gormConfig := &gorm.Config{
+		Logger: logging.NewGormLogger(slog.Default(), time.Second),
+	}
+
+	if cfg.Driver != "sqlite" {
+		return gorm.Open(postgres.Open(cfg.DSN()), gormConfig)
+	}
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		os.Getenv("HOST"),
-		os.Getenv("USER_NAME"),
-		os.Getenv("PASSWORD"),
-		os.Getenv("DATABASE_NAME"),
-		os.Getenv("PORT"),
-	)
-
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold: time.Second, // Slow SQL threshold
-			LogLevel:      logger.Info, // Log level
-			Colorful:      true,        // Enable color
-		},
-	)
-
-	// เชื่อมต่อกับ PostgreSQL สำหรับการทดสอบ
-	TestDB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: newLogger, // เพิ่ม logger
-	})
+	db, err := gorm.Open(sqlite.Open(cfg.SQLiteDSN()), gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// NOTE - SQLite เขียนได้ทีละคนอยู่แล้ว connection เดียวกันไม่ให้ชน SQLITE_BUSY
+	// และ ":memory:" แต่ละ connection คือ DB คนละก้อน ถ้าเปิดหลายอันตารางจะหาย
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
 
+// NOTE - เรียกตอน shutdown หลัง request ที่ค้างอยู่เสร็จหมดแล้ว
+func CloseDB() error {
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatal("Fail to connect to test DB: ", err)
+		return err
 	}
+	return sqlDB.Close()
+}
 
-	fmt.Println("Connected to Test DB Successfully!")
+// NOTE - ใส่ quote ให้ทุกค่า password ที่มี space หรือ ' จะได้ไม่ทำให้ DSN พัง
+func (cfg DatabaseConfig) DSN() string {
+	quote := func(value string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+	}
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		quote(cfg.Host), quote(cfg.User), quote(cfg.Password), quote(cfg.Name), quote(cfg.Port), quote(cfg.SSLMode))
+	// NOTE - pgx ส่ง key ที่ไม่รู้จักไปเป็น runtime parameter ของ session เท่ากับ SET statement_timeout ทุก connection
+	if cfg.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	}
+	return dsn
+}
 
-	TestDB.Exec("CREATE TYPE user_role AS ENUM ('admin', 'user');")
-	TestDB.Exec("CREATE TYPE task_status AS ENUM ('active', 'inactive');")
-	TestDB.Exec("CREATE TYPE task_priority AS ENUM ('low', 'medium', 'high');")
+// NOTE - ไม่มี statement_timeout ฝั่ง SQLite query ที่ค้างหยุดได้ด้วย ctx ของ request อย่างเดียว
+// foreign key ของ SQLite ปิดไว้ตั้งต้น ต้องเปิดทุก connection ให้ตรงกับ Postgres
+func (cfg DatabaseConfig) SQLiteDSN() string {
+	return "file:" + cfg.Path + "?_foreign_keys=1&_busy_timeout=5000"
+}
 
-	// ใช้ AutoMigrate เพื่ออัปเดตฐานข้อมูลสำหรับการทดสอบ
-	err = TestDB.AutoMigrate(
-		&models.Users{},   // ให้ตรวจสอบตาราง Users
-		&models.Tasks{},   // ให้ตรวจสอบตาราง Tasks
-	)
+// NOTE - ชื่อที่ใช้เป็น label ของ metrics connection pool
+func (cfg DatabaseConfig) Label() string {
+	if cfg.Driver == "sqlite" {
+		return cfg.Path
+	}
+	return cfg.Name
+}
+
+// NOTE - audit_log เป็น append-only แก้หรือลบแถวไม่ได้แม้จะต่อ DB ตรงด้วย user ของ app
+func protectAuditLog(db *gorm.DB) error {
+	if db.Dialector.Name() == "sqlite" {
+		return protectAuditLogSQLite(db)
+	}
+
+	err := db.Exec(`
+		CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_log is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+		CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+			FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+	`).Error
 	if err != nil {
-		log.Fatal("Failed to migrate database for test:", err)
+		return fmt.Errorf("Failed to protect audit log: %w", err)
 	}
+	return nil
+}
+
+func protectAuditLogSQLite(db *gorm.DB) error {
+	err := db.Exec(`
+		CREATE TRIGGER IF NOT EXISTS audit_log_append_only_update BEFORE UPDATE ON audit_log
+		BEGIN
+			SELECT RAISE(ABORT, 'audit_log is append-only');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS audit_log_append_only_delete BEFORE DELETE ON audit_log
+		BEGIN
+			SELECT RAISE(ABORT, 'audit_log is append-only');
+		END;
+	`).Error
+	if err != nil {
+		return fmt.Errorf("Failed to protect audit log: %w", err)
+	}
+	return nil
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+type CORSConfig struct {
+	AllowOrigins     []string `yaml:"allow_origins"`
+	AllowMethods     []string `yaml:"allow_methods"`
+	AllowHeaders     []string `yaml:"allow_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+}
+
+type HTTPConfig struct {
+	Cookie utils.CookieConfig `yaml:"cookie"`
+	CORS   CORSConfig         `yaml:"cors"`
+	// NOTE - deadline ของ ctx ที่ handler ส่งลงไปถึง repository หมดเวลาแล้ว query ที่ค้างจะถูกยกเลิก
+	// client ตัดการเชื่อมต่อไม่ได้ยกเลิก query ดู NewTimeoutMiddleware
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+}
+
+// NOTE - ค่า default ของแต่ละ APP_ENV ยังทับได้ด้วย YAML หรือ COOKIE_* / CORS_*
+var httpConfigByEnv = map[string]HTTPConfig{
+	"development": {
+		Cookie: utils.CookieConfig{Name: "jwt", MaxAge: 72 * time.Hour, SameSite: "Lax"},
+		CORS:   CORSConfig{AllowOrigins: []string{"http://localhost:3000", "http://localhost:3001"}},
+	},
+	"test": {
+		Cookie: utils.CookieConfig{Name: "jwt", MaxAge: 72 * time.Hour, SameSite: "Lax"},
+		CORS:   CORSConfig{AllowOrigins: []string{"http://localhost:3000"}},
+	},
+	"test.localhost": {
+		Cookie: utils.CookieConfig{Name: "jwt", MaxAge: 72 * time.Hour, SameSite: "Lax"},
+		CORS:   CORSConfig{AllowOrigins: []string{"http://localhost:3000"}},
+	},
+	"test.sqlite": {
+		Cookie: utils.CookieConfig{Name: "jwt", MaxAge: 72 * time.Hour, SameSite: "Lax"},
+		CORS:   CORSConfig{AllowOrigins: []string{"http://localhost:3000"}},
+	},
+	"production": {
+		Cookie: utils.CookieConfig{Name: "jwt", Domain: ".belugatasks.dev", MaxAge: 72 * time.Hour, SameSite: "None", Secure: true},
+		CORS:   CORSConfig{AllowOrigins: []string{"https://belugatasks.dev"}},
+	},
+}
+
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func defaultHTTPConfig(env string) HTTPConfig {
+	cfg := httpConfigByEnv[env]
+	cfg.CORS.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	cfg.CORS.AllowHeaders = []string{"Content-Type", "Authorization", utils.CSRFHeaderName, "X-Request-ID"}
+	cfg.CORS.AllowCredentials = true
+	cfg.RequestTimeout = 30 * time.Second
+	return cfg
+}
+
+func (c CORSConfig) Validate() error {
+	if len(c.AllowOrigins) == 0 {
+		return errors.New("CORS allow origins is required")
+	}
+
+	// NOTE - browser ไม่ยอมส่ง cookie ถ้า origin เป็น * พร้อม credentials
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" && c.AllowCredentials {
+			return errors.New("CORS origin * can not be used with credentials")
+		}
+	}
+
+	return nil
+}
+
+func (c CORSConfig) Fiber() cors.Config {
+	return cors.Config{
+		AllowOrigins:     strings.Join(c.AllowOrigins, ","),
+		AllowMethods:     strings.Join(c.AllowMethods, ","),
+		AllowHeaders:     strings.Join(c.AllowHeaders, ","),
+		AllowCredentials: c.AllowCredentials,
+		// NOTE - ให้ frontend อ่าน request id ไปแนบตอน report bug ได้
+		ExposeHeaders: "X-Request-ID",
+	}
+}
@@ -0,0 +1,520 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// NOTE - Driver เป็น "postgres" หรือ "sqlite" ถ้าเป็น sqlite ใช้แค่ Path (":memory:" = DB ในหน่วยความจำ) ค่าอื่นไม่สน
+type DatabaseConfig struct {
+	Driver   string `yaml:"driver"`
+	Path     string `yaml:"path"`
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"ssl_mode"`
+	// NOTE - Postgres ตัด query ที่รันนานเกินนี้ทิ้งเอง ใช้กับทุก connection รวม background job ที่ไม่มี request ctx
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+}
+
+// NOTE - PrivateKey ใส่ PEM ได้หลาย key key แรกใช้เซ็น ที่เหลือใช้ verify อย่างเดียว (ดู utils.NewKeyring)
+// RotationInterval เป็น 0 คือไม่ rotate เอง ใช้ได้แค่ตอนไม่ได้ตั้ง PrivateKey
+type JWTConfig struct {
+	Algorithm        string        `yaml:"algorithm"`
+	PrivateKey       string        `yaml:"private_key"`
+	Issuer           string        `yaml:"issuer"`
+	Audience         string        `yaml:"audience"`
+	RotationInterval time.Duration `yaml:"rotation_interval"`
+}
+
+// NOTE - SMTPHost ว่างจะพิมพ์ email ออก stdout แทน หรือเขียนต่อท้าย File ถ้าตั้งไว้
+type MailConfig struct {
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     string `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	From         string `yaml:"from"`
+	File         string `yaml:"file"`
+}
+
+type WebAuthnConfig struct {
+	RPID      string   `yaml:"rp_id"`
+	RPOrigins []string `yaml:"rp_origins"`
+}
+
+type RateLimitConfig struct {
+	Store string `yaml:"store"`
+	Auth  string `yaml:"auth"`
+	API   string `yaml:"api"`
+}
+
+type OIDCProviderConfig struct {
+	Name         string `yaml:"name"`
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+}
+
+type OIDCConfig struct {
+	RedirectBaseURL    string               `yaml:"redirect_base_url"`
+	SuccessRedirectURL string               `yaml:"success_redirect_url"`
+	Providers          []OIDCProviderConfig `yaml:"providers"`
+}
+
+type ShutdownConfig struct {
+	Timeout time.Duration `yaml:"timeout"`
+	Delay   time.Duration `yaml:"delay"`
+}
+
+type MetricsConfig struct {
+	Token string `yaml:"token"`
+}
+
+// NOTE - Exporter เป็น "none" (ไม่ส่ง span ออกไปไหน), "stdout" (dev) หรือ "otlp"
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// NOTE - GORM log SQL ที่ระดับ debug ตั้ง debug ใน production เมื่อไหร่ log จะท่วม
+type LogConfig struct {
+	Level slog.Level `yaml:"level"`
+}
+
+type MagicLinkConfig struct {
+	CallbackURL        string `yaml:"callback_url"`
+	SuccessRedirectURL string `yaml:"success_redirect_url"`
+}
+
+// NOTE - config ทั้งหมดของ API โหลดครั้งเดียวตอน start แล้วส่งต่อให้แต่ละส่วน ห้ามอ่าน env เองข้างใน
+type Config struct {
+	Env              string          `yaml:"-"`
+	Port             string          `yaml:"port"`
+	Database         DatabaseConfig  `yaml:"database"`
+	JWT              JWTConfig       `yaml:"jwt"`
+	HTTP             HTTPConfig      `yaml:"http"`
+	Mail             MailConfig      `yaml:"mail"`
+	WebAuthn         WebAuthnConfig  `yaml:"webauthn"`
+	RateLimit        RateLimitConfig `yaml:"rate_limit"`
+	OIDC             OIDCConfig      `yaml:"oidc"`
+	MagicLink        MagicLinkConfig `yaml:"magic_link"`
+	ResetPasswordURL string          `yaml:"reset_password_url"`
+	Shutdown         ShutdownConfig  `yaml:"shutdown"`
+	Metrics          MetricsConfig   `yaml:"metrics"`
+	Tracing          TracingConfig   `yaml:"tracing"`
+	Log              LogConfig       `yaml:"log"`
+}
+
+// NOTE - ลำดับความสำคัญ default < YAML (CONFIG_FILE) < .env ของ APP_ENV < env จริง
+// ทุก key อ่านจาก <KEY>_FILE ได้ด้วย (Docker secrets)
+func Load() (*Config, error) {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+	if _, ok := httpConfigByEnv[env]; !ok {
+		return nil, fmt.Errorf("Invalid APP_ENV: %s", env)
+	}
+
+	if err := LoadEnv(env); err != nil {
+		return nil, err
+	}
+
+	cfg := defaultConfig(env)
+
+	if path, err := lookupEnv("CONFIG_FILE"); err != nil {
+		return nil, err
+	} else if path != "" {
+		if err := cfg.loadYAML(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	// NOTE - .env เดิมตั้ง PORT_API=":8080" รับได้ทั้งสองแบบ
+	cfg.Port = strings.TrimPrefix(cfg.Port, ":")
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func defaultConfig(env string) *Config {
+	return &Config{
+		Env:  env,
+		Port: "8080",
+		Database: DatabaseConfig{
+			Driver:           "postgres",
+			Host:             "localhost",
+			Port:             "5432",
+			SSLMode:          "disable",
+			StatementTimeout: 10 * time.Second,
+		},
+		JWT: JWTConfig{
+			Algorithm:        utils.AlgRS256,
+			Issuer:           "belugatasks",
+			Audience:         "belugatasks-api",
+		},
+		HTTP: defaultHTTPConfig(env),
+		Mail: MailConfig{SMTPPort: "587"},
+		WebAuthn: WebAuthnConfig{
+			RPID:      "localhost",
+			RPOrigins: []string{"http://localhost:3000"},
+		},
+		RateLimit: RateLimitConfig{Store: "memory", Auth: "20/1m", API: "300/1m"},
+		OIDC: OIDCConfig{
+			RedirectBaseURL:    "http://localhost:8080/api/user/oidc",
+			SuccessRedirectURL: "http://localhost:3000/",
+		},
+		MagicLink: MagicLinkConfig{
+			CallbackURL:        "http://localhost:8080/api/user/login/magic/callback",
+			SuccessRedirectURL: "http://localhost:3000/",
+		},
+		ResetPasswordURL: "http://localhost:3000/reset-password",
+		Shutdown:         ShutdownConfig{Timeout: 15 * time.Second},
+		Tracing:          TracingConfig{Exporter: "none", ServiceName: "belugatasks-api", SampleRatio: 1},
+		Log:              LogConfig{Level: defaultLogLevel(env)},
+	}
+}
+
+func defaultLogLevel(env string) slog.Level {
+	switch env {
+	case "development":
+		return slog.LevelDebug
+	case "test", "test.localhost", "test.sqlite":
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func (cfg *Config) Addr() string {
+	return ":" + cfg.Port
+}
+
+func (cfg *Config) loadYAML(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	// NOTE - key ที่สะกดผิดให้ error ไปเลย ไม่ใช่เงียบแล้วใช้ค่า default
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("Failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (cfg *Config) loadEnv() error {
+	r := &envReader{}
+
+	r.string(&cfg.Port, "PORT_API")
+
+	r.string(&cfg.Database.Driver, "DB_DRIVER")
+	r.string(&cfg.Database.Path, "SQLITE_PATH")
+	r.string(&cfg.Database.Host, "HOST")
+	r.string(&cfg.Database.Port, "PORT")
+	r.string(&cfg.Database.User, "USER_NAME")
+	r.string(&cfg.Database.Password, "PASSWORD")
+	r.string(&cfg.Database.Name, "DATABASE_NAME")
+	r.string(&cfg.Database.SSLMode, "SSL_MODE")
+	r.duration(&cfg.Database.StatementTimeout, "DB_STATEMENT_TIMEOUT")
+
+	r.string(&cfg.JWT.Algorithm, "JWT_ALGORITHM")
+	r.string(&cfg.JWT.PrivateKey, "JWT_PRIVATE_KEY")
+	r.string(&cfg.JWT.Issuer, "JWT_ISSUER")
+	r.string(&cfg.JWT.Audience, "JWT_AUDIENCE")
+	r.duration(&cfg.JWT.RotationInterval, "JWT_KEY_ROTATION_INTERVAL")
+
+	r.string(&cfg.HTTP.Cookie.Name, "COOKIE_NAME")
+	r.string(&cfg.HTTP.Cookie.Domain, "COOKIE_DOMAIN")
+	r.string(&cfg.HTTP.Cookie.SameSite, "COOKIE_SAMESITE")
+	r.duration(&cfg.HTTP.Cookie.MaxAge, "COOKIE_MAX_AGE")
+	r.bool(&cfg.HTTP.Cookie.Secure, "COOKIE_SECURE")
+	r.list(&cfg.HTTP.CORS.AllowOrigins, "CORS_ALLOW_ORIGINS")
+	r.list(&cfg.HTTP.CORS.AllowMethods, "CORS_ALLOW_METHODS")
+	r.list(&cfg.HTTP.CORS.AllowHeaders, "CORS_ALLOW_HEADERS")
+	r.duration(&cfg.HTTP.RequestTimeout, "REQUEST_TIMEOUT")
+
+	r.string(&cfg.Mail.SMTPHost, "SMTP_HOST")
+	r.string(&cfg.Mail.SMTPPort, "SMTP_PORT")
+	r.string(&cfg.Mail.SMTPUsername, "SMTP_USERNAME")
+	r.string(&cfg.Mail.SMTPPassword, "SMTP_PASSWORD")
+	r.string(&cfg.Mail.From, "MAIL_FROM")
+	r.string(&cfg.Mail.File, "MAIL_FILE")
+
+	r.string(&cfg.WebAuthn.RPID, "WEBAUTHN_RP_ID")
+	r.list(&cfg.WebAuthn.RPOrigins, "WEBAUTHN_RP_ORIGINS")
+
+	r.string(&cfg.RateLimit.Store, "RATE_LIMIT_STORE")
+	r.string(&cfg.RateLimit.Auth, "RATE_LIMIT_AUTH")
+	r.string(&cfg.RateLimit.API, "RATE_LIMIT_API")
+
+	r.string(&cfg.OIDC.RedirectBaseURL, "OIDC_REDIRECT_BASE_URL")
+	r.string(&cfg.OIDC.SuccessRedirectURL, "OIDC_SUCCESS_REDIRECT_URL")
+
+	// NOTE - OIDC_PROVIDERS=google,github แล้วตั้ง OIDC_<NAME>_ISSUER/_CLIENT_ID/_CLIENT_SECRET ของแต่ละตัว
+	// provider ที่มีใน YAML อยู่แล้วใช้ค่าเดิมเป็นฐาน
+	var names []string
+	r.list(&names, "OIDC_PROVIDERS")
+	if names != nil {
+		providers := make([]OIDCProviderConfig, 0, len(names))
+		for _, name := range names {
+			name = strings.ToLower(name)
+			provider := OIDCProviderConfig{Name: name}
+			for _, existing := range cfg.OIDC.Providers {
+				if existing.Name == name {
+					provider = existing
+				}
+			}
+
+			prefix := "OIDC_" + strings.ToUpper(name) + "_"
+			r.string(&provider.Issuer, prefix+"ISSUER")
+			r.string(&provider.ClientID, prefix+"CLIENT_ID")
+			r.string(&provider.ClientSecret, prefix+"CLIENT_SECRET")
+			providers = append(providers, provider)
+		}
+		cfg.OIDC.Providers = providers
+	}
+
+	r.string(&cfg.MagicLink.CallbackURL, "MAGIC_LINK_CALLBACK_URL")
+	r.string(&cfg.MagicLink.SuccessRedirectURL, "MAGIC_LINK_SUCCESS_REDIRECT_URL")
+	r.string(&cfg.ResetPasswordURL, "RESET_PASSWORD_URL")
+
+	r.duration(&cfg.Shutdown.Timeout, "SHUTDOWN_TIMEOUT")
+	r.duration(&cfg.Shutdown.Delay, "SHUTDOWN_DELAY")
+
+	r.string(&cfg.Metrics.Token, "METRICS_TOKEN")
+
+	r.string(&cfg.Tracing.Exporter, "TRACING_EXPORTER")
+	r.string(&cfg.Tracing.Endpoint, "OTEL_EXPORTER_OTLP_ENDPOINT")
+	r.string(&cfg.Tracing.ServiceName, "OTEL_SERVICE_NAME")
+	r.float(&cfg.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO")
+
+	r.text(&cfg.Log.Level, "LOG_LEVEL")
+
+	return errors.Join(r.errs...)
+}
+
+// NOTE - ตรวจทุกอย่างตอน start ทีเดียว รวม error ทั้งหมดให้แก้ได้ในรอบเดียว
+func (cfg *Config) Validate() error {
+	var errs []error
+	required := func(value string, name string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+		}
+	}
+	numeric := func(value string, name string) {
+		if _, err := strconv.Atoi(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number", name))
+		}
+	}
+
+	numeric(cfg.Port, "PORT_API")
+
+	switch cfg.Database.Driver {
+	case "postgres":
+		required(cfg.Database.Host, "HOST")
+		required(cfg.Database.User, "USER_NAME")
+		required(cfg.Database.Name, "DATABASE_NAME")
+		numeric(cfg.Database.Port, "PORT")
+	case "sqlite":
+		required(cfg.Database.Path, "SQLITE_PATH")
+	default:
+		errs = append(errs, fmt.Errorf("Invalid DB_DRIVER: %s", cfg.Database.Driver))
+	}
+	if cfg.Database.StatementTimeout < 0 {
+		errs = append(errs, errors.New("DB_STATEMENT_TIMEOUT must not be negative"))
+	}
+
+	switch cfg.JWT.Algorithm {
+	case utils.AlgRS256, utils.AlgEdDSA:
+	default:
+		errs = append(errs, fmt.Errorf("Unsupported JWT algorithm: %s", cfg.JWT.Algorithm))
+	}
+	required(cfg.JWT.Issuer, "JWT_ISSUER")
+	required(cfg.JWT.Audience, "JWT_AUDIENCE")
+	if cfg.JWT.RotationInterval < 0 {
+		errs = append(errs, errors.New("JWT_KEY_ROTATION_INTERVAL must not be negative"))
+	}
+	// NOTE - key ที่ rotate เองอยู่ใน memory ของแต่ละ process replica อื่นไม่รู้จัก ตั้ง key ไว้แล้วต้อง rotate ผ่าน JWT_PRIVATE_KEY แทน
+	if cfg.JWT.RotationInterval > 0 && cfg.JWT.PrivateKey != "" {
+		errs = append(errs, errors.New("JWT_KEY_ROTATION_INTERVAL can't be used with JWT_PRIVATE_KEY, rotate by adding keys to JWT_PRIVATE_KEY"))
+	}
+	// NOTE - ไม่มี key ตอน dev จะ generate ใหม่ทุกครั้งที่ start แต่ production ต้องมีไม่งั้น restart แล้ว user หลุดหมด
+	if cfg.Env == "production" && cfg.JWT.PrivateKey == "" {
+		errs = append(errs, errors.New("JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_FILE is required in production"))
+	}
+
+	if err := cfg.HTTP.Cookie.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := cfg.HTTP.CORS.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.HTTP.RequestTimeout <= 0 {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT must be positive"))
+	}
+
+	if cfg.Mail.SMTPHost != "" {
+		numeric(cfg.Mail.SMTPPort, "SMTP_PORT")
+		required(cfg.Mail.From, "MAIL_FROM")
+	}
+
+	required(cfg.WebAuthn.RPID, "WEBAUTHN_RP_ID")
+	if len(cfg.WebAuthn.RPOrigins) == 0 {
+		errs = append(errs, errors.New("WEBAUTHN_RP_ORIGINS is required"))
+	}
+
+	switch cfg.RateLimit.Store {
+	case "memory", "postgres":
+	default:
+		errs = append(errs, fmt.Errorf("Invalid RATE_LIMIT_STORE: %s", cfg.RateLimit.Store))
+	}
+	if _, err := utils.ParseRateLimit(cfg.RateLimit.Auth); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := utils.ParseRateLimit(cfg.RateLimit.API); err != nil {
+		errs = append(errs, err)
+	}
+
+	required(cfg.OIDC.RedirectBaseURL, "OIDC_REDIRECT_BASE_URL")
+	required(cfg.OIDC.SuccessRedirectURL, "OIDC_SUCCESS_REDIRECT_URL")
+	for _, provider := range cfg.OIDC.Providers {
+		prefix := "OIDC_" + strings.ToUpper(provider.Name) + "_"
+		required(provider.Name, "OIDC provider name")
+		required(provider.Issuer, prefix+"ISSUER")
+		required(provider.ClientID, prefix+"CLIENT_ID")
+		required(provider.ClientSecret, prefix+"CLIENT_SECRET")
+	}
+
+	required(cfg.MagicLink.CallbackURL, "MAGIC_LINK_CALLBACK_URL")
+	required(cfg.MagicLink.SuccessRedirectURL, "MAGIC_LINK_SUCCESS_REDIRECT_URL")
+	required(cfg.ResetPasswordURL, "RESET_PASSWORD_URL")
+
+	if cfg.Shutdown.Timeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if cfg.Shutdown.Delay < 0 {
+		errs = append(errs, errors.New("SHUTDOWN_DELAY must not be negative"))
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		required(cfg.Tracing.Endpoint, "OTEL_EXPORTER_OTLP_ENDPOINT")
+	default:
+		errs = append(errs, fmt.Errorf("Invalid TRACING_EXPORTER: %s", cfg.Tracing.Exporter))
+	}
+	required(cfg.Tracing.ServiceName, "OTEL_SERVICE_NAME")
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("TRACING_SAMPLE_RATIO must be between 0 and 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// NOTE - ค่าว่างถือว่าไม่ได้ตั้ง ถ้าไม่มี KEY จะอ่านจากไฟล์ที่ KEY_FILE ชี้ไป
+func lookupEnv(key string) (string, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read %s_FILE: %w", key, err)
+	}
+	// NOTE - ไฟล์ secret ส่วนใหญ่มี newline ปิดท้าย ถ้าไม่ตัดออก password จะผิด
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) lookup(key string) (string, bool) {
+	value, err := lookupEnv(key)
+	if err != nil {
+		r.errs = append(r.errs, err)
+		return "", false
+	}
+	return value, value != ""
+}
+
+func (r *envReader) string(dst *string, key string) {
+	if value, ok := r.lookup(key); ok {
+		*dst = value
+	}
+}
+
+func (r *envReader) list(dst *[]string, key string) {
+	if value, ok := r.lookup(key); ok {
+		*dst = splitList(value)
+	}
+}
+
+func (r *envReader) duration(dst *time.Duration, key string) {
+	if value, ok := r.lookup(key); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("Invalid %s: %w", key, err))
+			return
+		}
+		*dst = d
+	}
+}
+
+func (r *envReader) bool(dst *bool, key string) {
+	if value, ok := r.lookup(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("Invalid %s: %w", key, err))
+			return
+		}
+		*dst = b
+	}
+}
+
+func (r *envReader) float(dst *float64, key string) {
+	if value, ok := r.lookup(key); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("Invalid %s: %w", key, err))
+			return
+		}
+		*dst = f
+	}
+}
+
+func (r *envReader) text(dst encoding.TextUnmarshaler, key string) {
+	if value, ok := r.lookup(key); ok {
+		if err := dst.UnmarshalText([]byte(value)); err != nil {
+			r.errs = append(r.errs, fmt.Errorf("Invalid %s: %w", key, err))
+		}
+	}
+}
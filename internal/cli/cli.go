@@ -0,0 +1,377 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+)
+
+// NOTE - ใช้งานผ่าน docker exec <container> ./server <command> ... ได้เลย ใช้ config และ DB ชุดเดียวกับ serve
+const usage = `Usage: server <command> [flags]
+
+Commands:
+  serve                 Run the HTTP API (default)
+  migrate               Apply database migrations
+  seed                  Create demo accounts (--demo-accounts) and generate users with tasks
+  user create           Create a user (--admin for an admin)
+  user set-role         Change the role of a user
+  user reset-password   Set a new password and revoke all sessions
+  user disable          Block a user from signing in
+  task reassign         Move tasks to another user
+
+Run "server <command> -h" for the flags of a command.
+Passwords not given with --password are read from the first line of stdin.
+`
+
+// NOTE - คนที่ใช้ CLI ไม่มี IP ลง audit log ว่าทำผ่าน CLI แทน
+var cliClient = services.ClientInfo{UserAgent: "cli"}
+
+// NOTE - Env คือ APP_ENV ใช้กัน command ที่ไม่ควรรันใน production
+type App struct {
+	Env     string
+	Users   services.UserServiceInterface
+	Tasks   services.TaskServiceInterface
+	Seed    services.SeedServiceInterface
+	Migrate func(ctx context.Context) error
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+// NOTE - error ที่คืนไปให้ main พิมพ์ลง stderr แล้ว exit 1 ส่วนผลลัพธ์ออก stdout อย่างเดียวจะได้ pipe ต่อได้
+func (a *App) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(a.Stderr, usage)
+		return errors.New("Command is required")
+	}
+
+	switch args[0] {
+	case "migrate":
+		return a.migrate(ctx, args[1:])
+	case "seed":
+		return a.seed(ctx, args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(a.Stdout, usage)
+		return nil
+	}
+
+	if len(args) < 2 {
+		fmt.Fprint(a.Stderr, usage)
+		return fmt.Errorf("Unknown command: %s", args[0])
+	}
+
+	switch args[0] + " " + args[1] {
+	case "user create":
+		return a.userCreate(ctx, args[2:])
+	case "user set-role":
+		return a.userSetRole(ctx, args[2:])
+	case "user reset-password":
+		return a.userResetPassword(ctx, args[2:])
+	case "user disable":
+		return a.userDisable(ctx, args[2:])
+	case "task reassign":
+		return a.taskReassign(ctx, args[2:])
+	}
+
+	fmt.Fprint(a.Stderr, usage)
+	return fmt.Errorf("Unknown command: %s %s", args[0], args[1])
+}
+
+// NOTE - ทุก command มี --output ให้เลือก table (อ่านง่าย) หรือ json (เอาไปใช้ใน script)
+func (a *App) flags(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	output := fs.String("output", "table", "Output format: table or json")
+	return fs, output
+}
+
+func parse(fs *flag.FlagSet, output *string, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("Unexpected argument: %s", fs.Arg(0))
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("Invalid output format: %s", *output)
+	}
+	return nil
+}
+
+func (a *App) migrate(ctx context.Context, args []string) error {
+	fs, output := a.flags("migrate")
+	if err := parse(fs, output, args); err != nil {
+		return err
+	}
+
+	if err := a.Migrate(ctx); err != nil {
+		return fmt.Errorf("Failed to migrate database: %w", err)
+	}
+
+	return a.print(*output, statusView{Status: "migrated"})
+}
+
+func (a *App) userCreate(ctx context.Context, args []string) error {
+	fs, output := a.flags("user create")
+	email := fs.String("email", "", "Email of the new user (required)")
+	name := fs.String("name", "", "Display name")
+	password := fs.String("password", "", "Password, read from stdin when empty")
+	admin := fs.Bool("admin", false, "Create the user as an admin")
+	if err := parse(fs, output, args); err != nil {
+		return err
+	}
+
+	if err := a.readPassword(password); err != nil {
+		return err
+	}
+
+	role := models.User
+	if *admin {
+		role = models.Admin
+	}
+
+	user := &models.Users{Email: *email, Name: *name, Password: *password}
+	if err := a.Users.CreateUser(ctx, user, role, cliClient); err != nil {
+		return err
+	}
+
+	return a.print(*output, newUserView(user))
+}
+
+func (a *App) userSetRole(ctx context.Context, args []string) error {
+	fs, output := a.flags("user set-role")
+	email := fs.String("email", "", "Email of the user (required)")
+	role := fs.String("role", "", "New role: admin or user (required)")
+	if err := parse(fs, output, args); err != nil {
+		return err
+	}
+
+	user, err := a.Users.SetUserRole(ctx, *email, models.Role(*role), cliClient)
+	if err != nil {
+		return err
+	}
+
+	return a.print(*output, newUserView(user))
+}
+
+func (a *App) userResetPassword(ctx context.Context, args []string) error {
+	fs, output := a.flags("user reset-password")
+	email := fs.String("email", "", "Email of the user (required)")
+	password := fs.String("password", "", "New password, read from stdin when empty")
+	if err := parse(fs, output, args); err != nil {
+		return err
+	}
+
+	if err := a.readPassword(password); err != nil {
+		return err
+	}
+
+	if err := a.Users.ResetUserPassword(ctx, *email, *password, cliClient); err != nil {
+		return err
+	}
+
+	return a.print(*output, statusView{Status: "password reset", Email: *email})
+}
+
+func (a *App) userDisable(ctx context.Context, args []string) error {
+	fs, output := a.flags("user disable")
+	email := fs.String("email", "", "Email of the user (required)")
+	if err := parse(fs, output, args); err != nil {
+		return err
+	}
+
+	user, err := a.Users.DisableUser(ctx, *email, cliClient)
+	if err != nil {
+		return err
+	}
+
+	return a.print(*output, newUserView(user))
+}
+
+func (a *App) taskReassign(ctx context.Context, args []string) error {
+	fs, output := a.flags("task reassign")
+	id := fs.String("id", "", "ID of a single task to move")
+	from := fs.String("from", "", "Move every task of this email")
+	to := fs.String("to", "", "Email of the new owner (required)")
+	if err := parse(fs, output, args); err != nil {
+		return err
+	}
+
+	if (*id == "") == (*from == "") {
+		return errors.New("Exactly one of --id or --from is required")
+	}
+
+	var tasks []models.Tasks
+	if *id != "" {
+		task, err := a.Tasks.ReassignTask(ctx, *id, *to)
+		if err != nil {
+			return err
+		}
+		tasks = []models.Tasks{*task}
+	} else {
+		var err error
+		tasks, err = a.Tasks.ReassignUserTasks(ctx, *from, *to)
+		if err != nil {
+			return err
+		}
+	}
+
+	views := make([]taskView, 0, len(tasks))
+	for _, task := range tasks {
+		views = append(views, newTaskView(task))
+	}
+	return a.print(*output, views)
+}
+
+// NOTE - อ่านจาก stdin ได้ password จะไม่ค้างใน shell history (echo "$PASS" | docker exec -i ...)
+func (a *App) readPassword(password *string) error {
+	if *password != "" {
+		return nil
+	}
+	if a.Stdin == nil {
+		return errors.New("Password is required")
+	}
+
+	line, err := readLine(a.Stdin)
+	if err != nil {
+		return fmt.Errorf("Failed to read password from stdin: %w", err)
+	}
+	if line == "" {
+		return errors.New("Password is required")
+	}
+	*password = line
+	return nil
+}
+
+// NOTE - อ่านทีละ byte ไม่ใช้ bufio จะได้ไม่กิน stdin เกินบรรทัดแรก
+func readLine(r io.Reader) (string, error) {
+	var sb strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			sb.WriteByte(buf[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(sb.String(), "\r"), nil
+}
+
+// NOTE - view แยกจาก model ไม่ให้ password hash หรือ 2FA secret หลุดออกไปทาง --output json
+type userView struct {
+	ID       uint       `json:"id"`
+	Email    string     `json:"email"`
+	Name     string     `json:"name"`
+	Role     string     `json:"role"`
+	Disabled *time.Time `json:"disabled_at"`
+}
+
+func newUserView(user *models.Users) userView {
+	return userView{ID: user.ID, Email: user.Email, Name: user.Name, Role: string(user.Role), Disabled: user.DisabledAt}
+}
+
+type taskView struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	Priority  string    `json:"priority"`
+	Completed bool      `json:"completed"`
+	DueDate   time.Time `json:"due_date"`
+	UserID    uint      `json:"user_id"`
+}
+
+func newTaskView(task models.Tasks) taskView {
+	return taskView{
+		ID:        task.ID,
+		Title:     task.Title,
+		Status:    string(task.Status),
+		Priority:  string(task.Priority),
+		Completed: task.Completed,
+		DueDate:   task.DueDate,
+		UserID:    task.UserID,
+	}
+}
+
+type generatedView struct {
+	Seed       uint64 `json:"seed"`
+	Users      int    `json:"users"`
+	Tasks      int    `json:"tasks"`
+	Completed  int    `json:"completed"`
+	Overdue    int    `json:"overdue"`
+	Pending    int    `json:"pending"`
+	FirstEmail string `json:"first_email"`
+}
+
+type seedView struct {
+	Accounts  []userView     `json:"accounts,omitempty"`
+	Generated *generatedView `json:"generated,omitempty"`
+}
+
+type statusView struct {
+	Status string `json:"status"`
+	Email  string `json:"email,omitempty"`
+}
+
+func (a *App) print(output string, v any) error {
+	if output == "json" {
+		enc := json.NewEncoder(a.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	switch v := v.(type) {
+	case userView:
+		fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tDISABLED")
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", v.ID, v.Email, v.Name, v.Role, formatTime(v.Disabled))
+	case seedView:
+		if len(v.Accounts) > 0 {
+			fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tDISABLED")
+			for _, u := range v.Accounts {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", u.ID, u.Email, u.Name, u.Role, formatTime(u.Disabled))
+			}
+		}
+		if g := v.Generated; g != nil {
+			if len(v.Accounts) > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, "SEED\tUSERS\tTASKS\tCOMPLETED\tOVERDUE\tPENDING\tFIRST_EMAIL")
+			fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%s\n", g.Seed, g.Users, g.Tasks, g.Completed, g.Overdue, g.Pending, g.FirstEmail)
+		}
+	case []taskView:
+		fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tPRIORITY\tCOMPLETED\tDUE\tUSER_ID")
+		for _, t := range v {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\t%d\n", t.ID, t.Title, t.Status, t.Priority, t.Completed, formatTime(&t.DueDate), t.UserID)
+		}
+	case statusView:
+		fmt.Fprintln(w, "STATUS\tEMAIL")
+		fmt.Fprintf(w, "%s\t%s\n", v.Status, v.Email)
+	default:
+		return fmt.Errorf("Unsupported output: %T", v)
+	}
+	return w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,226 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/cli"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newApp(stdin string) (*cli.App, *services.UserServiceMock, *services.TaskServiceMock, *bytes.Buffer) {
+	userService := services.NewUserServiceMock()
+	taskService := services.NewTaskServiceMock()
+	stdout := &bytes.Buffer{}
+	app := &cli.App{
+		Users:   userService,
+		Tasks:   taskService,
+		Seed:    services.NewSeedServiceMock(),
+		Migrate: func(ctx context.Context) error { return nil },
+		Stdin:   strings.NewReader(stdin),
+		Stdout:  stdout,
+		Stderr:  io.Discard,
+	}
+	return app, userService, taskService, stdout
+}
+
+var cliClient = services.ClientInfo{UserAgent: "cli"}
+
+func TestUserCreate(t *testing.T) {
+	t.Run("Create admin with password from stdin", func(t *testing.T) {
+		app, userService, _, stdout := newApp("secret123\nignored\n")
+
+		userService.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *models.Users) bool {
+			return user.Email == "admin@gmail.com" && user.Password == "secret123"
+		}), models.Admin, cliClient).Run(func(args mock.Arguments) {
+			user := args.Get(1).(*models.Users)
+			user.ID = 7
+			user.Role = models.Admin
+			user.Password = "hashed"
+		}).Return(nil)
+
+		err := app.Run(context.Background(), []string{"user", "create", "--email", "admin@gmail.com", "--name", "Admin", "--admin", "--output", "json"})
+
+		assert.NoError(t, err)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(stdout.Bytes(), &body))
+		assert.Equal(t, float64(7), body["id"])
+		assert.Equal(t, "admin", body["role"])
+		assert.NotContains(t, stdout.String(), "hashed")
+		userService.AssertExpectations(t)
+	})
+
+	t.Run("Missing password", func(t *testing.T) {
+		app, userService, _, _ := newApp("")
+
+		err := app.Run(context.Background(), []string{"user", "create", "--email", "user@gmail.com"})
+
+		assert.EqualError(t, err, "Password is required")
+		userService.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		app, userService, _, _ := newApp("")
+
+		userService.On("CreateUser", mock.Anything, mock.Anything, models.User, cliClient).Return(errors.New("Email has already been used"))
+
+		err := app.Run(context.Background(), []string{"user", "create", "--email", "user@gmail.com", "--password", "secret123"})
+
+		assert.EqualError(t, err, "Email has already been used")
+	})
+}
+
+func TestUserSetRole(t *testing.T) {
+	t.Run("Table output", func(t *testing.T) {
+		app, userService, _, stdout := newApp("")
+
+		userService.On("SetUserRole", mock.Anything, "user@gmail.com", models.Admin, cliClient).Return(&models.Users{Email: "user@gmail.com", Role: models.Admin}, nil)
+
+		err := app.Run(context.Background(), []string{"user", "set-role", "--email", "user@gmail.com", "--role", "admin"})
+
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.Contains(t, lines[0], "EMAIL")
+		assert.Contains(t, lines[1], "user@gmail.com")
+		assert.Contains(t, lines[1], "admin")
+	})
+
+	t.Run("Invalid output format", func(t *testing.T) {
+		app, userService, _, _ := newApp("")
+
+		err := app.Run(context.Background(), []string{"user", "set-role", "--email", "user@gmail.com", "--role", "admin", "--output", "yaml"})
+
+		assert.EqualError(t, err, "Invalid output format: yaml")
+		userService.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserResetPassword(t *testing.T) {
+	app, userService, _, _ := newApp("newpass123\r\n")
+
+	userService.On("ResetUserPassword", mock.Anything, "user@gmail.com", "newpass123", cliClient).Return(nil)
+
+	err := app.Run(context.Background(), []string{"user", "reset-password", "--email", "user@gmail.com"})
+
+	assert.NoError(t, err)
+	userService.AssertExpectations(t)
+}
+
+func TestTaskReassign(t *testing.T) {
+	t.Run("Reassign every task of a user", func(t *testing.T) {
+		app, _, taskService, stdout := newApp("")
+
+		taskService.On("ReassignUserTasks", mock.Anything, "old@gmail.com", "new@gmail.com").Return([]models.Tasks{{Title: "Task 1", UserID: 2}, {Title: "Task 2", UserID: 2}}, nil)
+
+		err := app.Run(context.Background(), []string{"task", "reassign", "--from", "old@gmail.com", "--to", "new@gmail.com", "--output", "json"})
+
+		assert.NoError(t, err)
+		var body []map[string]any
+		assert.NoError(t, json.Unmarshal(stdout.Bytes(), &body))
+		assert.Len(t, body, 2)
+		assert.Equal(t, "Task 1", body[0]["title"])
+	})
+
+	t.Run("Reassign one task", func(t *testing.T) {
+		app, _, taskService, _ := newApp("")
+
+		taskService.On("ReassignTask", mock.Anything, "5", "new@gmail.com").Return(&models.Tasks{Title: "Task 5", UserID: 2}, nil)
+
+		err := app.Run(context.Background(), []string{"task", "reassign", "--id", "5", "--to", "new@gmail.com"})
+
+		assert.NoError(t, err)
+		taskService.AssertExpectations(t)
+	})
+
+	t.Run("Require id or from", func(t *testing.T) {
+		app, _, _, _ := newApp("")
+
+		assert.EqualError(t, app.Run(context.Background(), []string{"task", "reassign", "--to", "new@gmail.com"}), "Exactly one of --id or --from is required")
+		assert.EqualError(t, app.Run(context.Background(), []string{"task", "reassign", "--id", "5", "--from", "old@gmail.com", "--to", "new@gmail.com"}), "Exactly one of --id or --from is required")
+	})
+}
+
+func TestSeed(t *testing.T) {
+	t.Run("Demo accounts only", func(t *testing.T) {
+		app, userService, _, _ := newApp("")
+
+		userService.On("GetUserByEmail", mock.Anything, "admin@example.com").Return(&models.Users{Email: "admin@example.com", Role: models.Admin}, nil)
+		userService.On("GetUserByEmail", mock.Anything, "demo@example.com").Return(nil, nil)
+		userService.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *models.Users) bool {
+			return user.Email == "demo@example.com"
+		}), models.User, cliClient).Return(nil)
+
+		err := app.Run(context.Background(), []string{"seed", "--demo-accounts", "--password", "secret123"})
+
+		assert.NoError(t, err)
+		userService.AssertNumberOfCalls(t, "CreateUser", 1)
+		app.Seed.(*services.SeedServiceMock).AssertNotCalled(t, "Generate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Generate users and tasks", func(t *testing.T) {
+		app, userService, _, stdout := newApp("")
+		seedService := services.NewSeedServiceMock()
+		app.Seed = seedService
+
+		seedService.On("Generate", mock.Anything, services.SeedOptions{Users: 100, TasksPerUser: 5, Seed: 42, BatchSize: 1000, Password: "secret123"}).Return(&services.SeedResult{Users: 100, Tasks: 500, Overdue: 180, Pending: 320, Completed: 170, FirstEmail: "ava.kim.42.0@seed.example.com"}, nil)
+
+		err := app.Run(context.Background(), []string{"seed", "--password", "secret123", "--users", "100", "--tasks", "5", "--seed", "42", "--batch-size", "1000", "--output", "json"})
+
+		assert.NoError(t, err)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(stdout.Bytes(), &body))
+		generated := body["generated"].(map[string]any)
+		assert.Equal(t, float64(500), generated["tasks"])
+		assert.Equal(t, float64(42), generated["seed"])
+		assert.NotContains(t, body, "accounts")
+		seedService.AssertExpectations(t)
+		userService.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Nothing to seed", func(t *testing.T) {
+		app, userService, _, _ := newApp("")
+
+		err := app.Run(context.Background(), []string{"seed", "--password", "secret123"})
+
+		assert.EqualError(t, err, "Nothing to seed, use --demo-accounts or --users")
+		userService.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Refuse in production", func(t *testing.T) {
+		app, userService, _, _ := newApp("")
+		app.Env = "production"
+
+		err := app.Run(context.Background(), []string{"seed", "--demo-accounts", "--users", "10", "--password", "secret123"})
+
+		assert.EqualError(t, err, "Seed is disabled in production")
+		userService.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+		app.Seed.(*services.SeedServiceMock).AssertNotCalled(t, "Generate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Migrate", func(t *testing.T) {
+		app, _, _, _ := newApp("")
+		app.Migrate = func(ctx context.Context) error { return errors.New("boom") }
+
+		err := app.Run(context.Background(), []string{"migrate"})
+
+		assert.EqualError(t, err, "Failed to migrate database: boom")
+	})
+
+	t.Run("Unknown command", func(t *testing.T) {
+		app, _, _, _ := newApp("")
+
+		assert.EqualError(t, app.Run(context.Background(), nil), "Command is required")
+		assert.EqualError(t, app.Run(context.Background(), []string{"user", "delete"}), "Unknown command: user delete")
+	})
+}
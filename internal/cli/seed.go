@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+)
+
+// NOTE - บัญชีสำหรับลองใช้ local/staging รันซ้ำได้ คนไหนมีอยู่แล้วก็ข้าม
+var seedAccounts = []struct {
+	Email string
+	Name  string
+	Role  models.Role
+}{
+	{Email: "admin@example.com", Name: "Demo Admin", Role: models.Admin},
+	{Email: "demo@example.com", Name: "Demo User", Role: models.User},
+}
+
+// NOTE - --users > 0 จะ generate user และ task เพิ่ม (seed --users 1000 --tasks 50 --seed 42)
+// บัญชี demo (มี admin) สร้างเฉพาะตอนส่ง --demo-accounts และ seed ทั้ง command ใช้ใน production ไม่ได้
+// user ที่ generate ใช้ password เดียวกับบัญชี demo
+func (a *App) seed(ctx context.Context, args []string) error {
+	fs, output := a.flags("seed")
+	demoAccounts := fs.Bool("demo-accounts", false, "Create the demo accounts admin@example.com and demo@example.com")
+	password := fs.String("password", "", "Password of the demo and generated accounts, read from stdin when empty")
+	users := fs.Int("users", 0, "Number of users to generate")
+	tasks := fs.Int("tasks", 20, "Number of tasks per generated user")
+	seed := fs.Uint64("seed", 1, "Seed value, the same seed always generates the same data")
+	batchSize := fs.Int("batch-size", services.DefaultSeedBatchSize, "Rows per INSERT statement")
+	if err := parse(fs, output, args); err != nil {
+		return err
+	}
+
+	if a.Env == "production" {
+		return errors.New("Seed is disabled in production")
+	}
+	if !*demoAccounts && *users <= 0 {
+		return errors.New("Nothing to seed, use --demo-accounts or --users")
+	}
+
+	if err := a.readPassword(password); err != nil {
+		return err
+	}
+
+	var view seedView
+	if *demoAccounts {
+		view.Accounts = make([]userView, 0, len(seedAccounts))
+		for _, account := range seedAccounts {
+			user, err := a.Users.GetUserByEmail(ctx, account.Email)
+			if err != nil {
+				return err
+			}
+
+			if user == nil {
+				user = &models.Users{Email: account.Email, Name: account.Name, Password: *password}
+				if err := a.Users.CreateUser(ctx, user, account.Role, cliClient); err != nil {
+					return err
+				}
+			}
+			view.Accounts = append(view.Accounts, newUserView(user))
+		}
+	}
+
+	if *users > 0 {
+		result, err := a.Seed.Generate(ctx, services.SeedOptions{
+			Users:        *users,
+			TasksPerUser: *tasks,
+			Seed:         *seed,
+			BatchSize:    *batchSize,
+			Password:     *password,
+		})
+		if err != nil {
+			return err
+		}
+		view.Generated = &generatedView{
+			Seed:       *seed,
+			Users:      result.Users,
+			Tasks:      result.Tasks,
+			Completed:  result.Completed,
+			Overdue:    result.Overdue,
+			Pending:    result.Pending,
+			FirstEmail: result.FirstEmail,
+		}
+	}
+
+	return a.print(*output, view)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService services.AccessTokenServiceInterface
+}
+
+type createAccessTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+func NewAccessTokenHandler(accessTokenService services.AccessTokenServiceInterface) *AccessTokenHandler {
+	return &AccessTokenHandler{accessTokenService: accessTokenService}
+}
+
+func (h *AccessTokenHandler) CreateToken(c *fiber.Ctx) error {
+	req := new(createAccessTokenRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	token, accessToken, err := h.accessTokenService.CreateToken(c.UserContext(), userEmail, req.Name, req.Scopes, req.ExpiresInDays, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":      "Copy this token now, it will not be shown again",
+		"token":        token,
+		"access_token": accessToken,
+	})
+}
+
+func (h *AccessTokenHandler) GetTokens(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	tokens, err := h.accessTokenService.GetTokens(c.UserContext(), userEmail)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": tokens,
+	})
+}
+
+func (h *AccessTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.accessTokenService.RevokeToken(c.UserContext(), userEmail, c.Params("id")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Revoke token success",
+	})
+}
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateAccessToken(t *testing.T) {
+	t.Run("Create token success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+		accessToken := &models.AccessTokens{Name: "CI", Scopes: "tasks:read", Model: gorm.Model{ID: 1}}
+
+		accessTokenService := services.NewAccessTokenServiceMock()
+		accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService)
+
+		accessTokenService.On("CreateToken", mock.Anything, userEmail, "CI", []string{"tasks:read"}, 30, mock.AnythingOfType("services.ClientInfo")).Return("bt_pat_secret", accessToken, nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/tokens", testMiddleware, accessTokenHandler.CreateToken)
+
+		req := httptest.NewRequest("POST", "/user/tokens", bytes.NewReader([]byte(`{"name":"CI","scopes":["tasks:read"],"expires_in_days":30}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "bt_pat_secret")
+		assert.NotContains(t, string(body), "token_hash")
+	})
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+
+		accessTokenService := services.NewAccessTokenServiceMock()
+		accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService)
+
+		accessTokenService.On("CreateToken", mock.Anything, userEmail, "CI", []string{"admin"}, 0, mock.AnythingOfType("services.ClientInfo")).Return("", nil, errors.New("Invalid scope: admin"))
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/tokens", testMiddleware, accessTokenHandler.CreateToken)
+
+		req := httptest.NewRequest("POST", "/user/tokens", bytes.NewReader([]byte(`{"name":"CI","scopes":["admin"]}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Invalid scope: admin")
+	})
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	t.Run("Revoke token success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+
+		accessTokenService := services.NewAccessTokenServiceMock()
+		accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService)
+
+		accessTokenService.On("RevokeToken", mock.Anything, userEmail, "1").Return(nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Delete("/user/tokens/:id", testMiddleware, accessTokenHandler.RevokeToken)
+
+		req := httptest.NewRequest("DELETE", "/user/tokens/1", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		accessTokenService.AssertExpectations(t)
+	})
+}
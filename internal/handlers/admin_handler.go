@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminHandler struct {
+	auditService services.AuditServiceInterface
+	userService  services.UserServiceInterface
+}
+
+type updateRoleRequest struct {
+	Role models.Role `json:"role"`
+}
+
+func NewAdminHandler(auditService services.AuditServiceInterface, userService services.UserServiceInterface) *AdminHandler {
+	return &AdminHandler{auditService: auditService, userService: userService}
+}
+
+// NOTE - query: action, actor_id, actor_email, ip, from, to (RFC3339), limit, offset
+func auditFilter(c *fiber.Ctx) (models.AuditLogFilter, error) {
+	filter := models.AuditLogFilter{
+		Action:     c.Query("action"),
+		ActorEmail: c.Query("actor_email"),
+		IP:         c.Query("ip"),
+	}
+
+	if v := c.Query("actor_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return filter, errors.New("Invalid actor_id")
+		}
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+
+	var err error
+	if filter.From, err = timeQuery(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = timeQuery(c, "to"); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = strconv.Atoi(c.Query("limit", "0")); err != nil {
+		return filter, errors.New("Invalid limit")
+	}
+	if filter.Offset, err = strconv.Atoi(c.Query("offset", "0")); err != nil {
+		return filter, errors.New("Invalid offset")
+	}
+
+	return filter, nil
+}
+
+func timeQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, errors.New("Invalid " + name + ", use RFC3339 format")
+	}
+	return &t, nil
+}
+
+func (h *AdminHandler) GetAuditLogs(c *fiber.Ctx) error {
+	filter, err := auditFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	logs, err := h.auditService.GetAuditLogs(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"audit_logs": logs,
+	})
+}
+
+// NOTE - stream ออกไปทีละบรรทัด export ทั้งตารางได้โดยไม่ต้องโหลดเข้า memory
+func (h *AdminHandler) ExportAuditLogs(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	filter, err := auditFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	client := clientInfo(c)
+
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="audit_log.ndjson"`)
+	// NOTE - stream เขียนหลัง handler return ไปแล้ว ctx ของ request ถูกยกเลิกตอนนั้น ใช้แค่ค่า request id/trace
+	ctx := context.WithoutCancel(c.UserContext())
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.auditService.ExportAuditLogs(ctx, userEmail, filter, client, w); err != nil {
+			slog.ErrorContext(ctx, "Failed to export audit log", "error", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+func (h *AdminHandler) UpdateUserRole(c *fiber.Ctx) error {
+	req := new(updateRoleRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.userService.UpdateUserRole(c.UserContext(), userEmail, c.Params("id"), req.Role, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Update role success",
+	})
+}
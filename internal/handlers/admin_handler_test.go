@@ -0,0 +1,137 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func adminApp(adminHandler *handlers.AdminHandler, role models.Role) *fiber.App {
+	testMiddleware := func(c *fiber.Ctx) error {
+		c.Locals("userEmail", "admin@gmail.com")
+		c.Locals("userRole", role)
+		return c.Next()
+	}
+
+	app := fiber.New()
+	admin := app.Group("/admin", testMiddleware, middleware.RequireRole(models.Admin))
+	admin.Get("/audit", adminHandler.GetAuditLogs)
+	admin.Get("/audit/export", adminHandler.ExportAuditLogs)
+	admin.Put("/users/:id/role", adminHandler.UpdateUserRole)
+	return app
+}
+
+func TestGetAuditLogs(t *testing.T) {
+	t.Run("Get audit logs with filter", func(t *testing.T) {
+		auditService := services.NewAuditServiceMock()
+		adminHandler := handlers.NewAdminHandler(auditService, services.NewUserServiceMock())
+
+		actorID := uint(3)
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		auditService.On("GetAuditLogs", mock.Anything, models.AuditLogFilter{
+			Action:  models.AuditLoginFailed,
+			ActorID: &actorID,
+			From:    &from,
+			Limit:   10,
+		}).Return([]models.AuditLogs{{ID: 1, Action: models.AuditLoginFailed}}, nil)
+
+		req := httptest.NewRequest("GET", "/admin/audit?action=login_failed&actor_id=3&from=2025-01-01T00:00:00Z&limit=10", nil)
+
+		res, err := adminApp(adminHandler, models.Admin).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"action":"login_failed"`)
+	})
+
+	t.Run("Invalid from", func(t *testing.T) {
+		adminHandler := handlers.NewAdminHandler(services.NewAuditServiceMock(), services.NewUserServiceMock())
+
+		req := httptest.NewRequest("GET", "/admin/audit?from=yesterday", nil)
+
+		res, err := adminApp(adminHandler, models.Admin).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Forbidden for normal user", func(t *testing.T) {
+		auditService := services.NewAuditServiceMock()
+		adminHandler := handlers.NewAdminHandler(auditService, services.NewUserServiceMock())
+
+		req := httptest.NewRequest("GET", "/admin/audit", nil)
+
+		res, err := adminApp(adminHandler, models.User).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+		auditService.AssertNotCalled(t, "GetAuditLogs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestExportAuditLogs(t *testing.T) {
+	t.Run("Export NDJSON", func(t *testing.T) {
+		auditService := services.NewAuditServiceMock()
+		adminHandler := handlers.NewAdminHandler(auditService, services.NewUserServiceMock())
+
+		ndjson := "{\"id\":1,\"action\":\"login\"}\n{\"id\":2,\"action\":\"logout\"}\n"
+		auditService.On("ExportAuditLogs", mock.Anything, "admin@gmail.com", models.AuditLogFilter{IP: "10.0.0.1"}, mock.AnythingOfType("services.ClientInfo"), mock.Anything).Return(ndjson, nil)
+
+		req := httptest.NewRequest("GET", "/admin/audit/export?ip=10.0.0.1", nil)
+
+		res, err := adminApp(adminHandler, models.Admin).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Equal(t, ndjson, string(body))
+	})
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	t.Run("Update role success", func(t *testing.T) {
+		userService := services.NewUserServiceMock()
+		adminHandler := handlers.NewAdminHandler(services.NewAuditServiceMock(), userService)
+
+		userService.On("UpdateUserRole", mock.Anything, "admin@gmail.com", "2", models.Admin, mock.AnythingOfType("services.ClientInfo")).Return(nil)
+
+		req := httptest.NewRequest("PUT", "/admin/users/2/role", bytes.NewReader([]byte(`{"role":"admin"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := adminApp(adminHandler, models.Admin).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		userService.AssertExpectations(t)
+	})
+
+	t.Run("Change own role", func(t *testing.T) {
+		userService := services.NewUserServiceMock()
+		adminHandler := handlers.NewAdminHandler(services.NewAuditServiceMock(), userService)
+
+		userService.On("UpdateUserRole", mock.Anything, "admin@gmail.com", "1", models.User, mock.AnythingOfType("services.ClientInfo")).Return(errors.New("You can not change your own role"))
+
+		req := httptest.NewRequest("PUT", "/admin/users/1/role", bytes.NewReader([]byte(`{"role":"user"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := adminApp(adminHandler, models.Admin).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
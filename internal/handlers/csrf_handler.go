@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const csrfCookieTTL = 72 * time.Hour
+
+type CSRFHandler struct {
+	cookies utils.CookieConfig
+}
+
+func NewCSRFHandler(cookies utils.CookieConfig) *CSRFHandler {
+	return &CSRFHandler{cookies: cookies}
+}
+
+// NOTE - frontend อยู่คนละ domain อ่าน cookie ไม่ได้ เลยคืน token ใน body ให้เอาไปใส่ header X-CSRF-Token
+func (h *CSRFHandler) GetToken(c *fiber.Ctx) error {
+	// NOTE - ใช้ token เดิมถ้ายังมี ไม่งั้นหลาย tab จะทับ cookie กันเอง
+	token := c.Cookies(utils.CSRFCookieName)
+	if !utils.ValidCSRFToken(token, token) {
+		var err error
+		token, err = utils.GenerateCSRFToken()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate CSRF token"})
+		}
+	}
+
+	c.Cookie(h.cookies.New(utils.CSRFCookieName, token, csrfCookieTTL))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"csrf_token": token,
+	})
+}
@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func csrfApp() *fiber.App {
+	app := fiber.New()
+	api := app.Group("/api", middleware.NewCSRFMiddleware(testCookies))
+	api.Get("/csrf", handlers.NewCSRFHandler(testCookies).GetToken)
+	api.Put("/task/:id", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Update task success"})
+	})
+	api.Delete("/task/:id", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Delete task success"})
+	})
+	return app
+}
+
+func getCSRFToken(t *testing.T, app *fiber.App) (string, string) {
+	res, err := app.Test(httptest.NewRequest("GET", "/api/csrf", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	body, _ := io.ReadAll(res.Body)
+	var data map[string]string
+	require.NoError(t, json.Unmarshal(body, &data))
+
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "csrf_token" {
+			assert.True(t, cookie.HttpOnly)
+			assert.True(t, cookie.Secure)
+			return data["csrf_token"], cookie.Value
+		}
+	}
+	t.Fatal("csrf_token cookie not set")
+	return "", ""
+}
+
+func TestCSRF(t *testing.T) {
+	t.Run("Get token", func(t *testing.T) {
+		token, cookie := getCSRFToken(t, csrfApp())
+
+		assert.Len(t, token, 64)
+		assert.Equal(t, token, cookie)
+	})
+
+	t.Run("Reuse existing token", func(t *testing.T) {
+		app := csrfApp()
+		_, cookie := getCSRFToken(t, app)
+
+		req := httptest.NewRequest("GET", "/api/csrf", nil)
+		req.Header.Set("Cookie", "csrf_token="+cookie)
+
+		res, err := app.Test(req)
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), cookie)
+	})
+
+	t.Run("Reject cross-origin forged request without token", func(t *testing.T) {
+		app := csrfApp()
+		_, cookie := getCSRFToken(t, app)
+
+		// NOTE - เว็บอื่นส่ง form มา browser แนบ cookie ให้ แต่ใส่ header ไม่ได้
+		req := httptest.NewRequest("DELETE", "/api/task/1", nil)
+		req.Header.Set("Origin", "https://evil.example")
+		req.Header.Set("Cookie", "jwt=jwtToken; csrf_token="+cookie)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Reject forged token", func(t *testing.T) {
+		app := csrfApp()
+		_, cookie := getCSRFToken(t, app)
+
+		req := httptest.NewRequest("PUT", "/api/task/1", strings.NewReader(`{"title":"hacked"}`))
+		req.Header.Set("Origin", "https://evil.example")
+		req.Header.Set("Cookie", "jwt=jwtToken; csrf_token="+cookie)
+		req.Header.Set("X-CSRF-Token", strings.Repeat("0", 64))
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Reject request without csrf cookie", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/api/task/1", nil)
+		req.Header.Set("Cookie", "jwt=jwtToken")
+		req.Header.Set("X-CSRF-Token", "anything")
+
+		res, err := csrfApp().Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Allow request with matching token", func(t *testing.T) {
+		app := csrfApp()
+		token, cookie := getCSRFToken(t, app)
+
+		req := httptest.NewRequest("PUT", "/api/task/1", nil)
+		req.Header.Set("Cookie", "jwt=jwtToken; csrf_token="+cookie)
+		req.Header.Set("X-CSRF-Token", token)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Bearer token is exempt", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/task/1", nil)
+		req.Header.Set("Authorization", "Bearer jwtToken")
+
+		res, err := csrfApp().Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Personal access token is exempt even with cookie", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/task/1", nil)
+		req.Header.Set("Cookie", "jwt=jwtToken")
+		req.Header.Set("Authorization", "Bearer "+services.AccessTokenPrefix+"secret")
+
+		res, err := csrfApp().Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler struct {
+	healthService services.HealthServiceInterface
+}
+
+func NewHealthHandler(healthService services.HealthServiceInterface) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// NOTE - liveness ตอบได้ก็แปลว่า process ยังไม่ค้าง ไม่เช็ค DB ไม่งั้น DB ล่มทีเดียว orchestrator restart ทุก pod
+func (h *HealthHandler) Healthz(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+}
+
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	ready, checks := h.healthService.Ready(c.UserContext())
+	if !ready {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "unready", "checks": checks})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ready", "checks": checks})
+}
+
+func (h *HealthHandler) Version(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.healthService.Version())
+}
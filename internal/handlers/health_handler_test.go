@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func healthApp(healthService services.HealthServiceInterface) *fiber.App {
+	healthHandler := handlers.NewHealthHandler(healthService)
+
+	app := fiber.New()
+	app.Get("/healthz", healthHandler.Healthz)
+	app.Get("/readyz", healthHandler.Readyz)
+	app.Get("/version", healthHandler.Version)
+	return app
+}
+
+func TestHealthz(t *testing.T) {
+	healthService := services.NewHealthServiceMock()
+
+	res, err := healthApp(healthService).Test(httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	healthService.AssertNotCalled(t, "Ready", mock.Anything)
+}
+
+func TestReadyz(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		healthService := services.NewHealthServiceMock()
+		healthService.On("Ready", mock.Anything).Return(true, map[string]string{"database": "ok"})
+
+		res, err := healthApp(healthService).Test(httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+	})
+
+	t.Run("Unready", func(t *testing.T) {
+		healthService := services.NewHealthServiceMock()
+		healthService.On("Ready", mock.Anything).Return(false, map[string]string{"database": "unavailable"})
+
+		res, err := healthApp(healthService).Test(httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusServiceUnavailable, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"database":"unavailable"`)
+	})
+}
+
+func TestVersion(t *testing.T) {
+	healthService := services.NewHealthServiceMock()
+	healthService.On("Version").Return(utils.BuildInfo{GitSHA: "abc123", BuildTime: "2025-01-01T00:00:00Z", GoVersion: "go1.24.0"})
+
+	res, err := healthApp(healthService).Test(httptest.NewRequest("GET", "/version", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var info map[string]string
+	body, _ := io.ReadAll(res.Body)
+	require.NoError(t, json.Unmarshal(body, &info))
+	assert.Equal(t, map[string]string{"git_sha": "abc123", "build_time": "2025-01-01T00:00:00Z", "go_version": "go1.24.0"}, info)
+}
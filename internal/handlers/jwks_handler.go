@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler struct {
+	keyring utils.KeyringInterface
+}
+
+func NewJWKSHandler(keyring utils.KeyringInterface) *JWKSHandler {
+	return &JWKSHandler{keyring: keyring}
+}
+
+// NOTE - service อื่น cache ได้ไม่นาน เพราะ key ใหม่ต้องเห็นก่อน token แรกที่เซ็นด้วย key นั้น
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.keyring.JWKS())
+}
@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getJWKS(t *testing.T, keyring utils.KeyringInterface) utils.JWKSet {
+	jwksHandler := handlers.NewJWKSHandler(keyring)
+
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	body, _ := io.ReadAll(res.Body)
+
+	var set utils.JWKSet
+	require.NoError(t, json.Unmarshal(body, &set))
+	return set
+}
+
+// NOTE - verify แบบที่ service อื่นทำ คือหา key จาก JWKS ด้วย kid
+func verifyWithJWKS(set utils.JWKSet, tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		for _, key := range set.Keys {
+			if key.Kid == token.Header["kid"] {
+				x, err := base64.RawURLEncoding.DecodeString(key.X)
+				return ed25519.PublicKey(x), err
+			}
+		}
+		return nil, jwt.ErrTokenUnverifiable
+	}, jwt.WithValidMethods([]string{utils.AlgEdDSA}), jwt.WithIssuer("belugatasks"), jwt.WithAudience("belugatasks-api"))
+}
+
+func TestGetJWKS(t *testing.T) {
+	t.Run("Publish current and next key", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Hour)
+		require.NoError(t, err)
+		assert.Len(t, getJWKS(t, keyring).Keys, 1)
+
+		require.NoError(t, keyring.Rotate())
+		set := getJWKS(t, keyring)
+
+		assert.Len(t, set.Keys, 2)
+		for _, key := range set.Keys {
+			assert.Equal(t, "OKP", key.Kty)
+			assert.Equal(t, "EdDSA", key.Alg)
+			assert.Equal(t, "sig", key.Use)
+			assert.NotEmpty(t, key.Kid)
+		}
+	})
+
+	t.Run("RSA key", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgRS256, nil, time.Hour)
+		require.NoError(t, err)
+
+		set := getJWKS(t, keyring)
+
+		assert.Equal(t, "RSA", set.Keys[0].Kty)
+		assert.Equal(t, "AQAB", set.Keys[0].E)
+		assert.NotEmpty(t, set.Keys[0].N)
+	})
+
+	t.Run("Token verifies with published key after rotation", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Hour)
+		require.NoError(t, err)
+		jwtUtil := utils.NewJwt(keyring, "belugatasks", "belugatasks-api")
+
+		// NOTE - verifier ดึง JWKS ไว้ก่อน rotate
+		cached := getJWKS(t, keyring)
+
+		oldToken, err := jwtUtil.GenerateSessionJWT("test@gmail.com", "")
+		require.NoError(t, err)
+
+		require.NoError(t, keyring.Rotate())
+
+		newToken, err := jwtUtil.GenerateSessionJWT("test@gmail.com", "")
+		require.NoError(t, err)
+
+		_, err = verifyWithJWKS(cached, newToken)
+		assert.NoError(t, err)
+
+		email, err := jwtUtil.ParseJWT(oldToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "test@gmail.com", email)
+	})
+
+	t.Run("Reject token for other audience", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Hour)
+		require.NoError(t, err)
+
+		otherToken, err := utils.NewJwt(keyring, "belugatasks", "other-api").GenerateSessionJWT("test@gmail.com", "")
+		require.NoError(t, err)
+
+		_, err = utils.NewJwt(keyring, "belugatasks", "belugatasks-api").ParseJWT(otherToken)
+
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("Reject HS256 token signed with public key", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Hour)
+		require.NoError(t, err)
+		set := getJWKS(t, keyring)
+
+		kid, _, _ := keyring.SigningKey()
+		var publicKey []byte
+		for _, key := range set.Keys {
+			if key.Kid == kid {
+				publicKey, err = base64.RawURLEncoding.DecodeString(key.X)
+				require.NoError(t, err)
+			}
+		}
+		require.NotEmpty(t, publicKey)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.JWTClaims{
+			Email: "attacker@gmail.com",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "belugatasks",
+				Audience:  jwt.ClaimStrings{"belugatasks-api"},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		token.Header["kid"] = kid
+		forged, err := token.SignedString(publicKey)
+		require.NoError(t, err)
+
+		_, err = utils.NewJwt(keyring, "belugatasks", "belugatasks-api").ParseJWT(forged)
+
+		assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const deviceCookie = "device_id"
+
+type MagicLinkHandler struct {
+	magicLinkService services.MagicLinkServiceInterface
+	// NOTE - หน้า frontend ที่จะ redirect กลับไปหลัง login เสร็จ
+	successURL string
+	cookies    utils.CookieConfig
+}
+
+type magicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+func NewMagicLinkHandler(magicLinkService services.MagicLinkServiceInterface, successURL string, cookies utils.CookieConfig) *MagicLinkHandler {
+	return &MagicLinkHandler{magicLinkService: magicLinkService, successURL: successURL, cookies: cookies}
+}
+
+func (h *MagicLinkHandler) RequestLink(c *fiber.Ctx) error {
+	req := new(magicLinkRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	fingerprint, err := h.deviceFingerprint(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.magicLinkService.RequestLink(c.UserContext(), req.Email, fingerprint); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// NOTE - ตอบเหมือนกันทุกกรณี ไม่บอกว่ามี email นี้ไหม
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "If the email exists, a sign-in link has been sent",
+	})
+}
+
+func (h *MagicLinkHandler) Callback(c *fiber.Ctx) error {
+	fingerprint, err := h.deviceFingerprint(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	token, _, err := h.magicLinkService.Login(c.UserContext(), c.Query("token"), fingerprint, clientInfo(c))
+
+	// NOTE - เปิด 2FA ไว้ ส่ง challenge token ไปใน fragment ให้ frontend เรียก /user/login/mfa ต่อ
+	if errors.Is(err, services.ErrMFARequired) {
+		return c.Redirect(h.successURL+"#mfa_token="+url.QueryEscape(token), fiber.StatusFound)
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Cookie(h.cookies.AuthCookie(token))
+
+	return c.Redirect(h.successURL, fiber.StatusFound)
+}
+
+// NOTE - ระบุ device ด้วย cookie สุ่มที่อยู่ยาว รวมกับ User-Agent ไม่มี cookie ก็ออกให้ใหม่
+func (h *MagicLinkHandler) deviceFingerprint(c *fiber.Ctx) (string, error) {
+	deviceID := c.Cookies(deviceCookie)
+	if deviceID == "" {
+		var err error
+		deviceID, err = utils.GenerateRandomToken(16)
+		if err != nil {
+			return "", errors.New("Failed to identify device")
+		}
+
+		c.Cookie(h.cookies.New(deviceCookie, deviceID, 365*24*time.Hour))
+	}
+
+	return deviceID + "|" + c.Get(fiber.HeaderUserAgent), nil
+}
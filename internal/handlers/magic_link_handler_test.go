@@ -0,0 +1,146 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newMagicLinkApp(magicLinkService services.MagicLinkServiceInterface) *fiber.App {
+	magicLinkHandler := handlers.NewMagicLinkHandler(magicLinkService, "http://localhost:3000/", testCookies)
+
+	app := fiber.New()
+	app.Post("/user/login/magic", magicLinkHandler.RequestLink)
+	app.Get("/user/login/magic/callback", magicLinkHandler.Callback)
+	return app
+}
+
+func TestRequestMagicLink(t *testing.T) {
+	t.Run("Request link sets device cookie", func(t *testing.T) {
+		magicLinkService := services.NewMagicLinkServiceMock()
+		magicLinkService.On("RequestLink", mock.Anything, "test@gmail.com", mock.MatchedBy(func(fingerprint string) bool {
+			return strings.HasSuffix(fingerprint, "|Mozilla/5.0")
+		})).Return(nil)
+
+		req := httptest.NewRequest("POST", "/user/login/magic", bytes.NewReader([]byte(`{"email":"test@gmail.com"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+
+		res, err := newMagicLinkApp(magicLinkService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Header.Get("Set-Cookie"), "device_id=")
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "If the email exists")
+		magicLinkService.AssertExpectations(t)
+	})
+
+	t.Run("Keep existing device cookie", func(t *testing.T) {
+		magicLinkService := services.NewMagicLinkServiceMock()
+		magicLinkService.On("RequestLink", mock.Anything, "test@gmail.com", "deviceID|Mozilla/5.0").Return(nil)
+
+		req := httptest.NewRequest("POST", "/user/login/magic", bytes.NewReader([]byte(`{"email":"test@gmail.com"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		req.Header.Set("Cookie", "device_id=deviceID")
+
+		res, err := newMagicLinkApp(magicLinkService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Empty(t, res.Header.Get("Set-Cookie"))
+	})
+
+	t.Run("Failed send to existing email looks like unknown email", func(t *testing.T) {
+		userRepo := repositories.NewUserRepositoryMock()
+		magicLinkRepo := repositories.NewMagicLinkRepositoryMock()
+		jwtUtil := utils.NewJwtMock()
+		mailer := utils.NewMailerMock()
+		magicLinkService := services.NewMagicLinkService(userRepo, magicLinkRepo, jwtUtil, services.NewSessionServiceMock(), mailer, "http://localhost:8080/api/user/login/magic/callback")
+
+		userRepo.On("FindByEmail", mock.Anything, "unknown@gmail.com").Return(nil, nil)
+		userRepo.On("FindByEmail", mock.Anything, "test@gmail.com").Return(&models.Users{Email: "test@gmail.com"}, nil)
+		magicLinkRepo.On("CreateMagicLink", mock.Anything, mock.Anything).Return(nil)
+		jwtUtil.On("GenerateMagicLinkToken", "test@gmail.com", mock.Anything, mock.Anything).Return("magicToken", nil)
+		mailer.On("Send", "test@gmail.com", mock.Anything, mock.Anything).Return(errors.New("smtp down"))
+
+		request := func(email string) (int, string) {
+			req := httptest.NewRequest("POST", "/user/login/magic", bytes.NewReader([]byte(`{"email":"`+email+`"}`)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "Mozilla/5.0")
+			req.Header.Set("Cookie", "device_id=deviceID")
+
+			res, err := newMagicLinkApp(magicLinkService).Test(req)
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(res.Body)
+			return res.StatusCode, string(body)
+		}
+
+		unknownStatus, unknownBody := request("unknown@gmail.com")
+		failedStatus, failedBody := request("test@gmail.com")
+
+		assert.Equal(t, fiber.StatusOK, unknownStatus)
+		assert.Equal(t, unknownStatus, failedStatus)
+		assert.Equal(t, unknownBody, failedBody)
+		mailer.AssertExpectations(t)
+	})
+}
+
+func TestMagicLinkCallback(t *testing.T) {
+	t.Run("Callback success", func(t *testing.T) {
+		magicLinkService := services.NewMagicLinkServiceMock()
+		magicLinkService.On("Login", mock.Anything, "magicToken", "deviceID|Mozilla/5.0", services.ClientInfo{IP: "0.0.0.0", UserAgent: "Mozilla/5.0"}).Return("jwtToken", &models.Users{Email: "test@gmail.com"}, nil)
+
+		req := httptest.NewRequest("GET", "/user/login/magic/callback?token=magicToken", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		req.Header.Set("Cookie", "device_id=deviceID")
+
+		res, err := newMagicLinkApp(magicLinkService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Equal(t, "http://localhost:3000/", res.Header.Get("Location"))
+		assert.Contains(t, res.Header.Get("Set-Cookie"), "jwt=jwtToken")
+	})
+
+	t.Run("Callback failed", func(t *testing.T) {
+		magicLinkService := services.NewMagicLinkServiceMock()
+		magicLinkService.On("Login", mock.Anything, "magicToken", mock.Anything, mock.Anything).Return("", nil, errors.New("This link must be opened in the browser where it was requested"))
+
+		req := httptest.NewRequest("GET", "/user/login/magic/callback?token=magicToken", nil)
+
+		res, err := newMagicLinkApp(magicLinkService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		assert.NotContains(t, strings.Join(res.Header.Values("Set-Cookie"), "\n"), "jwt=")
+	})
+
+	t.Run("Two-factor required", func(t *testing.T) {
+		magicLinkService := services.NewMagicLinkServiceMock()
+		magicLinkService.On("Login", mock.Anything, "magicToken", "deviceID|", mock.AnythingOfType("services.ClientInfo")).Return("mfaToken", &models.Users{}, services.ErrMFARequired)
+
+		req := httptest.NewRequest("GET", "/user/login/magic/callback?token=magicToken", nil)
+		req.Header.Set("Cookie", "device_id=deviceID")
+
+		res, err := newMagicLinkApp(magicLinkService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Equal(t, "http://localhost:3000/#mfa_token=mfaToken", res.Header.Get("Location"))
+	})
+}
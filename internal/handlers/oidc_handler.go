@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	oidcService services.OIDCServiceInterface
+	// NOTE - หน้า frontend ที่จะ redirect กลับไปหลัง login เสร็จ
+	successURL string
+	cookies    utils.CookieConfig
+}
+
+func NewOIDCHandler(oidcService services.OIDCServiceInterface, successURL string, cookies utils.CookieConfig) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService, successURL: successURL, cookies: cookies}
+}
+
+// NOTE - SameSite=Lax เพราะ provider redirect กลับมาแบบ top-level GET
+func (h *OIDCHandler) stateCookie(cookie *fiber.Cookie) *fiber.Cookie {
+	cookie.Path = "/api/user/oidc"
+	cookie.SameSite = fiber.CookieSameSiteLaxMode
+	return cookie
+}
+
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+
+	state, authURL, err := h.oidcService.BeginLogin(c.UserContext(), provider)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// NOTE - ผูก state กับ browser ที่เริ่ม login กัน login CSRF
+	c.Cookie(h.stateCookie(h.cookies.New(oidcStateCookie, state, 10*time.Minute)))
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+	state := c.Query("state")
+	cookieState := c.Cookies(oidcStateCookie)
+
+	c.Cookie(h.stateCookie(h.cookies.Expire(oidcStateCookie)))
+
+	// NOTE - ผู้ใช้กดยกเลิกหรือ provider ปฏิเสธ
+	if providerErr := c.Query("error"); providerErr != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Login was cancelled or denied by provider: " + providerErr})
+	}
+
+	if state == "" || cookieState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid login state"})
+	}
+
+	token, _, err := h.oidcService.FinishLogin(c.UserContext(), provider, state, c.Query("code"), clientInfo(c))
+
+	// NOTE - เปิด 2FA ไว้ ส่ง challenge token ไปใน fragment ให้ frontend เรียก /user/login/mfa ต่อ
+	if errors.Is(err, services.ErrMFARequired) {
+		return c.Redirect(h.successURL+"#mfa_token="+url.QueryEscape(token), fiber.StatusFound)
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Cookie(h.cookies.AuthCookie(token))
+
+	return c.Redirect(h.successURL, fiber.StatusFound)
+}
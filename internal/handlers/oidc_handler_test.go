@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOIDCApp(oidcService services.OIDCServiceInterface) *fiber.App {
+	oidcHandler := handlers.NewOIDCHandler(oidcService, "http://localhost:3000/", testCookies)
+
+	app := fiber.New()
+	app.Get("/api/user/oidc/:provider/login", oidcHandler.Login)
+	app.Get("/api/user/oidc/:provider/callback", oidcHandler.Callback)
+	return app
+}
+
+func TestOIDCLogin(t *testing.T) {
+	t.Run("Redirect to provider", func(t *testing.T) {
+		oidcService := services.NewOIDCServiceMock()
+		oidcService.On("BeginLogin", mock.Anything, "google").Return("stateValue", "https://accounts.example.com/authorize?state=stateValue", nil)
+
+		req := httptest.NewRequest("GET", "/api/user/oidc/google/login", nil)
+
+		res, err := newOIDCApp(oidcService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Equal(t, "https://accounts.example.com/authorize?state=stateValue", res.Header.Get("Location"))
+		assert.Contains(t, res.Header.Get("Set-Cookie"), "oidc_state=stateValue")
+		assert.Contains(t, res.Header.Get("Set-Cookie"), "SameSite=Lax")
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		oidcService := services.NewOIDCServiceMock()
+		oidcService.On("BeginLogin", mock.Anything, "unknown").Return("", "", errors.New("Unknown login provider"))
+
+		req := httptest.NewRequest("GET", "/api/user/oidc/unknown/login", nil)
+
+		res, err := newOIDCApp(oidcService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestOIDCCallback(t *testing.T) {
+	t.Run("Callback success", func(t *testing.T) {
+		oidcService := services.NewOIDCServiceMock()
+		oidcService.On("FinishLogin", mock.Anything, "google", "stateValue", "code", mock.AnythingOfType("services.ClientInfo")).Return("jwtToken", &models.Users{Email: "test@gmail.com"}, nil)
+
+		req := httptest.NewRequest("GET", "/api/user/oidc/google/callback?state=stateValue&code=code", nil)
+		req.Header.Set("Cookie", "oidc_state=stateValue")
+
+		res, err := newOIDCApp(oidcService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Equal(t, "http://localhost:3000/", res.Header.Get("Location"))
+		assert.Contains(t, strings.Join(res.Header.Values("Set-Cookie"), "\n"), "jwt=jwtToken")
+	})
+
+	t.Run("State cookie mismatch", func(t *testing.T) {
+		oidcService := services.NewOIDCServiceMock()
+
+		req := httptest.NewRequest("GET", "/api/user/oidc/google/callback?state=stateValue&code=code", nil)
+		req.Header.Set("Cookie", "oidc_state=otherState")
+
+		res, err := newOIDCApp(oidcService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		oidcService.AssertNotCalled(t, "FinishLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Provider denied", func(t *testing.T) {
+		oidcService := services.NewOIDCServiceMock()
+
+		req := httptest.NewRequest("GET", "/api/user/oidc/google/callback?error=access_denied&state=stateValue", nil)
+		req.Header.Set("Cookie", "oidc_state=stateValue")
+
+		res, err := newOIDCApp(oidcService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		oidcService.AssertNotCalled(t, "FinishLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Two-factor required", func(t *testing.T) {
+		oidcService := services.NewOIDCServiceMock()
+		oidcService.On("FinishLogin", mock.Anything, "google", "stateValue", "code", mock.AnythingOfType("services.ClientInfo")).Return("mfaToken", &models.Users{Email: "test@gmail.com"}, services.ErrMFARequired)
+
+		req := httptest.NewRequest("GET", "/api/user/oidc/google/callback?state=stateValue&code=code", nil)
+		req.Header.Set("Cookie", "oidc_state=stateValue")
+
+		res, err := newOIDCApp(oidcService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Equal(t, "http://localhost:3000/#mfa_token=mfaToken", res.Header.Get("Location"))
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PasswordHandler struct {
+	passwordService services.PasswordServiceInterface
+	cookies         utils.CookieConfig
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func NewPasswordHandler(passwordService services.PasswordServiceInterface, cookies utils.CookieConfig) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService, cookies: cookies}
+}
+
+func (h *PasswordHandler) ForgotPassword(c *fiber.Ctx) error {
+	req := new(forgotPasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if err := h.passwordService.ForgotPassword(c.UserContext(), req.Email); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// NOTE - ตอบเหมือนกันทุกกรณี ไม่บอกว่ามี email นี้ไหม
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "If the email exists, a reset link has been sent",
+	})
+}
+
+func (h *PasswordHandler) ResetPassword(c *fiber.Ctx) error {
+	req := new(resetPasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if err := h.passwordService.ResetPassword(c.UserContext(), req.Token, req.Password, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Reset password success",
+	})
+}
+
+func (h *PasswordHandler) ChangePassword(c *fiber.Ctx) error {
+	req := new(changePasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	token, err := h.passwordService.ChangePassword(c.UserContext(), userEmail, req.CurrentPassword, req.NewPassword, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// NOTE - session อื่นถูก revoke แล้ว ตั้ง cookie ใหม่ให้ session นี้
+	c.Cookie(h.cookies.AuthCookie(token))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Change password success",
+		"token":   token,
+	})
+}
@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestForgotPassword(t *testing.T) {
+	t.Run("Forgot password success", func(t *testing.T) {
+		passwordService := services.NewPasswordServiceMock()
+		passwordHandler := handlers.NewPasswordHandler(passwordService, testCookies)
+
+		passwordService.On("ForgotPassword", mock.Anything, "test@gmail.com").Return(nil)
+
+		app := fiber.New()
+		app.Post("/user/password/forgot", passwordHandler.ForgotPassword)
+
+		req := httptest.NewRequest("POST", "/user/password/forgot", bytes.NewReader([]byte(`{"email":"test@gmail.com"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "If the email exists")
+
+		passwordService.AssertExpectations(t)
+	})
+
+	t.Run("Forgot password Invalid request", func(t *testing.T) {
+		passwordService := services.NewPasswordServiceMock()
+		passwordHandler := handlers.NewPasswordHandler(passwordService, testCookies)
+
+		app := fiber.New()
+		app.Post("/user/password/forgot", passwordHandler.ForgotPassword)
+
+		req := httptest.NewRequest("POST", "/user/password/forgot", nil)
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("Reset password success", func(t *testing.T) {
+		passwordService := services.NewPasswordServiceMock()
+		passwordHandler := handlers.NewPasswordHandler(passwordService, testCookies)
+
+		passwordService.On("ResetPassword", mock.Anything, "resetToken", "newPassword", mock.AnythingOfType("services.ClientInfo")).Return(nil)
+
+		app := fiber.New()
+		app.Post("/user/password/reset", passwordHandler.ResetPassword)
+
+		req := httptest.NewRequest("POST", "/user/password/reset", bytes.NewReader([]byte(`{"token":"resetToken","password":"newPassword"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Reset password success")
+	})
+
+	t.Run("Reset password Invalid token", func(t *testing.T) {
+		passwordService := services.NewPasswordServiceMock()
+		passwordHandler := handlers.NewPasswordHandler(passwordService, testCookies)
+
+		passwordService.On("ResetPassword", mock.Anything, "resetToken", "newPassword", mock.AnythingOfType("services.ClientInfo")).Return(errors.New("Invalid or expired token"))
+
+		app := fiber.New()
+		app.Post("/user/password/reset", passwordHandler.ResetPassword)
+
+		req := httptest.NewRequest("POST", "/user/password/reset", bytes.NewReader([]byte(`{"token":"resetToken","password":"newPassword"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Invalid or expired token")
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	t.Run("Change password success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+
+		passwordService := services.NewPasswordServiceMock()
+		passwordHandler := handlers.NewPasswordHandler(passwordService, testCookies)
+
+		passwordService.On("ChangePassword", mock.Anything, userEmail, "oldPassword", "newPassword", mock.AnythingOfType("services.ClientInfo")).Return("newToken", nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/password/change", testMiddleware, passwordHandler.ChangePassword)
+
+		req := httptest.NewRequest("POST", "/user/password/change", bytes.NewReader([]byte(`{"current_password":"oldPassword","new_password":"newPassword"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Header.Get("Set-Cookie"), "jwt=newToken")
+
+		passwordService.AssertExpectations(t)
+	})
+
+	t.Run("Change password not authenticated", func(t *testing.T) {
+		passwordService := services.NewPasswordServiceMock()
+		passwordHandler := handlers.NewPasswordHandler(passwordService, testCookies)
+
+		app := fiber.New()
+		app.Post("/user/password/change", passwordHandler.ChangePassword)
+
+		req := httptest.NewRequest("POST", "/user/password/change", bytes.NewReader([]byte(`{"current_password":"oldPassword","new_password":"newPassword"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("Change password incorrect", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+
+		passwordService := services.NewPasswordServiceMock()
+		passwordHandler := handlers.NewPasswordHandler(passwordService, testCookies)
+
+		passwordService.On("ChangePassword", mock.Anything, userEmail, "wrong", "newPassword", mock.AnythingOfType("services.ClientInfo")).Return("", errors.New("Current password is incorrect"))
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/password/change", testMiddleware, passwordHandler.ChangePassword)
+
+		req := httptest.NewRequest("POST", "/user/password/change", bytes.NewReader([]byte(`{"current_password":"wrong","new_password":"newPassword"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Current password is incorrect")
+	})
+}
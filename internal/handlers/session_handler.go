@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	sessionService services.SessionServiceInterface
+}
+
+func NewSessionHandler(sessionService services.SessionServiceInterface) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+func (h *SessionHandler) GetSessions(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	currentSessionID, _ := c.Locals("sessionID").(string)
+
+	sessions, err := h.sessionService.GetSessions(c.UserContext(), userEmail, currentSessionID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"sessions": sessions,
+	})
+}
+
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.sessionService.RevokeSession(c.UserContext(), userEmail, c.Params("id")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Revoke session success",
+	})
+}
+
+func (h *SessionHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	// NOTE - token ที่ไม่มี session จะ logout ทุกเครื่องรวมทั้งเครื่องนี้ด้วย
+	currentSessionID, _ := c.Locals("sessionID").(string)
+
+	if err := h.sessionService.RevokeOtherSessions(c.UserContext(), userEmail, currentSessionID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Logged out of all other sessions",
+	})
+}
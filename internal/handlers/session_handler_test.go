@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func sessionMiddleware(userEmail string, sessionID string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("userEmail", userEmail)
+		c.Locals("sessionID", sessionID)
+		return c.Next()
+	}
+}
+
+func TestGetSessions(t *testing.T) {
+	t.Run("Get sessions success", func(t *testing.T) {
+		sessionService := services.NewSessionServiceMock()
+		sessionHandler := handlers.NewSessionHandler(sessionService)
+
+		sessionService.On("GetSessions", mock.Anything, "test@gmail.com", "sid").Return([]models.Sessions{
+			{SessionID: "sid", UserAgent: "Mozilla/5.0", Current: true},
+		}, nil)
+
+		app := fiber.New()
+		app.Get("/user/sessions", sessionMiddleware("test@gmail.com", "sid"), sessionHandler.GetSessions)
+
+		res, err := app.Test(httptest.NewRequest("GET", "/user/sessions", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"current":true`)
+		assert.NotContains(t, string(body), `"sid"`)
+	})
+
+	t.Run("User not authenticated", func(t *testing.T) {
+		sessionHandler := handlers.NewSessionHandler(services.NewSessionServiceMock())
+
+		app := fiber.New()
+		app.Get("/user/sessions", sessionHandler.GetSessions)
+
+		res, err := app.Test(httptest.NewRequest("GET", "/user/sessions", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	t.Run("Revoke session success", func(t *testing.T) {
+		sessionService := services.NewSessionServiceMock()
+		sessionHandler := handlers.NewSessionHandler(sessionService)
+
+		sessionService.On("RevokeSession", mock.Anything, "test@gmail.com", "3").Return(nil)
+
+		app := fiber.New()
+		app.Delete("/user/sessions/:id", sessionMiddleware("test@gmail.com", "sid"), sessionHandler.RevokeSession)
+
+		res, err := app.Test(httptest.NewRequest("DELETE", "/user/sessions/3", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Session of other user", func(t *testing.T) {
+		sessionService := services.NewSessionServiceMock()
+		sessionHandler := handlers.NewSessionHandler(sessionService)
+
+		sessionService.On("RevokeSession", mock.Anything, "test@gmail.com", "3").Return(errors.New("you do not have permission to access this session"))
+
+		app := fiber.New()
+		app.Delete("/user/sessions/:id", sessionMiddleware("test@gmail.com", "sid"), sessionHandler.RevokeSession)
+
+		res, err := app.Test(httptest.NewRequest("DELETE", "/user/sessions/3", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	t.Run("Keep current session", func(t *testing.T) {
+		sessionService := services.NewSessionServiceMock()
+		sessionHandler := handlers.NewSessionHandler(sessionService)
+
+		sessionService.On("RevokeOtherSessions", mock.Anything, "test@gmail.com", "sid").Return(nil)
+
+		app := fiber.New()
+		app.Delete("/user/sessions", sessionMiddleware("test@gmail.com", "sid"), sessionHandler.RevokeOtherSessions)
+
+		res, err := app.Test(httptest.NewRequest("DELETE", "/user/sessions", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		sessionService.AssertExpectations(t)
+	})
+}
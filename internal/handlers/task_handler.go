@@ -16,10 +16,9 @@ func NewTaskHandler(taskService services.TaskServiceInterface) *TaskHandler{
 
 
 func (h *TaskHandler) GetAllTask(c *fiber.Ctx) error {
-	//NOTE -  ดึง userID จาก cookie
-	emailCookie := c.Cookies("jwt")
-
-	if emailCookie == "" {
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "User not authenticated",
 		})
@@ -28,7 +27,7 @@ func (h *TaskHandler) GetAllTask(c *fiber.Ctx) error {
 	// NOTE - Query Param
 	priority := c.Query("priority", "")
 
-	tasks, err :=  h.taskService.GetAllTask(emailCookie,priority)
+	tasks, err :=  h.taskService.GetAllTask(c.UserContext(), userEmail,priority)
 
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -49,16 +48,15 @@ func (h *TaskHandler) CreateTask(c *fiber.Ctx)error{
 		})
 	}
 
-    // ดึง userID จาก cookie
-    emailCookie := c.Cookies("jwt")
-
-	if emailCookie == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "User not authenticated",
-        })
-    }
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
 	
-	if err := h.taskService.CreateTask(task, emailCookie); err != nil{
+	if err := h.taskService.CreateTask(c.UserContext(), task, userEmail); err != nil{
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":err.Error(),
 		})
@@ -81,16 +79,15 @@ func (h *TaskHandler) FindTaskById(c *fiber.Ctx) error {
 		})
 	}
 
-	// NOTE - ดึง Email จาก cookie เพื่อเอามาเช็คว่าเป็น User ID เดียวกับที่อยู่ใน task ไหม
-
-	emailCookie := c.Cookies("jwt")
-	if emailCookie == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "User not authenticated",
-        })
-    }
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
 
-    task,err :=	h.taskService.FindTaskById(idStr,emailCookie)
+    task,err :=	h.taskService.FindTaskById(c.UserContext(), idStr,userEmail)
 	
 	if err !=nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -117,21 +114,20 @@ func (h *TaskHandler) UpdateTask(c *fiber.Ctx) error {
 		})
 	}
 
-	// NOTE - ดึง Email จาก cookie เพื่อเอามาเช็คว่าเป็น User ID เดียวกับที่อยู่ใน task ไหม
-
-	emailCookie := c.Cookies("jwt")
-	if emailCookie == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "User not authenticated",
-        })
-    }
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
 
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":"Invalid request",
 		})
 	}
-	if err :=h.taskService.UpdateTaskById(idStr, emailCookie, task); err !=nil{
+	if err :=h.taskService.UpdateTaskById(c.UserContext(), idStr, userEmail, task); err !=nil{
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error :": err.Error(),
 		})
@@ -153,16 +149,15 @@ func (h *TaskHandler) DeleteTask(c *fiber.Ctx) error {
 	}
 
 	
-	// NOTE - ดึง Email จาก cookie เพื่อเอามาเช็คว่าเป็น User ID เดียวกับที่อยู่ใน task ไหม
-
-	emailCookie := c.Cookies("jwt")
-	if emailCookie == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "User not authenticated",
-        })
-    }
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
 
-	if err := h.taskService.DeleteTaskById(idStr,emailCookie) ; err != nil{
+	if err := h.taskService.DeleteTaskById(c.UserContext(), idStr,userEmail) ; err != nil{
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error :": err.Error(),
 		})
@@ -173,10 +168,9 @@ func (h *TaskHandler) DeleteTask(c *fiber.Ctx) error {
 }
 
 func (h*TaskHandler) GetCompleteTask(c *fiber.Ctx) error {
-	//NOTE -  ดึง userID จาก cookie
-	emailCookie := c.Cookies("jwt")
-
-	if emailCookie == "" {
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "User not authenticated",
 		})
@@ -185,7 +179,7 @@ func (h*TaskHandler) GetCompleteTask(c *fiber.Ctx) error {
 	// NOTE - Query Param
 	priority := c.Query("priority", "")
 
-	tasks, err :=  h.taskService.GetCompleteTask(emailCookie,priority)
+	tasks, err :=  h.taskService.GetCompleteTask(c.UserContext(), userEmail,priority)
 
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -198,10 +192,9 @@ func (h*TaskHandler) GetCompleteTask(c *fiber.Ctx) error {
 }
 
 func (h*TaskHandler) GetPendingTask(c *fiber.Ctx) error {
-	//NOTE -  ดึง userID จาก cookie
-	emailCookie := c.Cookies("jwt")
-
-	if emailCookie == "" {
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "User not authenticated",
 		})
@@ -210,7 +203,7 @@ func (h*TaskHandler) GetPendingTask(c *fiber.Ctx) error {
 	// NOTE - Query Param
 	priority := c.Query("priority", "")
 
-	tasks, err :=  h.taskService.GetPendingTask(emailCookie,priority)
+	tasks, err :=  h.taskService.GetPendingTask(c.UserContext(), userEmail,priority)
 
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -223,10 +216,9 @@ func (h*TaskHandler) GetPendingTask(c *fiber.Ctx) error {
 }
 
 func (h*TaskHandler) GetOverdueTask(c *fiber.Ctx) error {
-	//NOTE -  ดึง userID จาก cookie
-	emailCookie := c.Cookies("jwt")
-
-	if emailCookie == "" {
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ไม่ว่าจะมาจาก cookie, Bearer JWT หรือ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "User not authenticated",
 		})
@@ -235,7 +227,7 @@ func (h*TaskHandler) GetOverdueTask(c *fiber.Ctx) error {
 	// NOTE - Query Param
 	priority := c.Query("priority", "")
 
-	tasks, err :=  h.taskService.GetOverdueTask(emailCookie,priority)
+	tasks, err :=  h.taskService.GetOverdueTask(c.UserContext(), userEmail,priority)
 
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -16,6 +16,14 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// NOTE - แทน AuthMiddleware เอาค่าใน cookie jwt มาเป็น email ของคนที่ login ตรงๆ
+func fakeAuth(c *fiber.Ctx) error {
+	if email := c.Cookies(testCookies.Name); email != "" {
+		c.Locals("userEmail", email)
+	}
+	return c.Next()
+}
+
 func TestGetAllTask(t *testing.T) {
 	t.Run("GetAllTask Success", func(t *testing.T) {
 		task := []models.Tasks{
@@ -31,10 +39,10 @@ func TestGetAllTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetAllTask",emailJwt,priority).Return(task,nil)
+		taskService.On("GetAllTask", mock.Anything, emailJwt,priority).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/tasks",taskHandler.GetAllTask)
+		app.Get("/tasks", fakeAuth, taskHandler.GetAllTask)
 
 		// NOTE - httptest.NewRequest ส่งจะ 3 ตัว 1. method 2. url 3. body
 		req := httptest.NewRequest("GET",fmt.Sprintf("/tasks?priority=%s",priority),nil)
@@ -58,10 +66,10 @@ func TestGetAllTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetAllTask",emailJwt,priority).Return(nil,errors.New("User not authenticated"))
+		taskService.On("GetAllTask", mock.Anything, emailJwt,priority).Return(nil,errors.New("User not authenticated"))
 
 		app := fiber.New()
-		app.Get("/tasks",taskHandler.GetAllTask)
+		app.Get("/tasks", fakeAuth, taskHandler.GetAllTask)
 
 		// NOTE - httptest.NewRequest ส่งจะ 3 ตัว 1. method 2. url 3. body
 		req := httptest.NewRequest("GET",fmt.Sprintf("/tasks?priority=%s",priority),nil)
@@ -80,10 +88,10 @@ func TestGetAllTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetAllTask",emailJwt,priority).Return(nil,errors.New("Can't get all tasks"))
+		taskService.On("GetAllTask", mock.Anything, emailJwt,priority).Return(nil,errors.New("Can't get all tasks"))
 
 		app := fiber.New()
-		app.Get("/tasks",taskHandler.GetAllTask)
+		app.Get("/tasks", fakeAuth, taskHandler.GetAllTask)
 
 		// NOTE - httptest.NewRequest ส่งจะ 3 ตัว 1. method 2. url 3. body
 		req := httptest.NewRequest("GET",fmt.Sprintf("/tasks?priority=%s",priority),nil)
@@ -113,10 +121,10 @@ func TestCreateTask(t *testing.T){
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("CreateTask", task, emailJwt).Return(nil)
+		taskService.On("CreateTask", mock.Anything, task, emailJwt).Return(nil)
 
 		app := fiber.New()
-		app.Post("/task", taskHandler.CreateTask)
+		app.Post("/task", fakeAuth, taskHandler.CreateTask)
 
 		reqBody := []byte(`{
 			"title": "Test Task",
@@ -148,10 +156,10 @@ func TestCreateTask(t *testing.T){
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("CreateTask", task, emailJwt).Return(nil)
+		taskService.On("CreateTask", mock.Anything, task, emailJwt).Return(nil)
 
 		app := fiber.New()
-		app.Post("/task", taskHandler.CreateTask)
+		app.Post("/task", fakeAuth, taskHandler.CreateTask)
 
 		req := httptest.NewRequest("POST", "/task", nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -181,10 +189,10 @@ func TestCreateTask(t *testing.T){
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("CreateTask", task, emailJwt).Return(nil)
+		taskService.On("CreateTask", mock.Anything, task, emailJwt).Return(nil)
 
 		app := fiber.New()
-		app.Post("/task", taskHandler.CreateTask)
+		app.Post("/task", fakeAuth, taskHandler.CreateTask)
 
 		reqBody := []byte(`{
 			"title": "Test Task",
@@ -214,10 +222,10 @@ func TestCreateTask(t *testing.T){
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("CreateTask", task, emailJwt).Return(errors.New("Can't create task"))
+		taskService.On("CreateTask", mock.Anything, task, emailJwt).Return(errors.New("Can't create task"))
 
 		app := fiber.New()
-		app.Post("/task", taskHandler.CreateTask)
+		app.Post("/task", fakeAuth, taskHandler.CreateTask)
 
 		reqBody := []byte(`{
 			"title": "Test Task",
@@ -249,15 +257,15 @@ func TestFindTaskById(t *testing.T){
 		}
 		idStr:= "1"
 
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("FindTaskById", idStr,emailCookie).Return(task,nil)
+		taskService.On("FindTaskById", mock.Anything, idStr,userEmail).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/task/:id", taskHandler.FindTaskById)
+		app.Get("/task/:id", fakeAuth, taskHandler.FindTaskById)
 
 		req := httptest.NewRequest("GET", "/task/1", nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -281,7 +289,7 @@ func TestFindTaskById(t *testing.T){
 		taskHandler := handlers.NewTaskHandler(taskService)
 
 		app := fiber.New()
-		app.Get("/task/:id", taskHandler.FindTaskById)
+		app.Get("/task/:id", fakeAuth, taskHandler.FindTaskById)
 
 		req := httptest.NewRequest("GET", "/task/1", nil)
 		req.Header.Set("Cookie", "jwt=")
@@ -301,11 +309,11 @@ func TestFindTaskById(t *testing.T){
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 		
-		emailCookie := "fakeJWT@gmail.com"
-		taskService.On("FindTaskById", "1",emailCookie).Return(nil,errors.New("User not authenticated"))
+		userEmail := "fakeJWT@gmail.com"
+		taskService.On("FindTaskById", mock.Anything, "1",userEmail).Return(nil,errors.New("User not authenticated"))
 
 		app := fiber.New()
-		app.Get("/task", taskHandler.FindTaskById)
+		app.Get("/task", fakeAuth, taskHandler.FindTaskById)
 
 		req := httptest.NewRequest("GET", "/task", nil)
 		req.Header.Set("Cookie", "jwt=fakeJWT@gmail.com")
@@ -324,15 +332,15 @@ func TestFindTaskById(t *testing.T){
 		// NOTE - Arrange
 		idStr:= "1"
 
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("FindTaskById", idStr,emailCookie).Return(nil,errors.New("Can't find task"))
+		taskService.On("FindTaskById", mock.Anything, idStr,userEmail).Return(nil,errors.New("Can't find task"))
 
 		app := fiber.New()
-		app.Get("/task/:id", taskHandler.FindTaskById)
+		app.Get("/task/:id", fakeAuth, taskHandler.FindTaskById)
 
 		req := httptest.NewRequest("GET", "/task/1", nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -357,16 +365,16 @@ func TestUpdateTask(t *testing.T){
 			Title: "Updated Task",
 			Description: "This is an updated task",
 		}
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 		idStr := "1"
 
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("UpdateTaskById",idStr,emailCookie,task).Return(nil)
+		taskService.On("UpdateTaskById", mock.Anything, idStr,userEmail,task).Return(nil)
 
 		app:= fiber.New()
-		app.Put("/task/:id",taskHandler.UpdateTask)
+		app.Put("/task/:id", fakeAuth, taskHandler.UpdateTask)
 
 		reqBody := []byte(`{
 		"Title": "Updated Task",
@@ -391,7 +399,7 @@ func TestUpdateTask(t *testing.T){
 		taskHandler := handlers.NewTaskHandler(taskService)
 
 		app:= fiber.New()
-		app.Put("/task/",taskHandler.UpdateTask)
+		app.Put("/task/", fakeAuth, taskHandler.UpdateTask)
 
 		req := httptest.NewRequest("PUT","/task/",nil)
 
@@ -410,7 +418,7 @@ func TestUpdateTask(t *testing.T){
 		taskHandler := handlers.NewTaskHandler(taskService)
 
 		app:= fiber.New()
-		app.Put("/task/:id",taskHandler.UpdateTask)
+		app.Put("/task/:id", fakeAuth, taskHandler.UpdateTask)
 
 		req := httptest.NewRequest("PUT","/task/1",nil)
 
@@ -429,16 +437,16 @@ func TestUpdateTask(t *testing.T){
 			Title: "Updated Task",
 			Description: "This is an updated task",
 		}
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 		idStr := "1"
 
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("UpdateTaskById",idStr,emailCookie,task).Return(nil)
+		taskService.On("UpdateTaskById", mock.Anything, idStr,userEmail,task).Return(nil)
 
 		app:= fiber.New()
-		app.Put("/task/:id",taskHandler.UpdateTask)
+		app.Put("/task/:id", fakeAuth, taskHandler.UpdateTask)
 
 		req := httptest.NewRequest("PUT","/task/1",nil)
 		req.Header.Set("Content-Type","application/json")
@@ -459,16 +467,16 @@ func TestUpdateTask(t *testing.T){
 			Title: "Updated Task",
 			Description: "This is an updated task",
 		}
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 		idStr := "1"
 
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("UpdateTaskById",idStr,emailCookie,task).Return(errors.New("Can't update task"))
+		taskService.On("UpdateTaskById", mock.Anything, idStr,userEmail,task).Return(errors.New("Can't update task"))
 
 		app:= fiber.New()
-		app.Put("/task/:id",taskHandler.UpdateTask)
+		app.Put("/task/:id", fakeAuth, taskHandler.UpdateTask)
 
 		reqBody := []byte(`{
 		"Title": "Updated Task",
@@ -492,16 +500,16 @@ func TestUpdateTask(t *testing.T){
 func TestDelete(t *testing.T){
 	t.Run("DeleteTask Success",func(t *testing.T) {
 
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 		idStr := "1"
 
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("DeleteTaskById",idStr,emailCookie).Return(nil)
+		taskService.On("DeleteTaskById", mock.Anything, idStr,userEmail).Return(nil)
 
 		app:= fiber.New()
-		app.Delete("/task/:id",taskHandler.DeleteTask)
+		app.Delete("/task/:id", fakeAuth, taskHandler.DeleteTask)
 
 		req := httptest.NewRequest("DELETE","/task/1",nil)
 		req.Header.Set("Cookie","jwt=fakeJWT@gmail.com")
@@ -518,16 +526,16 @@ func TestDelete(t *testing.T){
 
 	t.Run("DeleteTask ID is required",func(t *testing.T) {
 
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 		idStr := ""
 
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("DeleteTaskById",idStr,emailCookie).Return(nil)
+		taskService.On("DeleteTaskById", mock.Anything, idStr,userEmail).Return(nil)
 
 		app:= fiber.New()
-		app.Delete("/task",taskHandler.DeleteTask)
+		app.Delete("/task", fakeAuth, taskHandler.DeleteTask)
 
 		req := httptest.NewRequest("DELETE","/task",nil)
 		req.Header.Set("Cookie","jwt=fakeJWT@gmail.com")
@@ -543,16 +551,16 @@ func TestDelete(t *testing.T){
 	})
 	t.Run("DeleteTask Not authenticated",func(t *testing.T) {
 
-		emailCookie := ""
+		userEmail := ""
 		idStr := "1"
 
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("DeleteTaskById",idStr,emailCookie).Return(nil)
+		taskService.On("DeleteTaskById", mock.Anything, idStr,userEmail).Return(nil)
 
 		app:= fiber.New()
-		app.Delete("/task/:id",taskHandler.DeleteTask)
+		app.Delete("/task/:id", fakeAuth, taskHandler.DeleteTask)
 
 		req := httptest.NewRequest("DELETE","/task/1",nil)
 		res,err:=app.Test(req)
@@ -567,16 +575,16 @@ func TestDelete(t *testing.T){
 
 	t.Run("DeleteTask BadRequest",func(t *testing.T) {
 
-		emailCookie := "fakeJWT@gmail.com"
+		userEmail := "fakeJWT@gmail.com"
 		idStr := "1"
 
 		taskService := new(services.TaskServiceMock)
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("DeleteTaskById",idStr,emailCookie).Return(errors.New("Can't delete task"))
+		taskService.On("DeleteTaskById", mock.Anything, idStr,userEmail).Return(errors.New("Can't delete task"))
 
 		app:= fiber.New()
-		app.Delete("/task/:id",taskHandler.DeleteTask)
+		app.Delete("/task/:id", fakeAuth, taskHandler.DeleteTask)
 
 		req := httptest.NewRequest("DELETE","/task/1",nil)
 		req.Header.Set("Cookie","jwt=fakeJWT@gmail.com")
@@ -601,15 +609,15 @@ func TestGetCompleteTask(t *testing.T) {
 			Description: "This is a complete task2",},
 		}
 
-		// emailCookie := "fakeJWT@gmail.com"
+		// userEmail := "fakeJWT@gmail.com"
 		priority := "high"
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetCompleteTask",mock.Anything,mock.Anything).Return(task,nil)
+		taskService.On("GetCompleteTask", mock.Anything, mock.Anything,mock.Anything).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/task/complete",taskHandler.GetCompleteTask)
+		app.Get("/task/complete", fakeAuth, taskHandler.GetCompleteTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/complete?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -642,10 +650,10 @@ func TestGetCompleteTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetCompleteTask",mock.Anything,mock.Anything).Return(task,nil)
+		taskService.On("GetCompleteTask", mock.Anything, mock.Anything,mock.Anything).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/task/complete",taskHandler.GetCompleteTask)
+		app.Get("/task/complete", fakeAuth, taskHandler.GetCompleteTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/complete?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -668,10 +676,10 @@ func TestGetCompleteTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetCompleteTask",mock.Anything,mock.Anything).Return(nil,errors.New("Error to get complete task"))
+		taskService.On("GetCompleteTask", mock.Anything, mock.Anything,mock.Anything).Return(nil,errors.New("Error to get complete task"))
 
 		app := fiber.New()
-		app.Get("/task/complete",taskHandler.GetCompleteTask)
+		app.Get("/task/complete", fakeAuth, taskHandler.GetCompleteTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/complete?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -699,15 +707,15 @@ func TestGetPendingTask(t *testing.T) {
 			Description: "This is a pending task2",},
 		}
 
-		// emailCookie := "fakeJWT@gmail.com"
+		// userEmail := "fakeJWT@gmail.com"
 		priority := "high"
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetPendingTask",mock.Anything,mock.Anything).Return(task,nil)
+		taskService.On("GetPendingTask", mock.Anything, mock.Anything,mock.Anything).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/task/pending",taskHandler.GetPendingTask)
+		app.Get("/task/pending", fakeAuth, taskHandler.GetPendingTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/pending?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -740,10 +748,10 @@ func TestGetPendingTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetPendingTask",mock.Anything,mock.Anything).Return(task,nil)
+		taskService.On("GetPendingTask", mock.Anything, mock.Anything,mock.Anything).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/task/pending",taskHandler.GetPendingTask)
+		app.Get("/task/pending", fakeAuth, taskHandler.GetPendingTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/pending?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -766,10 +774,10 @@ func TestGetPendingTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetPendingTask",mock.Anything,mock.Anything).Return(nil,errors.New("Error to get pending task"))
+		taskService.On("GetPendingTask", mock.Anything, mock.Anything,mock.Anything).Return(nil,errors.New("Error to get pending task"))
 
 		app := fiber.New()
-		app.Get("/task/pending",taskHandler.GetPendingTask)
+		app.Get("/task/pending", fakeAuth, taskHandler.GetPendingTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/pending?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -797,15 +805,15 @@ func TestGetOverdueTask(t *testing.T) {
 			Description: "This is a overdueTask task2",},
 		}
 
-		// emailCookie := "fakeJWT@gmail.com"
+		// userEmail := "fakeJWT@gmail.com"
 		priority := "high"
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetOverdueTask",mock.Anything,mock.Anything).Return(task,nil)
+		taskService.On("GetOverdueTask", mock.Anything, mock.Anything,mock.Anything).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/task/overdueTask",taskHandler.GetOverdueTask)
+		app.Get("/task/overdueTask", fakeAuth, taskHandler.GetOverdueTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/overdueTask?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -838,10 +846,10 @@ func TestGetOverdueTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetOverdueTask",mock.Anything,mock.Anything).Return(task,nil)
+		taskService.On("GetOverdueTask", mock.Anything, mock.Anything,mock.Anything).Return(task,nil)
 
 		app := fiber.New()
-		app.Get("/task/overdueTask",taskHandler.GetOverdueTask)
+		app.Get("/task/overdueTask", fakeAuth, taskHandler.GetOverdueTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/overdueTask?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -864,10 +872,10 @@ func TestGetOverdueTask(t *testing.T) {
 		taskService := services.NewTaskServiceMock()
 		taskHandler := handlers.NewTaskHandler(taskService)
 
-		taskService.On("GetOverdueTask",mock.Anything,mock.Anything).Return(nil,errors.New("Error to get overdueTask task"))
+		taskService.On("GetOverdueTask", mock.Anything, mock.Anything,mock.Anything).Return(nil,errors.New("Error to get overdueTask task"))
 
 		app := fiber.New()
-		app.Get("/task/overdueTask",taskHandler.GetOverdueTask)
+		app.Get("/task/overdueTask", fakeAuth, taskHandler.GetOverdueTask)
 
 		req := httptest.NewRequest("GET",fmt.Sprintf("/task/overdueTask?priority=%s",priority),nil)
 		req.Header.Set("Content-Type", "application/json")
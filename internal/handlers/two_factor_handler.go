@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService services.TwoFactorServiceInterface
+	cookies          utils.CookieConfig
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type loginMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+func NewTwoFactorHandler(twoFactorService services.TwoFactorServiceInterface, cookies utils.CookieConfig) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService, cookies: cookies}
+}
+
+func (h *TwoFactorHandler) Enroll(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	key, err := h.twoFactorService.Enroll(c.UserContext(), userEmail)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Scan the QR code and verify with a code from your app",
+		"totp":    key,
+	})
+}
+
+func (h *TwoFactorHandler) Verify(c *fiber.Ctx) error {
+	req := new(twoFactorCodeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	codes, err := h.twoFactorService.Verify(c.UserContext(), userEmail, req.Code)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	req := new(twoFactorCodeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.twoFactorService.Disable(c.UserContext(), userEmail, req.Code); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func (h *TwoFactorHandler) LoginMFA(c *fiber.Ctx) error {
+	req := new(loginMFARequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	token, userDetail, err := h.twoFactorService.LoginMFA(c.UserContext(), req.MFAToken, req.Code, clientInfo(c))
+
+	var throttled *services.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// NOTE - Set cookie
+	c.Cookie(h.cookies.AuthCookie(token))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Login success",
+		"token":   token,
+		"user": fiber.Map{
+			"id":    userDetail.ID,
+			"email": userDetail.Email,
+			"name":  userDetail.Name,
+		},
+	})
+}
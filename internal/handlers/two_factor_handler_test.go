@@ -0,0 +1,147 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEnrollTwoFactor(t *testing.T) {
+	t.Run("Enroll success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+		key := &utils.TOTPKey{Secret: "SECRET", URI: "otpauth://totp/BelugaTasks:test@gmail.com?secret=SECRET"}
+
+		twoFactorService := services.NewTwoFactorServiceMock()
+		twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, testCookies)
+
+		twoFactorService.On("Enroll", mock.Anything, userEmail).Return(key, nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/2fa/enroll", testMiddleware, twoFactorHandler.Enroll)
+
+		req := httptest.NewRequest("POST", "/user/2fa/enroll", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "otpauth://totp/")
+	})
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	t.Run("Verify success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+
+		twoFactorService := services.NewTwoFactorServiceMock()
+		twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, testCookies)
+
+		twoFactorService.On("Verify", mock.Anything, userEmail, "123456").Return([]string{"AAAAAAAA-BBBBBBBB"}, nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/2fa/verify", testMiddleware, twoFactorHandler.Verify)
+
+		req := httptest.NewRequest("POST", "/user/2fa/verify", bytes.NewReader([]byte(`{"code":"123456"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "AAAAAAAA-BBBBBBBB")
+	})
+}
+
+func TestLoginMFA(t *testing.T) {
+	t.Run("Login MFA success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Name:  "Test User",
+		}
+
+		twoFactorService := services.NewTwoFactorServiceMock()
+		twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, testCookies)
+
+		twoFactorService.On("LoginMFA", mock.Anything, "mfaToken", "123456", mock.AnythingOfType("services.ClientInfo")).Return("jwtToken", user, nil)
+
+		app := fiber.New()
+		app.Post("/user/login/mfa", twoFactorHandler.LoginMFA)
+
+		req := httptest.NewRequest("POST", "/user/login/mfa", bytes.NewReader([]byte(`{"mfa_token":"mfaToken","code":"123456"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Header.Get("Set-Cookie"), "jwt=jwtToken")
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Login success")
+	})
+
+	t.Run("Login MFA invalid code", func(t *testing.T) {
+		twoFactorService := services.NewTwoFactorServiceMock()
+		twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, testCookies)
+
+		twoFactorService.On("LoginMFA", mock.Anything, "mfaToken", "000000", mock.AnythingOfType("services.ClientInfo")).Return("", nil, errors.New("Invalid code"))
+
+		app := fiber.New()
+		app.Post("/user/login/mfa", twoFactorHandler.LoginMFA)
+
+		req := httptest.NewRequest("POST", "/user/login/mfa", bytes.NewReader([]byte(`{"mfa_token":"mfaToken","code":"000000"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Invalid code")
+	})
+
+	t.Run("Login MFA throttled", func(t *testing.T) {
+		twoFactorService := services.NewTwoFactorServiceMock()
+		twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, testCookies)
+
+		twoFactorService.On("LoginMFA", mock.Anything, "mfaToken", "000000", mock.AnythingOfType("services.ClientInfo")).Return("", nil, &services.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+
+		app := fiber.New()
+		app.Post("/user/login/mfa", twoFactorHandler.LoginMFA)
+
+		req := httptest.NewRequest("POST", "/user/login/mfa", bytes.NewReader([]byte(`{"mfa_token":"mfaToken","code":"000000"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusTooManyRequests, res.StatusCode)
+		assert.Equal(t, "2", res.Header.Get(fiber.HeaderRetryAfter))
+	})
+}
@@ -1,29 +1,41 @@
 package handlers
 
 import (
-	"time"
+	"errors"
+	"math"
+	"strconv"
 
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
+// NOTE - รับแค่ field ที่สมัครเองได้ ไม่ parse เข้า models.Users ตรงๆ กันส่ง role/two factor มาเอง
+type registerRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
 type UserHandler struct {
 	userService services.UserServiceInterface
+	cookies utils.CookieConfig
 }
 
-func NewUserHandler(userService services.UserServiceInterface) *UserHandler{
-	return &UserHandler{userService:userService}
+func NewUserHandler(userService services.UserServiceInterface, cookies utils.CookieConfig) *UserHandler{
+	return &UserHandler{userService:userService, cookies:cookies}
 }
 
 func (h *UserHandler) RegisterUser(c *fiber.Ctx) error {
-	user := new(models.Users)
-	if err:= c.BodyParser(user); err != nil {
+	req := new(registerRequest)
+	if err:= c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error":"Invalid request"})
 	}
 
 	// NOTE - Call service Register
-	err := h.userService.RegisterUser(user)
+	user := &models.Users{Email: req.Email, Name: req.Name, Password: req.Password}
+	err := h.userService.RegisterUser(c.UserContext(), user)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error":err.Error()})
 	}
@@ -38,23 +50,30 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// NOTE - Call Service login
-	token, userDetail ,err := h.userService.Login(user)
+	token, userDetail ,err := h.userService.Login(c.UserContext(), user, clientInfo(c))
+
+	// NOTE - เปิด 2FA ไว้ ส่ง challenge token กลับไปยังไม่ตั้ง cookie
+	if errors.Is(err, services.ErrMFARequired) {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message":"Two-factor authentication required",
+			"mfa_required":true,
+			"mfa_token":token,
+		})
+	}
+
+	// NOTE - ผิดบ่อยเกินไป บอกเวลาที่ต้องรอผ่าน Retry-After
+	var throttled *services.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error":err.Error()})
+	}
 
 	if err !=nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error":err.Error()})
 	}
 
 	// NOTE - Set cookie
-	c.Cookie(&fiber.Cookie{
-		Name: "jwt",
-		Value: token,
-		Expires: time.Now().Add(time.Hour*72),
-		Domain: ".belugatasks.dev",
-		HTTPOnly: true,
-		Secure:true,
-		SameSite: fiber.CookieSameSiteNoneMode, 
-		
-	})
+	c.Cookie(h.cookies.AuthCookie(token))
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":"Login success",
@@ -68,7 +87,13 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 }
 
 func (h *UserHandler) Logout(c *fiber.Ctx) error {
-	c.ClearCookie();
+	// NOTE - ปิด session ฝั่ง server ด้วย ไม่ใช่แค่ลบ cookie
+	if err := h.userService.Logout(c.UserContext(), h.cookies.AuthToken(c), clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error":err.Error()})
+	}
+
+	// NOTE - ต้องใช้ domain/path/samesite เดียวกับตอนตั้ง browser ถึงจะลบให้
+	c.Cookie(h.cookies.ClearAuthCookie())
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":"Logout Success",
@@ -78,7 +103,7 @@ func (h *UserHandler) Logout(c *fiber.Ctx) error {
 func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 	userEmail := c.Locals("userEmail").(string)
 
-	user, err := h.userService.GetUserByEmail(userEmail)
+	user, err := h.userService.GetUserByEmail(c.UserContext(), userEmail)
 
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error":err.Error()})
@@ -100,13 +125,13 @@ func (h *UserHandler) EditUser(c *fiber.Ctx) error {
 				"error":"Task ID is required",
 			})
 		}
-	// NOTE - ดึง Email จาก cookie
-	emailCookie := c.Cookies("jwt")
-	if emailCookie == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "User not authenticated",
-        })
-    }
+	// NOTE - AuthMiddleware ใส่ email ไว้ให้ ใช้ได้ทั้ง cookie และ personal access token
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
 
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -114,7 +139,7 @@ func (h *UserHandler) EditUser(c *fiber.Ctx) error {
 		})
 	}
 
-	if err :=h.userService.UpdateUserById(idStr, emailCookie, user); err !=nil{
+	if err :=h.userService.UpdateUserById(c.UserContext(), idStr, userEmail, user); err !=nil{
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error :": err.Error(),
 		})
@@ -125,3 +150,7 @@ func (h *UserHandler) EditUser(c *fiber.Ctx) error {
 	})
 }
 
+// NOTE - เก็บไว้กับ session ให้ผู้ใช้ดูว่า login จากที่ไหน
+func clientInfo(c *fiber.Ctx) services.ClientInfo {
+	return services.ClientInfo{IP: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
+}
@@ -6,14 +6,25 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Beluga-Whale/management-api/internal/handlers"
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+var testCookies = utils.CookieConfig{
+	Name: "jwt",
+	Domain: ".belugatasks.dev",
+	MaxAge: 72 * time.Hour,
+	SameSite: "None",
+	Secure: true,
+}
+
 func TestRegisterUser(t *testing.T) {
 	t.Run("Test Register Success",func(t *testing.T) {
 		// NOTE - Arrange
@@ -24,9 +35,9 @@ func TestRegisterUser(t *testing.T) {
 		}
 		
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("RegisterUser",user).Return(nil)
+		userService.On("RegisterUser", mock.Anything, user).Return(nil)
 
 		app := fiber.New()
 		app.Post("/user/register",userHandler.RegisterUser)
@@ -53,7 +64,7 @@ func TestRegisterUser(t *testing.T) {
 
 	t.Run("RegisterUser ฺBody empty Invalid request", func(t *testing.T) {
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
 		app := fiber.New()
 		app.Post("/register", userHandler.RegisterUser)
@@ -79,9 +90,9 @@ func TestRegisterUser(t *testing.T) {
 		}
 		
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("RegisterUser",user).Return(errors.New("User already exists"))
+		userService.On("RegisterUser", mock.Anything, user).Return(errors.New("User already exists"))
 
 		app := fiber.New()
 		app.Post("/user/register",userHandler.RegisterUser)
@@ -117,13 +128,13 @@ func TestLogin(t *testing.T){
 		jwtToken := "fake_jwtToken"
 
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
 		expectUser := &models.Users{
 			Email: "test@gmail.com",
 			Name: "Test User",
 		}
-		userService.On("Login",userLogin).Return(jwtToken,expectUser,nil)	
+		userService.On("Login", mock.Anything, userLogin,mock.Anything).Return(jwtToken,expectUser,nil)	
 
 		app:= fiber.New()
 		app.Post("/user/login",userHandler.Login)
@@ -144,9 +155,40 @@ func TestLogin(t *testing.T){
 
 		assert.Contains(t,string(body),"Login success")
 	})
+	t.Run("Test Login MFA required",func(t *testing.T) {
+		userLogin := &models.Users{
+			Email:"test@gmail.com",
+			Password:"password123",
+		}
+
+		userService := services.NewUserServiceMock()
+		userHandler := handlers.NewUserHandler(userService, testCookies)
+
+		userService.On("Login", mock.Anything, userLogin,mock.Anything).Return("mfaToken",userLogin,services.ErrMFARequired)
+
+		app:= fiber.New()
+		app.Post("/user/login",userHandler.Login)
+
+		reqBody := []byte(`{
+			"email":"test@gmail.com",
+			"password":"password123"
+		}`)
+		req := httptest.NewRequest("POST","/user/login",bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t,fiber.StatusOK,res.StatusCode)
+		assert.Empty(t,res.Header.Get("Set-Cookie"))
+
+		body,_ := io.ReadAll(res.Body)
+
+		assert.Contains(t,string(body),"mfaToken")
+	})
 	t.Run("Test Login BadRequest",func(t *testing.T) {
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
 		app:= fiber.New()
 		app.Post("/user/login",userHandler.Login)
@@ -171,9 +213,9 @@ func TestLogin(t *testing.T){
 			Password:"password123",
 		}
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("Login",userLogin).Return("",nil,errors.New("User not found"))	
+		userService.On("Login", mock.Anything, userLogin,mock.Anything).Return("",nil,errors.New("User not found"))	
 
 		app:= fiber.New()
 		app.Post("/user/login",userHandler.Login)
@@ -196,10 +238,41 @@ func TestLogin(t *testing.T){
 	})
 }
 
+func TestLoginThrottled(t *testing.T){
+	t.Run("Test Login Too many attempts",func(t *testing.T) {
+		userLogin := &models.Users{
+			Email:"test@gmail.com",
+			Password:"password123",
+		}
+		userService := services.NewUserServiceMock()
+		userHandler := handlers.NewUserHandler(userService, testCookies)
+
+		userService.On("Login", mock.Anything, userLogin,mock.Anything).Return("",nil,&services.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+
+		app:= fiber.New()
+		app.Post("/user/login",userHandler.Login)
+
+		reqBody := []byte(`{
+			"email":"test@gmail.com",
+			"password":"password123"
+		}`)
+		req := httptest.NewRequest("POST","/user/login",bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t,fiber.StatusTooManyRequests,res.StatusCode)
+		assert.Equal(t,"2",res.Header.Get("Retry-After"))
+	})
+}
+
 func TestLogout(t *testing.T){
 	t.Run("Test Logout Success",func(t *testing.T) {
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
+
+		userService.On("Logout", mock.Anything, "",mock.AnythingOfType("services.ClientInfo")).Return(nil)
 
 		app := fiber.New()
 		app.Post("/user/logout",userHandler.Logout)
@@ -216,6 +289,52 @@ func TestLogout(t *testing.T){
 
 		assert.Contains(t,string(body),"Logout Success")
 	})
+
+	t.Run("Test Logout ends session",func(t *testing.T) {
+		userService := services.NewUserServiceMock()
+		userHandler := handlers.NewUserHandler(userService, testCookies)
+
+		userService.On("Logout", mock.Anything, "jwtToken",mock.AnythingOfType("services.ClientInfo")).Return(nil)
+
+		app := fiber.New()
+		app.Post("/user/logout",userHandler.Logout)
+
+		req := httptest.NewRequest("POST","/user/logout",nil)
+		req.Header.Set("Cookie","jwt=jwtToken")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t,fiber.StatusOK,res.StatusCode)
+		userService.AssertExpectations(t)
+	})
+
+	t.Run("Test Logout clears cookie with same attributes",func(t *testing.T) {
+		userService := services.NewUserServiceMock()
+		userHandler := handlers.NewUserHandler(userService, testCookies)
+
+		userService.On("Logout", mock.Anything, "jwtToken",mock.AnythingOfType("services.ClientInfo")).Return(nil)
+
+		app := fiber.New()
+		app.Post("/user/logout",userHandler.Logout)
+
+		req := httptest.NewRequest("POST","/user/logout",nil)
+		req.Header.Set("Cookie","jwt=jwtToken")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+
+		cookies := res.Cookies()
+		assert.Len(t,cookies,1)
+		assert.Equal(t,"jwt",cookies[0].Name)
+		assert.Equal(t,"",cookies[0].Value)
+		assert.Equal(t,".belugatasks.dev",cookies[0].Domain)
+		assert.Equal(t,"/",cookies[0].Path)
+		assert.True(t,cookies[0].Secure)
+		assert.True(t,cookies[0].HttpOnly)
+		assert.True(t,cookies[0].Expires.Before(time.Now()))
+	})
 }
 
 func TestGetUser(t *testing.T){
@@ -227,9 +346,9 @@ func TestGetUser(t *testing.T){
 		}
 
 		userService := new(services.UserServiceMock)
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("GetUserByEmail", userEmail).Return(expectedUser, nil)
+		userService.On("GetUserByEmail", mock.Anything, userEmail).Return(expectedUser, nil)
 
 		testMiddleware := func(c *fiber.Ctx) error {
 			c.Locals("userEmail", userEmail)
@@ -257,9 +376,9 @@ func TestGetUser(t *testing.T){
 		userEmail := "test@example.com"
 
 		userService := new(services.UserServiceMock)
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("GetUserByEmail", userEmail).Return(nil, errors.New("User not found"))
+		userService.On("GetUserByEmail", mock.Anything, userEmail).Return(nil, errors.New("User not found"))
 
 		testMiddleware := func(c *fiber.Ctx) error {
 			c.Locals("userEmail", userEmail)
@@ -290,15 +409,15 @@ func TestEditUser(t *testing.T){
 			Email: "test@gmail.com",
 		}
 
-		emailCookie := "fake_jwtToken"
+		userEmail := "fake_jwtToken"
 
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("UpdateUserById","1",emailCookie,userMock).Return(nil)
+		userService.On("UpdateUserById", mock.Anything, "1",userEmail,userMock).Return(nil)
 
 		app := fiber.New()
-		app.Put("/user/:id", userHandler.EditUser)
+		app.Put("/user/:id", fakeAuth, userHandler.EditUser)
 
 		reqBody := []byte(`{
 			"name":"Edit User",
@@ -307,7 +426,7 @@ func TestEditUser(t *testing.T){
 
 		req := httptest.NewRequest("PUT", "/user/1", bytes.NewReader(reqBody))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Cookie", "jwt="+emailCookie)
+		req.Header.Set("Cookie", "jwt="+userEmail)
 
 		res, err := app.Test(req)
 
@@ -319,10 +438,10 @@ func TestEditUser(t *testing.T){
 	t.Run("EditUser ID is required",func(t *testing.T) {
 
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
 		app := fiber.New()
-		app.Put("/user", userHandler.EditUser)
+		app.Put("/user", fakeAuth, userHandler.EditUser)
 
 
 		req := httptest.NewRequest("PUT", "/user",nil)
@@ -339,11 +458,11 @@ func TestEditUser(t *testing.T){
 	
 	t.Run("EditUser not authenticated",func(t *testing.T) {
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
 
 		app := fiber.New()
-		app.Put("/user/:id", userHandler.EditUser)
+		app.Put("/user/:id", fakeAuth, userHandler.EditUser)
 
 		reqBody := []byte(`{
 			"name":"Edit User",
@@ -369,19 +488,19 @@ func TestEditUser(t *testing.T){
 			Email: "test@gmail.com",
 		}
 
-		emailCookie := "fake_jwtToken"
+		userEmail := "fake_jwtToken"
 
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("UpdateUserById","1",emailCookie,userMock).Return(nil)
+		userService.On("UpdateUserById", mock.Anything, "1",userEmail,userMock).Return(nil)
 
 		app := fiber.New()
-		app.Put("/user/:id", userHandler.EditUser)
+		app.Put("/user/:id", fakeAuth, userHandler.EditUser)
 
 		req := httptest.NewRequest("PUT", "/user/1", nil)
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Cookie", "jwt="+emailCookie)
+		req.Header.Set("Cookie", "jwt="+userEmail)
 
 		res, err := app.Test(req)
 
@@ -398,15 +517,15 @@ func TestEditUser(t *testing.T){
 			Email: "test@gmail.com",
 		}
 
-		emailCookie := "fake_jwtToken"
+		userEmail := "fake_jwtToken"
 
 		userService := services.NewUserServiceMock()
-		userHandler := handlers.NewUserHandler(userService)
+		userHandler := handlers.NewUserHandler(userService, testCookies)
 
-		userService.On("UpdateUserById","1",emailCookie,userMock).Return(errors.New("User not found"))
+		userService.On("UpdateUserById", mock.Anything, "1",userEmail,userMock).Return(errors.New("User not found"))
 
 		app := fiber.New()
-		app.Put("/user/:id", userHandler.EditUser)
+		app.Put("/user/:id", fakeAuth, userHandler.EditUser)
 
 		reqBody := []byte(`{
 			"name":"Edit User",
@@ -415,7 +534,7 @@ func TestEditUser(t *testing.T){
 
 		req := httptest.NewRequest("PUT", "/user/1", bytes.NewReader(reqBody))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Cookie", "jwt="+emailCookie)
+		req.Header.Set("Cookie", "jwt="+userEmail)
 
 		res, err := app.Test(req)
 
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebAuthnHandler struct {
+	webAuthnService services.WebAuthnServiceInterface
+	cookies         utils.CookieConfig
+}
+
+// NOTE - credential คือ object ที่ได้จาก navigator.credentials.create()/get() ส่งมาทั้งก้อน
+type webAuthnFinishRequest struct {
+	SessionID  string          `json:"session_id"`
+	Name       string          `json:"name"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+type renameCredentialRequest struct {
+	Name string `json:"name"`
+}
+
+func NewWebAuthnHandler(webAuthnService services.WebAuthnServiceInterface, cookies utils.CookieConfig) *WebAuthnHandler {
+	return &WebAuthnHandler{webAuthnService: webAuthnService, cookies: cookies}
+}
+
+func (h *WebAuthnHandler) BeginRegistration(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	sessionID, options, err := h.webAuthnService.BeginRegistration(c.UserContext(), userEmail)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"session_id": sessionID,
+		"options":    options,
+	})
+}
+
+func (h *WebAuthnHandler) FinishRegistration(c *fiber.Ctx) error {
+	req := new(webAuthnFinishRequest)
+	if err := c.BodyParser(req); err != nil || len(req.Credential) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	credential, err := h.webAuthnService.FinishRegistration(c.UserContext(), userEmail, req.SessionID, req.Name, req.Credential)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":    "Passkey registered",
+		"credential": credential,
+	})
+}
+
+// NOTE - ไม่อ่าน body client เก่าที่ยังส่ง email มาก็ได้ discoverable challenge เหมือนกัน
+func (h *WebAuthnHandler) BeginLogin(c *fiber.Ctx) error {
+	sessionID, options, err := h.webAuthnService.BeginLogin(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"session_id": sessionID,
+		"options":    options,
+	})
+}
+
+func (h *WebAuthnHandler) FinishLogin(c *fiber.Ctx) error {
+	req := new(webAuthnFinishRequest)
+	if err := c.BodyParser(req); err != nil || len(req.Credential) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	token, userDetail, err := h.webAuthnService.FinishLogin(c.UserContext(), req.SessionID, req.Credential, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// NOTE - Set cookie
+	c.Cookie(h.cookies.AuthCookie(token))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Login success",
+		"token":   token,
+		"user": fiber.Map{
+			"id":    userDetail.ID,
+			"email": userDetail.Email,
+			"name":  userDetail.Name,
+		},
+	})
+}
+
+func (h *WebAuthnHandler) GetCredentials(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	credentials, err := h.webAuthnService.GetCredentials(c.UserContext(), userEmail)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": credentials,
+	})
+}
+
+func (h *WebAuthnHandler) RenameCredential(c *fiber.Ctx) error {
+	req := new(renameCredentialRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.webAuthnService.RenameCredential(c.UserContext(), userEmail, c.Params("id"), req.Name); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Rename passkey success",
+	})
+}
+
+func (h *WebAuthnHandler) DeleteCredential(c *fiber.Ctx) error {
+	userEmail, ok := c.Locals("userEmail").(string)
+	if !ok || userEmail == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.webAuthnService.DeleteCredential(c.UserContext(), userEmail, c.Params("id")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Delete passkey success",
+	})
+}
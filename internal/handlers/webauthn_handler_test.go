@@ -0,0 +1,238 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestBeginWebAuthnRegistration(t *testing.T) {
+	t.Run("Begin registration success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+
+		webAuthnService := services.NewWebAuthnServiceMock()
+		webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, testCookies)
+
+		webAuthnService.On("BeginRegistration", mock.Anything, userEmail).Return("sessionKey", &protocol.CredentialCreation{}, nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/webauthn/register/begin", testMiddleware, webAuthnHandler.BeginRegistration)
+
+		req := httptest.NewRequest("POST", "/user/webauthn/register/begin", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"session_id":"sessionKey"`)
+	})
+}
+
+func TestFinishWebAuthnRegistration(t *testing.T) {
+	t.Run("Finish registration success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+		credential := &models.WebAuthnCredentials{Name: "My laptop", Model: gorm.Model{ID: 1}}
+
+		webAuthnService := services.NewWebAuthnServiceMock()
+		webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, testCookies)
+
+		webAuthnService.On("FinishRegistration", mock.Anything, userEmail, "sessionKey", "My laptop", []byte(`{"id":"abc"}`)).Return(credential, nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/webauthn/register/finish", testMiddleware, webAuthnHandler.FinishRegistration)
+
+		req := httptest.NewRequest("POST", "/user/webauthn/register/finish", bytes.NewReader([]byte(`{"session_id":"sessionKey","name":"My laptop","credential":{"id":"abc"}}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Passkey registered")
+	})
+
+	t.Run("Missing credential", func(t *testing.T) {
+		webAuthnService := services.NewWebAuthnServiceMock()
+		webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, testCookies)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", "test@gmail.com")
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/user/webauthn/register/finish", testMiddleware, webAuthnHandler.FinishRegistration)
+
+		req := httptest.NewRequest("POST", "/user/webauthn/register/finish", bytes.NewReader([]byte(`{"session_id":"sessionKey"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		webAuthnService.AssertNotCalled(t, "FinishRegistration", mock.Anything)
+	})
+}
+
+func TestWebAuthnLogin(t *testing.T) {
+	t.Run("Begin discoverable login", func(t *testing.T) {
+		webAuthnService := services.NewWebAuthnServiceMock()
+		webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, testCookies)
+
+		webAuthnService.On("BeginLogin", mock.Anything).Return("sessionKey", &protocol.CredentialAssertion{}, nil)
+
+		app := fiber.New()
+		app.Post("/user/login/webauthn/begin", webAuthnHandler.BeginLogin)
+
+		req := httptest.NewRequest("POST", "/user/login/webauthn/begin", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		webAuthnService.AssertExpectations(t)
+	})
+
+	t.Run("Email with passkey and unknown email get the same challenge", func(t *testing.T) {
+		webAuthn, err := webauthn.New(&webauthn.Config{
+			RPID:          "localhost",
+			RPDisplayName: "BelugaTasks",
+			RPOrigins:     []string{"http://localhost:3000"},
+		})
+		require.NoError(t, err)
+
+		user := &models.Users{Email: "passkey@gmail.com", Model: gorm.Model{ID: 7}}
+		userRepo := repositories.NewUserRepositoryMock()
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		userRepo.On("FindByEmail", mock.Anything, "unknown@gmail.com").Return(nil, nil)
+		webAuthnRepo := repositories.NewWebAuthnRepositoryMock()
+		webAuthnRepo.On("FindCredentialsByUserId", mock.Anything, user.ID).Return([]models.WebAuthnCredentials{{UserID: user.ID, CredentialID: []byte("credential")}}, nil)
+		webAuthnRepo.On("CreateSession", mock.Anything, mock.Anything).Return(nil)
+
+		webAuthnHandler := handlers.NewWebAuthnHandler(services.NewWebAuthnService(userRepo, webAuthnRepo, webAuthn, services.NewSessionServiceMock()), testCookies)
+		app := fiber.New()
+		app.Post("/user/login/webauthn/begin", webAuthnHandler.BeginLogin)
+
+		// NOTE - challenge กับ session_id สุ่มทุกครั้ง ตัดออกแล้วที่เหลือต้องเหมือนกันทุก key
+		beginLogin := func(email string) map[string]interface{} {
+			req := httptest.NewRequest("POST", "/user/login/webauthn/begin", bytes.NewReader([]byte(`{"email":"`+email+`"}`)))
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+			delete(body, "session_id")
+			publicKey := body["options"].(map[string]interface{})["publicKey"].(map[string]interface{})
+			delete(publicKey, "challenge")
+			return body
+		}
+
+		known := beginLogin(user.Email)
+		unknown := beginLogin("unknown@gmail.com")
+
+		assert.Equal(t, unknown, known)
+		assert.NotContains(t, known["options"].(map[string]interface{})["publicKey"], "allowCredentials")
+	})
+
+	t.Run("Finish login success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Name:  "Test User",
+		}
+
+		webAuthnService := services.NewWebAuthnServiceMock()
+		webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, testCookies)
+
+		webAuthnService.On("FinishLogin", mock.Anything, "sessionKey", []byte(`{"id":"abc"}`), mock.AnythingOfType("services.ClientInfo")).Return("jwtToken", user, nil)
+
+		app := fiber.New()
+		app.Post("/user/login/webauthn/finish", webAuthnHandler.FinishLogin)
+
+		req := httptest.NewRequest("POST", "/user/login/webauthn/finish", bytes.NewReader([]byte(`{"session_id":"sessionKey","credential":{"id":"abc"}}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Header.Get("Set-Cookie"), "jwt=jwtToken")
+	})
+
+	t.Run("Finish login failed", func(t *testing.T) {
+		webAuthnService := services.NewWebAuthnServiceMock()
+		webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, testCookies)
+
+		webAuthnService.On("FinishLogin", mock.Anything, "sessionKey", []byte(`{"id":"abc"}`), mock.AnythingOfType("services.ClientInfo")).Return("", nil, errors.New("Credential may be cloned, please sign in another way"))
+
+		app := fiber.New()
+		app.Post("/user/login/webauthn/finish", webAuthnHandler.FinishLogin)
+
+		req := httptest.NewRequest("POST", "/user/login/webauthn/finish", bytes.NewReader([]byte(`{"session_id":"sessionKey","credential":{"id":"abc"}}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		assert.Empty(t, res.Header.Get("Set-Cookie"))
+	})
+}
+
+func TestDeleteWebAuthnCredential(t *testing.T) {
+	t.Run("Delete credential success", func(t *testing.T) {
+		userEmail := "test@gmail.com"
+
+		webAuthnService := services.NewWebAuthnServiceMock()
+		webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, testCookies)
+
+		webAuthnService.On("DeleteCredential", mock.Anything, userEmail, "1").Return(nil)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userEmail", userEmail)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Delete("/user/webauthn/credentials/:id", testMiddleware, webAuthnHandler.DeleteCredential)
+
+		req := httptest.NewRequest("DELETE", "/user/webauthn/credentials/1", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		webAuthnService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,115 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func logLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	return line
+}
+
+func TestRedact(t *testing.T) {
+	t.Run("Sensitive keys", func(t *testing.T) {
+		tests := []string{"password", "new_password", "client_secret", "access_token", "Authorization", "Cookie", "jwt", "otp_code"}
+
+		for _, key := range tests {
+			t.Run(key, func(t *testing.T) {
+				buf := &bytes.Buffer{}
+				logging.New(buf, slog.LevelInfo).Info("Test", key, "value-that-must-not-leak")
+
+				line := logLine(t, buf)
+				assert.Equal(t, "[REDACTED]", line[key])
+				assert.NotContains(t, buf.String(), "value-that-must-not-leak")
+			})
+		}
+	})
+
+	t.Run("Sensitive key inside a group", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging.New(buf, slog.LevelInfo).Info("Test", slog.Group("request", slog.String("token", "abc"), slog.String("method", "GET")))
+
+		request := logLine(t, buf)["request"].(map[string]any)
+		assert.Equal(t, "[REDACTED]", request["token"])
+		assert.Equal(t, "GET", request["method"])
+	})
+
+	t.Run("Mask emails in strings and errors", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging.New(buf, slog.LevelInfo).Info("Test",
+			"email", "john.doe@gmail.com",
+			"detail", "sent to a@b.co and jane@example.org",
+			"error", errors.New("User john.doe@gmail.com not found"),
+		)
+
+		line := logLine(t, buf)
+		assert.Equal(t, "j***@gmail.com", line["email"])
+		assert.Equal(t, "sent to a***@b.co and j***@example.org", line["detail"])
+		assert.Equal(t, "User j***@gmail.com not found", line["error"])
+		assert.NotContains(t, buf.String(), "john.doe")
+	})
+
+	t.Run("Leave other values alone", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging.New(buf, slog.LevelInfo).Info("Server running", "port", "8080", "status", 200, "path", "/api/user@me")
+
+		line := logLine(t, buf)
+		assert.Equal(t, "Server running", line["msg"])
+		assert.Equal(t, "INFO", line["level"])
+		assert.NotEmpty(t, line["time"])
+		assert.Equal(t, "8080", line["port"])
+		assert.Equal(t, float64(200), line["status"])
+		assert.Equal(t, "/api/user@me", line["path"])
+	})
+}
+
+func TestContextHandler(t *testing.T) {
+	t.Run("Add request and trace IDs from context", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+		ctx = logging.WithRequestID(ctx, "req-1")
+
+		logging.New(buf, slog.LevelInfo).With("component", "test").InfoContext(ctx, "Test")
+
+		line := logLine(t, buf)
+		assert.Equal(t, "req-1", line["request_id"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+		assert.Equal(t, "00f067aa0ba902b7", line["span_id"])
+		assert.Equal(t, "test", line["component"])
+	})
+
+	t.Run("No IDs without context", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		logging.New(buf, slog.LevelInfo).Info("Test")
+
+		line := logLine(t, buf)
+		assert.NotContains(t, line, "request_id")
+		assert.NotContains(t, line, "trace_id")
+	})
+
+	t.Run("Respect level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		logging.New(buf, slog.LevelWarn).Info("Test")
+
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMetricsDB(t *testing.T) (*gorm.DB, *metrics.Metrics) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&models.Users{}))
+
+	m := metrics.New()
+	require.NoError(t, db.Use(metrics.NewGormPlugin(m)))
+	return db, m
+}
+
+// NOTE - จำนวน observation ของ histogram db_query_duration_seconds ตาม label operation/table
+func queryCounts(t *testing.T, g prometheus.Gatherer) map[string]uint64 {
+	families, err := g.Gather()
+	require.NoError(t, err)
+
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "belugatasks_db_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			counts[labels["operation"]+" "+labels["table"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return counts
+}
+
+func TestGormPlugin(t *testing.T) {
+	ctx := context.Background()
+	db, m := newMetricsDB(t)
+
+	user := &models.Users{Email: "a@example.com", Name: "A", Password: "hash"}
+	require.NoError(t, db.WithContext(ctx).Create(user).Error)
+	require.NoError(t, db.WithContext(ctx).First(&models.Users{}, user.ID).Error)
+	require.NoError(t, db.WithContext(ctx).Model(user).Update("name", "B").Error)
+	require.NoError(t, db.WithContext(ctx).Exec("UPDATE users SET name = ?", "C").Error)
+	require.NoError(t, db.WithContext(ctx).Delete(user).Error)
+
+	assert.Equal(t, map[string]uint64{
+		"create users": 1,
+		"query users":  1,
+		"update users": 1,
+		"raw raw":      1,
+		"delete users": 1,
+	}, queryCounts(t, m.Gatherer()))
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "belugatasks"
+
+// NOTE - business event ที่ service นับ แยกเป็น interface ให้ test ใช้ mock ได้
+type RecorderInterface interface {
+	TaskCreated()
+	TaskCompleted()
+	LoginSucceeded()
+	LoginFailed(reason string)
+}
+
+// NOTE - ใช้ registry ของตัวเองแทน prometheus.DefaultRegisterer สร้างหลายตัวใน test ได้ไม่ชนกัน
+type Metrics struct {
+	registry        *prometheus.Registry
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	dbQueryDuration *prometheus.HistogramVec
+	tasksCreated    prometheus.Counter
+	tasksCompleted  prometheus.Counter
+	loginsSucceeded prometheus.Counter
+	loginsFailed    *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "GORM query latency by operation and table.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		tasksCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_created_total",
+			Help:      "Tasks created.",
+		}),
+		tasksCompleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_completed_total",
+			Help:      "Tasks marked as completed.",
+		}),
+		loginsSucceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_succeeded_total",
+			Help:      "Successful logins across all login methods.",
+		}),
+		loginsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_failed_total",
+			Help:      "Failed logins by reason.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.dbQueryDuration,
+		m.tasksCreated,
+		m.tasksCompleted,
+		m.loginsSucceeded,
+		m.loginsFailed,
+	)
+
+	return m
+}
+
+// NOTE - ให้ test อ่านค่าจาก registry ได้ตรงๆ โดยไม่ต้อง scrape ผ่าน HTTP
+func (m *Metrics) Gatherer() prometheus.Gatherer {
+	return m.registry
+}
+
+// NOTE - stats ของ connection pool (open/in use/idle/wait) อ่านจาก sql.DB.Stats() ตอน scrape
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// NOTE - นับ task ที่เลย due date แต่ยังไม่เสร็จตอน scrape แยกตาม priority
+func (m *Metrics) RegisterOverdueTasks(count func(ctx context.Context) (map[models.Priority]int64, error)) {
+	m.registry.MustRegister(&overdueCollector{count: count})
+}
+
+// NOTE - token ว่างคือเปิดให้ scrape ได้เลย (เช่นอยู่หลัง network ภายใน) ถ้าตั้งไว้ต้องส่ง Authorization: Bearer <token>
+func (m *Metrics) Handler(token string) fiber.Handler {
+	handler := adaptor.HTTPHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+
+	return func(c *fiber.Ctx) error {
+		if token != "" && subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte("Bearer "+token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Unauthorized"})
+		}
+		return handler(c)
+	}
+}
+
+func (m *Metrics) ObserveRequest(method string, route string, status string, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveQuery(operation string, table string, duration time.Duration) {
+	m.dbQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+func (m *Metrics) TaskCreated() {
+	m.tasksCreated.Inc()
+}
+
+func (m *Metrics) TaskCompleted() {
+	m.tasksCompleted.Inc()
+}
+
+func (m *Metrics) LoginSucceeded() {
+	m.loginsSucceeded.Inc()
+}
+
+func (m *Metrics) LoginFailed(reason string) {
+	m.loginsFailed.WithLabelValues(reason).Inc()
+}
+
+var overdueDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "tasks_overdue"),
+	"Incomplete tasks past their due date by priority.",
+	[]string{"priority"}, nil,
+)
+
+type overdueCollector struct {
+	count func(ctx context.Context) (map[models.Priority]int64, error)
+}
+
+func (c *overdueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- overdueDesc
+}
+
+func (c *overdueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		slog.Error("Failed to count overdue tasks", "error", err)
+		ch <- prometheus.NewInvalidMetric(overdueDesc, err)
+		return
+	}
+
+	// NOTE - priority ที่ไม่มี task ค้างก็ต้องส่ง 0 ไม่งั้น series หายไปจาก graph
+	for _, priority := range []models.Priority{models.Low, models.Medium, models.High} {
+		ch <- prometheus.MustNewConstMetric(overdueDesc, prometheus.GaugeValue, float64(counts[priority]), string(priority))
+	}
+}
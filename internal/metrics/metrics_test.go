@@ -0,0 +1,134 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessCounters(t *testing.T) {
+	m := metrics.New()
+
+	m.TaskCreated()
+	m.TaskCreated()
+	m.TaskCompleted()
+	m.LoginSucceeded()
+	m.LoginFailed("invalid_password")
+	m.LoginFailed("invalid_password")
+	m.LoginFailed("locked")
+
+	expected := `
+# HELP belugatasks_logins_failed_total Failed logins by reason.
+# TYPE belugatasks_logins_failed_total counter
+belugatasks_logins_failed_total{reason="invalid_password"} 2
+belugatasks_logins_failed_total{reason="locked"} 1
+# HELP belugatasks_logins_succeeded_total Successful logins across all login methods.
+# TYPE belugatasks_logins_succeeded_total counter
+belugatasks_logins_succeeded_total 1
+# HELP belugatasks_tasks_completed_total Tasks marked as completed.
+# TYPE belugatasks_tasks_completed_total counter
+belugatasks_tasks_completed_total 1
+# HELP belugatasks_tasks_created_total Tasks created.
+# TYPE belugatasks_tasks_created_total counter
+belugatasks_tasks_created_total 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Gatherer(), strings.NewReader(expected),
+		"belugatasks_logins_failed_total", "belugatasks_logins_succeeded_total", "belugatasks_tasks_completed_total", "belugatasks_tasks_created_total"))
+}
+
+func TestObserveRequest(t *testing.T) {
+	m := metrics.New()
+
+	m.ObserveRequest("GET", "/api/task/:id", "200", 20*time.Millisecond)
+	m.ObserveRequest("GET", "/api/task/:id", "200", 30*time.Millisecond)
+	m.ObserveRequest("POST", "/api/task", "400", time.Millisecond)
+
+	expected := `
+# HELP belugatasks_http_requests_total HTTP requests by method, route template and status.
+# TYPE belugatasks_http_requests_total counter
+belugatasks_http_requests_total{method="GET",route="/api/task/:id",status="200"} 2
+belugatasks_http_requests_total{method="POST",route="/api/task",status="400"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Gatherer(), strings.NewReader(expected), "belugatasks_http_requests_total"))
+	count, err := testutil.GatherAndCount(m.Gatherer(), "belugatasks_http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestOverdueTasks(t *testing.T) {
+	t.Run("Report every priority even without tasks", func(t *testing.T) {
+		m := metrics.New()
+		m.RegisterOverdueTasks(func(ctx context.Context) (map[models.Priority]int64, error) {
+			return map[models.Priority]int64{models.High: 3}, nil
+		})
+
+		expected := `
+# HELP belugatasks_tasks_overdue Incomplete tasks past their due date by priority.
+# TYPE belugatasks_tasks_overdue gauge
+belugatasks_tasks_overdue{priority="high"} 3
+belugatasks_tasks_overdue{priority="low"} 0
+belugatasks_tasks_overdue{priority="medium"} 0
+`
+		assert.NoError(t, testutil.GatherAndCompare(m.Gatherer(), strings.NewReader(expected), "belugatasks_tasks_overdue"))
+	})
+
+	t.Run("Count failure fails the scrape", func(t *testing.T) {
+		m := metrics.New()
+		m.RegisterOverdueTasks(func(ctx context.Context) (map[models.Priority]int64, error) {
+			return nil, errors.New("database is down")
+		})
+
+		_, err := m.Gatherer().Gather()
+
+		assert.ErrorContains(t, err, "database is down")
+	})
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		status        int
+	}{
+		{name: "Open without token", status: 200},
+		{name: "Valid bearer token", token: "scrape-secret", authorization: "Bearer scrape-secret", status: 200},
+		{name: "Missing bearer token", token: "scrape-secret", status: 401},
+		{name: "Wrong bearer token", token: "scrape-secret", authorization: "Bearer nope", status: 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.New()
+			m.TaskCreated()
+			app := fiber.New()
+			app.Get("/metrics", m.Handler(tt.token))
+
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			res, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.status == 200 {
+				raw, _ := io.ReadAll(res.Body)
+				body := string(raw)
+				assert.Contains(t, body, "belugatasks_tasks_created_total 1")
+				assert.Contains(t, body, "go_goroutines")
+			}
+		})
+	}
+}
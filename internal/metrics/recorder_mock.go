@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type RecorderMock struct {
+	mock.Mock
+}
+
+func NewRecorderMock() *RecorderMock {
+	return &RecorderMock{}
+}
+
+func (m *RecorderMock) TaskCreated() {
+	m.Called()
+}
+
+func (m *RecorderMock) TaskCompleted() {
+	m.Called()
+}
+
+func (m *RecorderMock) LoginSucceeded() {
+	m.Called()
+}
+
+func (m *RecorderMock) LoginFailed(reason string) {
+	m.Called(reason)
+}
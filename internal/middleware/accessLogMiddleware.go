@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - probe กับ Prometheus เรียกทุกไม่กี่วินาที ถ้าผ่านปกติ log แค่ระดับ debug ไม่ให้ท่วม log ตอน info
+var probePaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// NOTE - 1 บรรทัดต่อ request ใช้ route template เหมือน metrics ส่วน query string ไม่ log เพราะอาจมี token
+func NewAccessLogMiddleware(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		case probePaths[c.Path()]:
+			level = slog.LevelDebug
+		}
+		if !logger.Enabled(c.UserContext(), level) {
+			return err
+		}
+
+		attrs := []any{
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("route", routeTemplate(c, status)),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", c.IP()),
+			slog.String("user_agent", c.Get(fiber.HeaderUserAgent)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		logger.Log(c.UserContext(), level, "HTTP request", attrs...)
+		return err
+	}
+}
@@ -0,0 +1,127 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/logging"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setUpAccessLogApp(level slog.Level) (*fiber.App, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+
+	app := fiber.New()
+	app.Use(middleware.NewRequestIDMiddleware())
+	app.Use(middleware.NewAccessLogMiddleware(logging.New(buf, level)))
+	app.Get("/healthz", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/readyz", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusServiceUnavailable) })
+	app.Get("/metrics", func(c *fiber.Ctx) error { return c.SendString("") })
+	app.Get("/task/:id", func(c *fiber.Ctx) error { return c.SendString("task") })
+	app.Get("/bad", func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusBadRequest, "Invalid request") })
+	return app, buf
+}
+
+func accessLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestAccessLog(t *testing.T) {
+	t.Run("Log one line with route template", func(t *testing.T) {
+		app, buf := setUpAccessLogApp(slog.LevelInfo)
+		req := httptest.NewRequest("GET", "/task/42?token=secret", nil)
+		req.Header.Set("X-Request-ID", "req-1")
+		req.Header.Set("User-Agent", "test-agent")
+
+		_, err := app.Test(req)
+		require.NoError(t, err)
+
+		lines := accessLogLines(t, buf)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "HTTP request", lines[0]["msg"])
+		assert.Equal(t, "INFO", lines[0]["level"])
+		assert.Equal(t, "GET", lines[0]["method"])
+		assert.Equal(t, "/task/42", lines[0]["path"])
+		assert.Equal(t, "/task/:id", lines[0]["route"])
+		assert.Equal(t, float64(200), lines[0]["status"])
+		assert.Equal(t, "test-agent", lines[0]["user_agent"])
+		assert.Equal(t, "req-1", lines[0]["request_id"])
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("Client error is a warning", func(t *testing.T) {
+		app, buf := setUpAccessLogApp(slog.LevelInfo)
+
+		_, err := app.Test(httptest.NewRequest("GET", "/bad", nil))
+		require.NoError(t, err)
+
+		lines := accessLogLines(t, buf)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "WARN", lines[0]["level"])
+		assert.Equal(t, float64(400), lines[0]["status"])
+		assert.Equal(t, "Invalid request", lines[0]["error"])
+	})
+
+	t.Run("Unmatched route", func(t *testing.T) {
+		app, buf := setUpAccessLogApp(slog.LevelInfo)
+
+		_, err := app.Test(httptest.NewRequest("GET", "/unknown/123", nil))
+		require.NoError(t, err)
+
+		lines := accessLogLines(t, buf)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "unmatched", lines[0]["route"])
+		assert.Equal(t, float64(404), lines[0]["status"])
+	})
+
+	t.Run("Skip healthy probes at info", func(t *testing.T) {
+		app, buf := setUpAccessLogApp(slog.LevelInfo)
+
+		for _, path := range []string{"/healthz", "/metrics"} {
+			_, err := app.Test(httptest.NewRequest("GET", path, nil))
+			require.NoError(t, err)
+		}
+
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("Log healthy probes at debug", func(t *testing.T) {
+		app, buf := setUpAccessLogApp(slog.LevelDebug)
+
+		_, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+		require.NoError(t, err)
+
+		lines := accessLogLines(t, buf)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "DEBUG", lines[0]["level"])
+		assert.Equal(t, "/healthz", lines[0]["route"])
+	})
+
+	t.Run("Failing probe is still logged", func(t *testing.T) {
+		app, buf := setUpAccessLogApp(slog.LevelInfo)
+
+		_, err := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+		require.NoError(t, err)
+
+		lines := accessLogLines(t, buf)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "ERROR", lines[0]["level"])
+		assert.Equal(t, float64(503), lines[0]["status"])
+	})
+}
@@ -1,42 +1,104 @@
 package middleware
 
 import (
-	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
 	"github.com/Beluga-Whale/management-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-type JWTClaims struct {
-	Email string `json:"email"`
-	jwt.RegisteredClaims
-}
+func NewAuthMiddleware(userRepo repositories.UserRepositoryInterface, jwtUtil utils.JwtInterface, accessTokenService services.AccessTokenServiceInterface, sessionService services.SessionServiceInterface, cookies utils.CookieConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		bearer := bearerToken(c)
 
-func AuthMiddleware(c*fiber.Ctx) error {
-	jwtUtil := utils.NewJwt()
-
-	// NOTE - Get cookies 
-	tokenString :=  c.Cookies("jwt")    
-	
-	// NOTE - Check token it empty
-	if tokenString == ""{
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message":"Unauthorized",
-		})
-	}
-	
-	claims,err := jwtUtil.ParseJWT(tokenString)
+		// NOTE - script/CI ส่ง personal access token มาทาง Authorization header
+		if strings.HasPrefix(bearer, services.AccessTokenPrefix) {
+			user, accessToken, err := accessTokenService.Authenticate(c.UserContext(), bearer, c.IP())
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"message":"Invalid access token",
+				})
+			}
 
-	c.Locals("userEmail", claims)
+			if user.DisabledAt != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"message":"Account has been disabled",
+				})
+			}
 
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message":"Invalid token claims",
-		})
-	}
+			c.Locals("userEmail", user.Email)
+			c.Locals("userID", user.ID)
+			c.Locals("userRole", user.Role)
+			c.Locals("tokenScopes", strings.Fields(accessToken.Scopes))
+			return c.Next()
+		}
+
+		// NOTE - Get cookies
+		tokenString := cookies.AuthToken(c)
+		if tokenString == "" {
+			tokenString = bearer
+		}
+
+		// NOTE - Check token it empty
+		if tokenString == ""{
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message":"Unauthorized",
+			})
+		}
+
+		claims,err := jwtUtil.ParseJWTClaims(tokenString)
+
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message":"Invalid token claims",
+			})
+		}
+
+		// NOTE - token ที่ออกก่อนเปลี่ยน password ถือว่าถูก revoke แล้ว
+		user, err := userRepo.FindByEmail(c.UserContext(), claims.Email)
+		if err != nil || user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message":"Unauthorized",
+			})
+		}
 
-	fmt.Println(claims)
-	return c.Next()
+		if user.DisabledAt != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message":"Account has been disabled",
+			})
+		}
 
-}
\ No newline at end of file
+		if user.PasswordChangedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(user.PasswordChangedAt.Truncate(time.Second))) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message":"Token has been revoked",
+			})
+		}
+
+		// NOTE - token ที่ไม่มี sid ออกก่อนมีระบบ session จะหมดอายุเองภายใน JWTTTL
+		if claims.SessionID != "" {
+			if err := sessionService.Authenticate(c.UserContext(), claims.SessionID, user.ID, c.IP()); err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"message":"Session has been revoked",
+				})
+			}
+		}
+
+		c.Locals("userEmail", claims.Email)
+		c.Locals("userID", user.ID)
+		c.Locals("userRole", user.Role)
+		c.Locals("sessionID", claims.SessionID)
+
+		return c.Next()
+	}
+}
+
+func bearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - เช็คเฉพาะ request ที่ยืนยันตัวด้วย auth cookie เพราะ browser แนบ cookie ให้เองแม้มาจากเว็บอื่น
+// request ที่ไม่มี cookie หรือใช้ personal access token ผ่าน Authorization header ปลอมข้ามเว็บไม่ได้
+func NewCSRFMiddleware(cookies utils.CookieConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+			return c.Next()
+		}
+
+		if cookies.AuthToken(c) == "" || strings.HasPrefix(bearerToken(c), services.AccessTokenPrefix) {
+			return c.Next()
+		}
+
+		if !utils.ValidCSRFToken(c.Cookies(utils.CSRFCookieName), c.Get(utils.CSRFHeaderName)) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Invalid CSRF token",
+			})
+		}
+
+		return c.Next()
+	}
+}
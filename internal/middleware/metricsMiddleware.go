@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - label ด้วย route template (/api/task/:id) ไม่ใช่ path จริง ไม่งั้นจำนวน series โตตาม id
+func NewMetricsMiddleware(m *metrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// NOTE - c.Method() ชี้ไปที่ buffer ของ fasthttp ที่ถูกใช้ซ้ำกับ request ถัดไป ต้อง copy ก่อนเก็บเป็น label
+		status := responseStatus(c, err)
+		m.ObserveRequest(strings.Clone(c.Method()), routeTemplate(c, status), strconv.Itoa(status), time.Since(start))
+		return err
+	}
+}
+
+// NOTE - error ยังไม่ถูกแปลงเป็น status จนกว่าจะถึง ErrorHandler ของ app
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+func routeTemplate(c *fiber.Ctx, status int) string {
+	if status == fiber.StatusNotFound && c.Route().Path == "/" && c.Path() != "/" {
+		return "unmatched"
+	}
+	return c.Route().Path
+}
@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setUpMetricsApp() (*fiber.App, *metrics.Metrics) {
+	m := metrics.New()
+
+	app := fiber.New()
+	app.Use(middleware.NewMetricsMiddleware(m))
+	app.Get("/api/task/:id", func(c *fiber.Ctx) error { return c.SendString("task") })
+	app.Post("/api/task", func(c *fiber.Ctx) error { return c.Status(fiber.StatusCreated).SendString("created") })
+	app.Get("/bad", func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusBadRequest, "Invalid request") })
+	app.Get("/boom", func(c *fiber.Ctx) error { return errors.New("boom") })
+	return app, m
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	app, m := setUpMetricsApp()
+
+	for _, req := range []struct{ method, path string }{
+		{"GET", "/api/task/1"},
+		{"GET", "/api/task/2"},
+		{"POST", "/api/task"},
+		{"GET", "/bad"},
+		{"GET", "/boom"},
+		{"GET", "/unknown/123"},
+	} {
+		_, err := app.Test(httptest.NewRequest(req.method, req.path, nil))
+		require.NoError(t, err)
+	}
+
+	// NOTE - label เป็น route template ไม่ใช่ path จริง /api/task/1 กับ /api/task/2 ต้องรวมเป็น series เดียว
+	expected := `
+# HELP belugatasks_http_requests_total HTTP requests by method, route template and status.
+# TYPE belugatasks_http_requests_total counter
+belugatasks_http_requests_total{method="GET",route="/api/task/:id",status="200"} 2
+belugatasks_http_requests_total{method="GET",route="/bad",status="400"} 1
+belugatasks_http_requests_total{method="GET",route="/boom",status="500"} 1
+belugatasks_http_requests_total{method="GET",route="unmatched",status="404"} 1
+belugatasks_http_requests_total{method="POST",route="/api/task",status="201"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Gatherer(), strings.NewReader(expected), "belugatasks_http_requests_total"))
+	count, err := testutil.GatherAndCount(m.Gatherer(), "belugatasks_http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
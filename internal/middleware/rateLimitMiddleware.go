@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - นับแยกตาม group ถ้าผ่าน authMiddleware มาแล้วนับตาม user ไม่งั้นนับตาม IP
+func NewRateLimiter(store repositories.RateLimitRepositoryInterface, group string, limit utils.RateLimit) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := group + ":ip:" + c.IP()
+		if userID, ok := c.Locals("userID").(uint); ok {
+			key = group + ":user:" + strconv.FormatUint(uint64(userID), 10)
+		}
+
+		result, err := store.Take(c.UserContext(), key, limit)
+		if err != nil {
+			// NOTE - store ล่มไม่ควรทำให้ทั้ง API ใช้ไม่ได้ ปล่อยผ่านไปก่อน
+			slog.WarnContext(c.UserContext(), "Rate limiter unavailable", "error", err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Policy", limit.String())
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"message": "Too many requests",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,126 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(ctx context.Context, key string, limit utils.RateLimit) (utils.RateLimitResult, error) {
+	return utils.RateLimitResult{}, errors.New("connection refused")
+}
+
+func (failingRateLimitStore) DeleteIdle(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+// NOTE - แทน AuthMiddleware ใส่ userID จาก header ให้ (ไม่มี header = ยังไม่ login นับตาม IP)
+func setUpRateLimitApp(store repositories.RateLimitRepositoryInterface, group string, limit utils.RateLimit) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id := c.Get("X-Test-User"); id != "" {
+			userID, _ := strconv.Atoi(id)
+			c.Locals("userID", uint(userID))
+		}
+		return c.Next()
+	})
+	app.Use(middleware.NewRateLimiter(store, group, limit))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+type httptestResponse struct {
+	status int
+	header func(string) string
+	body   string
+}
+
+func rateLimitRequest(t *testing.T, app *fiber.App, userID string) *httptestResponse {
+	req := httptest.NewRequest("GET", "/", nil)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	return &httptestResponse{status: res.StatusCode, header: res.Header.Get, body: string(body)}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limit := utils.RateLimit{Requests: 2, Per: time.Minute}
+
+	t.Run("Set RateLimit headers", func(t *testing.T) {
+		app := setUpRateLimitApp(repositories.NewRateLimitMemoryRepository(), "api", limit)
+
+		res := rateLimitRequest(t, app, "")
+
+		assert.Equal(t, fiber.StatusOK, res.status)
+		assert.Equal(t, "2;w=60", res.header("RateLimit-Policy"))
+		assert.Equal(t, "2", res.header("RateLimit-Limit"))
+		assert.Equal(t, "1", res.header("RateLimit-Remaining"))
+		assert.Equal(t, "30", res.header("RateLimit-Reset"))
+		assert.Empty(t, res.header(fiber.HeaderRetryAfter))
+	})
+
+	t.Run("Too many requests", func(t *testing.T) {
+		app := setUpRateLimitApp(repositories.NewRateLimitMemoryRepository(), "api", limit)
+		rateLimitRequest(t, app, "")
+		rateLimitRequest(t, app, "")
+
+		res := rateLimitRequest(t, app, "")
+
+		assert.Equal(t, fiber.StatusTooManyRequests, res.status)
+		assert.Contains(t, res.body, "Too many requests")
+		assert.Equal(t, "0", res.header("RateLimit-Remaining"))
+		assert.Equal(t, "30", res.header(fiber.HeaderRetryAfter))
+		assert.Equal(t, "60", res.header("RateLimit-Reset"))
+	})
+
+	t.Run("Count per user once authenticated", func(t *testing.T) {
+		store := repositories.NewRateLimitMemoryRepository()
+		app := setUpRateLimitApp(store, "api", limit)
+		rateLimitRequest(t, app, "1")
+		rateLimitRequest(t, app, "1")
+
+		assert.Equal(t, fiber.StatusTooManyRequests, rateLimitRequest(t, app, "1").status)
+		assert.Equal(t, fiber.StatusOK, rateLimitRequest(t, app, "2").status)
+		// NOTE - user 1 กับ request ที่ไม่ได้ login จาก IP เดียวกันใช้คนละถัง
+		assert.Equal(t, fiber.StatusOK, rateLimitRequest(t, app, "").status)
+		// NOTE - group อื่นใช้ store เดียวกันแต่นับแยก
+		assert.Equal(t, fiber.StatusOK, rateLimitRequest(t, setUpRateLimitApp(store, "auth", limit), "1").status)
+	})
+
+	t.Run("Count per IP when anonymous", func(t *testing.T) {
+		app := setUpRateLimitApp(repositories.NewRateLimitMemoryRepository(), "auth", limit)
+		rateLimitRequest(t, app, "")
+		rateLimitRequest(t, app, "")
+
+		assert.Equal(t, fiber.StatusTooManyRequests, rateLimitRequest(t, app, "").status)
+		assert.Equal(t, fiber.StatusOK, rateLimitRequest(t, app, "1").status)
+	})
+
+	t.Run("Let requests through when store fails", func(t *testing.T) {
+		app := setUpRateLimitApp(failingRateLimitStore{}, "api", limit)
+
+		res := rateLimitRequest(t, app, "")
+
+		assert.Equal(t, fiber.StatusOK, res.status)
+		assert.Empty(t, res.header("RateLimit-Limit"))
+	})
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/Beluga-Whale/management-api/internal/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// NOTE - ใช้ X-Request-ID จาก proxy/client ถ้าส่งมา ไม่งั้นสร้างใหม่ แล้วส่งกลับใน response ให้ไล่ log ข้ามระบบได้
+func NewRequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(requestIDHeader, requestID)
+		c.Locals("requestID", requestID)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), requestID))
+		return c.Next()
+	}
+}
+
+// NOTE - ค่าจาก header ไปลง log ตรงๆ รับแค่ตัวอักษรธรรมดาและไม่ยาวเกินไป กัน log injection
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+	for _, r := range requestID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/logging"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NOTE - handler ตอบ request ID ที่เห็นใน Locals และใน ctx กลับมา จะได้เช็คว่าส่งต่อถึง service ด้วย
+func setUpRequestIDApp() *fiber.App {
+	app := fiber.New()
+	app.Use(middleware.NewRequestIDMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("requestID").(string) + "|" + logging.RequestID(c.UserContext()))
+	})
+	return app
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("Propagate request ID from client", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", "abc-123_DEF.4")
+
+		res, err := setUpRequestIDApp().Test(req)
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Equal(t, "abc-123_DEF.4", res.Header.Get("X-Request-ID"))
+		assert.Equal(t, "abc-123_DEF.4|abc-123_DEF.4", string(body))
+	})
+
+	t.Run("Generate request ID when missing", func(t *testing.T) {
+		res, err := setUpRequestIDApp().Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+
+		requestID := res.Header.Get("X-Request-ID")
+		_, parseErr := uuid.Parse(requestID)
+		assert.NoError(t, parseErr)
+		body, _ := io.ReadAll(res.Body)
+		assert.Equal(t, requestID+"|"+requestID, string(body))
+	})
+
+	t.Run("Replace invalid request ID", func(t *testing.T) {
+		tests := map[string]string{
+			"Too long":        strings.Repeat("a", 129),
+			"Log injection":   "abc\" level=ERROR",
+			"Unicode":         "ไอดี",
+			"Path separators": "../../etc",
+		}
+
+		for name, value := range tests {
+			t.Run(name, func(t *testing.T) {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("X-Request-ID", value)
+
+				res, err := setUpRequestIDApp().Test(req)
+				require.NoError(t, err)
+
+				requestID := res.Header.Get("X-Request-ID")
+				assert.NotEqual(t, value, requestID)
+				_, parseErr := uuid.Parse(requestID)
+				assert.NoError(t, parseErr)
+			})
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - ต้องใช้หลัง AuthMiddleware ที่ใส่ userRole ไว้ใน Locals
+func RequireRole(role models.Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if userRole, ok := c.Locals("userRole").(models.Role); ok && userRole == role {
+			return c.Next()
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Forbidden",
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - login ปกติ (cookie/JWT) ได้สิทธิ์เต็ม มีแค่ personal access token ที่ถูกจำกัดด้วย scope
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scopes, ok := c.Locals("tokenScopes").([]string)
+		if !ok {
+			return c.Next()
+		}
+
+		for _, s := range scopes {
+			if s == scope {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Token is missing required scope: " + scope,
+		})
+	}
+}
+
+// NOTE - route ที่จัดการ credential (password, 2FA, passkey, token) ห้ามใช้ personal access token
+func RequireSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("tokenScopes").([]string); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "This action requires an interactive login",
+			})
+		}
+		return c.Next()
+	}
+}
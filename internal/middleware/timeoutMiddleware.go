@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - c.UserContext() ของ Fiber เป็น context.Background() ไม่มีวันถูกยกเลิก
+// ตั้ง deadline ให้ และยกเลิกทันทีที่ handler ตอบกลับ query ที่ยังค้างของ request นี้จะถูก pgx สั่งหยุด
+// บังคับได้แค่ deadline client ตัดการเชื่อมต่อกลางทาง query ยังทำต่อจนเสร็จหรือหมดเวลา
+// เพราะ fasthttp ไม่มีสัญญาณปิด connection ราย request ส่วน c.Context().Done() ปิดตอน server shutdown
+// ถ้าเอามาผูกจะไปตัด request ที่ lifecycle กำลังรอ drain อยู่
+func NewTimeoutMiddleware(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout(t *testing.T) {
+	t.Run("Cancel slow work at the deadline", func(t *testing.T) {
+		var workErr error
+		app := fiber.New()
+		app.Use(middleware.NewTimeoutMiddleware(20 * time.Millisecond))
+		app.Get("/", func(c *fiber.Ctx) error {
+			select {
+			case <-c.UserContext().Done():
+				workErr = c.UserContext().Err()
+			case <-time.After(time.Second):
+			}
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		start := time.Now()
+		_, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, workErr, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Cancel context once the handler returns", func(t *testing.T) {
+		var requestCtx context.Context
+		app := fiber.New()
+		app.Use(middleware.NewTimeoutMiddleware(time.Minute))
+		app.Get("/", func(c *fiber.Ctx) error {
+			requestCtx = c.UserContext()
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, requestCtx.Err(), context.Canceled)
+	})
+}
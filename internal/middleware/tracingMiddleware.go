@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/Beluga-Whale/management-api/internal/tracing"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NOTE - เปิด server span ต่อ request ต่อจาก traceparent ที่ client ส่งมา (ถ้ามี)
+// แล้วเก็บ ctx ไว้ใน c.UserContext() ให้ handler ส่งต่อลงไปถึง service/repository
+func NewTracingMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), fiberCarrier{c})
+		// NOTE - span ถูก export หลัง request จบ ต้อง copy ค่าออกจาก buffer ของ fasthttp ก่อน
+		method := strings.Clone(c.Method())
+		ctx, span := tracing.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.URLPath(strings.Clone(c.Path()))),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		err := c.Next()
+
+		// NOTE - รู้ route template ก็ต่อเมื่อ router match แล้ว เลยตั้งชื่อ span ทีหลัง
+		status := responseStatus(c, err)
+		route := routeTemplate(c, status)
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+		return err
+	}
+}
+
+type fiberCarrier struct {
+	c *fiber.Ctx
+}
+
+func (fc fiberCarrier) Get(key string) string {
+	return fc.c.Get(key)
+}
+
+func (fc fiberCarrier) Set(key string, value string) {
+	fc.c.Request().Header.Set(key, value)
+}
+
+func (fc fiberCarrier) Keys() []string {
+	keys := make([]string, 0, len(fc.c.GetReqHeaders()))
+	for key := range fc.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,148 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NOTE - handler เปิด span ลูกจาก c.UserContext() เหมือน service จริง จะได้เช็คว่า ctx ส่งต่อลงไปถึง
+func setUpTracingApp(t *testing.T) (*fiber.App, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		provider.Shutdown(context.Background())
+	})
+
+	app := fiber.New()
+	app.Use(middleware.NewTracingMiddleware())
+	app.Get("/api/task/:id", func(c *fiber.Ctx) error {
+		_, span := tracing.Start(c.UserContext(), "TaskService.GetTaskByID")
+		span.End()
+		return c.SendString("task")
+	})
+	app.Post("/api/task", func(c *fiber.Ctx) error { return c.Status(fiber.StatusCreated).SendString("created") })
+	app.Get("/boom", func(c *fiber.Ctx) error { return errors.New("boom") })
+	return app, exporter
+}
+
+func spanByName(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "Span not found", "name %q", name)
+	return tracetest.SpanStub{}
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]string {
+	attrs := map[attribute.Key]string{}
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value.Emit()
+	}
+	return attrs
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	t.Run("Continue trace from traceparent", func(t *testing.T) {
+		app, exporter := setUpTracingApp(t)
+		req := httptest.NewRequest("GET", "/api/task/42", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		_, err := app.Test(req)
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		server := spanByName(t, spans, "GET /api/task/:id")
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+		assert.True(t, server.Parent.IsRemote())
+
+		attrs := spanAttributes(server)
+		assert.Equal(t, "GET", attrs["http.request.method"])
+		assert.Equal(t, "/api/task/:id", attrs["http.route"])
+		assert.Equal(t, "/api/task/42", attrs["url.path"])
+		assert.Equal(t, "200", attrs["http.response.status_code"])
+		assert.Equal(t, codes.Unset, server.Status.Code)
+
+		service := spanByName(t, spans, "TaskService.GetTaskByID")
+		assert.Equal(t, server.SpanContext.TraceID(), service.SpanContext.TraceID())
+		assert.Equal(t, server.SpanContext.SpanID(), service.Parent.SpanID())
+	})
+
+	t.Run("Start a new trace without traceparent", func(t *testing.T) {
+		app, exporter := setUpTracingApp(t)
+
+		_, err := app.Test(httptest.NewRequest("POST", "/api/task", nil))
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "POST /api/task", spans[0].Name)
+		assert.True(t, spans[0].SpanContext.TraceID().IsValid())
+		assert.False(t, spans[0].Parent.IsValid())
+		assert.Equal(t, "201", spanAttributes(spans[0])["http.response.status_code"])
+	})
+
+	t.Run("Server error marks the span", func(t *testing.T) {
+		app, exporter := setUpTracingApp(t)
+
+		_, err := app.Test(httptest.NewRequest("GET", "/boom", nil))
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "500", spanAttributes(spans[0])["http.response.status_code"])
+		require.Len(t, spans[0].Events, 1)
+		assert.Equal(t, "exception", spans[0].Events[0].Name)
+	})
+
+	t.Run("Unmatched route", func(t *testing.T) {
+		app, exporter := setUpTracingApp(t)
+
+		_, err := app.Test(httptest.NewRequest("GET", "/unknown/123", nil))
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET unmatched", spans[0].Name)
+		assert.Equal(t, "404", spanAttributes(spans[0])["http.response.status_code"])
+	})
+
+	t.Run("Attributes survive the next request", func(t *testing.T) {
+		app, exporter := setUpTracingApp(t)
+
+		for _, req := range []struct{ method, path string }{{"POST", "/api/task"}, {"GET", "/api/task/42"}} {
+			_, err := app.Test(httptest.NewRequest(req.method, req.path, nil))
+			require.NoError(t, err)
+		}
+
+		attrs := spanAttributes(spanByName(t, exporter.GetSpans(), "POST /api/task"))
+		assert.Equal(t, "POST", attrs["http.request.method"])
+		assert.Equal(t, "/api/task", attrs["url.path"])
+	})
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AccessTokens struct {
+	gorm.Model
+	UserID      uint       `gorm:"index;not null" json:"user_id"` //NOTE - FK
+	Name        string     `gorm:"not null" json:"name"`
+	TokenPrefix string     `gorm:"not null" json:"token_prefix"`
+	TokenHash   string     `gorm:"uniqueIndex;not null" json:"-"`
+	Scopes      string     `gorm:"not null" json:"scopes"` //NOTE - คั่นด้วย space เช่น "tasks:read tasks:write"
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	AuditLogin          = "login"
+	AuditLoginFailed    = "login_failed"
+	AuditLogout         = "logout"
+	AuditPasswordChange = "password_change"
+	AuditPasswordReset  = "password_reset"
+	AuditRoleChange     = "role_change"
+	AuditTokenCreate    = "token_create"
+	AuditAdminExport    = "admin_audit_export"
+	AuditUserCreate     = "user_create"
+	AuditUserDisable    = "user_disable"
+)
+
+// NOTE - ข้อมูลเพิ่มเติมของแต่ละ event เก็บเป็น JSON
+type AuditMetadata map[string]string
+
+func (m AuditMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+func (m *AuditMetadata) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*m = nil
+		return nil
+	default:
+		return errors.New("Invalid audit metadata")
+	}
+	return json.Unmarshal(b, m)
+}
+
+// NOTE - append-only ไม่มี UpdatedAt/DeletedAt และ DB มี trigger กันการแก้/ลบ
+type AuditLogs struct {
+	ID         uint          `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time     `gorm:"index;not null" json:"created_at"`
+	Action     string        `gorm:"index;not null" json:"action"`
+	ActorID    *uint         `gorm:"index" json:"actor_id"` //NOTE - nil ถ้าไม่รู้ว่าใคร เช่น login ด้วย email ที่ไม่มีในระบบ
+	ActorEmail string        `gorm:"index" json:"actor_email"`
+	TargetID   *uint         `json:"target_id,omitempty"`
+	IP         string        `json:"ip"`
+	UserAgent  string        `json:"user_agent"`
+	Metadata   AuditMetadata `gorm:"type:text" json:"metadata,omitempty"`
+}
+
+func (AuditLogs) TableName() string {
+	return "audit_log"
+}
+
+// NOTE - field ที่เป็น zero value จะไม่ถูกใช้ filter
+type AuditLogFilter struct {
+	Action     string
+	ActorID    *uint
+	ActorEmail string
+	IP         string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// NOTE - ไม่ใช้ gorm.Model เพราะ soft delete จะทำให้ key ซ้ำตอน insert ใหม่หลัง reset
+type LoginAttempts struct {
+	ID           uint   `gorm:"primarykey"`
+	AttemptKey   string `gorm:"uniqueIndex;not null"` //NOTE - "account:<email>" หรือ "ip:<ip>"
+	Failures     int    `gorm:"not null;default:0"`
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NOTE - ตัว link เป็น JWT ที่เซ็นแล้ว ตารางนี้เก็บแค่ jti ไว้กันใช้ซ้ำ
+type MagicLinks struct {
+	gorm.Model
+	UserID    uint   `gorm:"index;not null"` //NOTE - FK
+	LinkID    string `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+// NOTE - ทุก model ที่ต้อง AutoMigrate ใช้ร่วมกันทั้งตอน migrate และตอนเช็ค readiness ว่ามีตารางครบ
+func All() []interface{} {
+	return []interface{}{
+		&Users{},
+		&Tasks{},
+		&PasswordResets{},
+		&RecoveryCodes{},
+		&WebAuthnCredentials{},
+		&WebAuthnSessions{},
+		&AccessTokens{},
+		&LoginAttempts{},
+		&RateLimitBuckets{},
+		&OAuthIdentities{},
+		&OIDCLoginStates{},
+		&MagicLinks{},
+		&Sessions{},
+		&AuditLogs{},
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NOTE - บัญชีจาก OIDC provider ที่ผูกกับ user (1 user ผูกได้หลาย provider)
+type OAuthIdentities struct {
+	gorm.Model
+	UserID   uint   `gorm:"index;not null" json:"user_id"` //NOTE - FK
+	Provider string `gorm:"uniqueIndex:idx_oauth_provider_subject;not null" json:"provider"`
+	Subject  string `gorm:"uniqueIndex:idx_oauth_provider_subject;not null" json:"-"`
+	Email    string `json:"email"`
+}
+
+// NOTE - เก็บ state/nonce/PKCE verifier ระหว่าง redirect ไป provider ใช้ได้ครั้งเดียว
+type OIDCLoginStates struct {
+	gorm.Model
+	State        string `gorm:"uniqueIndex;not null"`
+	Provider     string `gorm:"not null"`
+	Nonce        string `gorm:"not null"`
+	CodeVerifier string `gorm:"not null"`
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResets struct {
+	gorm.Model
+	UserID    uint   `gorm:"index;not null"` //NOTE - FK
+	TokenHash string `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
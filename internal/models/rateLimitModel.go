@@ -0,0 +1,11 @@
+package models
+
+import (
+	"time"
+)
+
+type RateLimitBuckets struct {
+	BucketKey  string `gorm:"primaryKey"` //NOTE - "<group>:user:<id>" หรือ "<group>:ip:<ip>"
+	Tokens     float64
+	RefilledAt time.Time `gorm:"index"`
+}
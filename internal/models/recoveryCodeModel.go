@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodes struct {
+	gorm.Model
+	UserID   uint   `gorm:"index;not null"` //NOTE - FK
+	CodeHash string `gorm:"not null"`
+	UsedAt   *time.Time
+}
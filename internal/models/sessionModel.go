@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NOTE - 1 แถวต่อการ login 1 ครั้ง SessionID อยู่ใน claim "sid" ของ JWT
+type Sessions struct {
+	gorm.Model
+	UserID     uint       `gorm:"index;not null" json:"user_id"` //NOTE - FK
+	SessionID  string     `gorm:"uniqueIndex;not null" json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `gorm:"-" json:"current"` //NOTE - session ของ request นี้ ไม่ได้เก็บลง DB
+}
@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 )
 
+// NOTE - เหมือน Role ค่าที่ใช้ได้อยู่ใน check constraint ของ Tasks.Status/Tasks.Priority ด้วย
 type Status string
 
 const (
@@ -26,8 +27,8 @@ type Tasks struct {
 	DueDate time.Time
 	Title string
 	Description string
-	Status  Status `gorm:"type:task_status;not null; default:'active'"`
+	Status  Status `gorm:"size:16;not null;default:'active';check:chk_tasks_status,status IN ('active', 'inactive')"`
 	Completed bool
-	Priority Priority `gorm:"type:task_priority;not null;default:'low'"`
+	Priority Priority `gorm:"size:16;not null;default:'low';check:chk_tasks_priority,priority IN ('low', 'medium', 'high')"`
 	UserID uint //NOTE - FK
 }
\ No newline at end of file
@@ -1,7 +1,13 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// NOTE - ค่าที่ใช้ได้บังคับด้วย check constraint ใน tag ของ Users.Role (ไม่ใช้ ENUM ของ Postgres เพราะ SQLite ไม่มี)
+// เพิ่มค่าใหม่ต้องแก้ทั้งสองที่
 type Role string
 
 const (
@@ -13,10 +19,15 @@ type Users struct {
 	gorm.Model
 	Email string `gorm:"unique"`
 	Name string  `gorm:"not null"`
-	Password string `gorm: "unique;not null"`
+	Password string `gorm:"not null"`
 	Photo string `gorm:"default:'https://images.unsplash.com/photo-1438761681033-6461ffad8d80?q=80&w=2070&auto=format&fit=crop&ixlib=rb-4.0.3&ixid=M3wxMjA3fDB8MHxwaG90by1wYWdlfHx8fGVufDB8fHx8fA%3D%3D'"`
 	Bio string 
-	Role  Role `gorm:"type:user_role;not null;default:'user'"`
+	Role  Role `gorm:"size:16;not null;default:'user';check:chk_users_role,role IN ('admin', 'user')"`
 	isVerified bool 
+	PasswordChangedAt *time.Time `json:"-"` // NOTE - token ที่ออกก่อนเวลานี้จะใช้ไม่ได้
+	TwoFactorEnabled bool
+	TwoFactorSecret string `json:"-"` // NOTE - base32 secret ของ TOTP
+	TwoFactorLastStep int64 `json:"-"` // NOTE - time step ของ TOTP code ล่าสุดที่ใช้ผ่าน code เดิมหรือเก่ากว่าใช้ซ้ำไม่ได้
+	DisabledAt *time.Time `json:"-"` // NOTE - ถูกปิดบัญชีโดย admin login ไม่ได้และ token เดิมใช้ไม่ได้
 	Tasks []Tasks `gorm:"foreignKey:UserID"`
 }
\ No newline at end of file
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebAuthnCredentials struct {
+	gorm.Model
+	UserID          uint   `gorm:"index;not null"` //NOTE - FK
+	Name            string `gorm:"not null"`
+	CredentialID    []byte `gorm:"uniqueIndex;not null" json:"-"`
+	PublicKey       []byte `gorm:"not null" json:"-"`
+	AttestationType string
+	Transports      string // NOTE - เก็บเป็น comma separated เช่น "usb,nfc"
+	AAGUID          []byte `json:"-"`
+	SignCount       uint32
+	CloneWarning    bool
+	BackupEligible  bool
+	BackupState     bool
+	LastUsedAt      *time.Time
+}
+
+// NOTE - เก็บ challenge ระหว่าง begin กับ finish ของ ceremony ใช้ได้ครั้งเดียว
+type WebAuthnSessions struct {
+	gorm.Model
+	SessionKey string `gorm:"uniqueIndex;not null"`
+	UserID     uint   // NOTE - 0 คือ discoverable login ที่ยังไม่รู้ว่าใคร
+	Ceremony   string `gorm:"not null"`
+	Data       string `gorm:"not null"`
+	ExpiresAt  time.Time
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepositoryInterface interface {
+	CreateAccessToken(ctx context.Context, token *models.AccessTokens) error
+	FindAccessTokensByUserId(ctx context.Context, userID uint) ([]models.AccessTokens, error)
+	FindAccessTokenById(ctx context.Context, idStr string) (*models.AccessTokens, error)
+	FindActiveByTokenHash(ctx context.Context, tokenHash string) (*models.AccessTokens, error)
+	UpdateLastUsed(ctx context.Context, id uint, ip string) error
+	RevokeAccessToken(ctx context.Context, id uint) error
+	RevokeAccessTokensByUserId(ctx context.Context, userID uint) error
+}
+
+type AccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+func (repo *AccessTokenRepository) CreateAccessToken(ctx context.Context, token *models.AccessTokens) error {
+	return repo.db.WithContext(ctx).Create(token).Error
+}
+
+func (repo *AccessTokenRepository) FindAccessTokensByUserId(ctx context.Context, userID uint) ([]models.AccessTokens, error) {
+	var tokens []models.AccessTokens
+
+	if err := repo.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (repo *AccessTokenRepository) FindAccessTokenById(ctx context.Context, idStr string) (*models.AccessTokens, error) {
+	var token models.AccessTokens
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return nil, errors.New("Invalid Token ID fomat")
+	}
+
+	if err := repo.db.WithContext(ctx).First(&token, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// NOTE - คืน nil ถ้าไม่เจอ, ถูก revoke หรือหมดอายุแล้ว
+func (repo *AccessTokenRepository) FindActiveByTokenHash(ctx context.Context, tokenHash string) (*models.AccessTokens, error) {
+	var token models.AccessTokens
+
+	err := repo.db.WithContext(ctx).Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", tokenHash, time.Now()).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find access token: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (repo *AccessTokenRepository) UpdateLastUsed(ctx context.Context, id uint, ip string) error {
+	err := repo.db.WithContext(ctx).Model(&models.AccessTokens{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"last_used_ip": ip,
+	}).Error
+
+	if err != nil {
+		return fmt.Errorf("Failed to update access token: %w", err)
+	}
+	return nil
+}
+
+func (repo *AccessTokenRepository) RevokeAccessToken(ctx context.Context, id uint) error {
+	return repo.db.WithContext(ctx).Model(&models.AccessTokens{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now()).Error
+}
+
+// NOTE - ใช้ตอนเปลี่ยน/reset password revoke ทุก token ของ user ที่ยังใช้ได้
+func (repo *AccessTokenRepository) RevokeAccessTokensByUserId(ctx context.Context, userID uint) error {
+	return repo.db.WithContext(ctx).Model(&models.AccessTokens{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", time.Now()).Error
+}
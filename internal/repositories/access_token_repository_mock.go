@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type AccessTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func NewAccessTokenRepositoryMock() *AccessTokenRepositoryMock {
+	return &AccessTokenRepositoryMock{}
+}
+
+func (m *AccessTokenRepositoryMock) CreateAccessToken(ctx context.Context, token *models.AccessTokens) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *AccessTokenRepositoryMock) FindAccessTokensByUserId(ctx context.Context, userID uint) ([]models.AccessTokens, error) {
+	args := m.Called(ctx, userID)
+	if tokens, ok := args.Get(0).([]models.AccessTokens); ok {
+		return tokens, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenRepositoryMock) FindAccessTokenById(ctx context.Context, idStr string) (*models.AccessTokens, error) {
+	args := m.Called(ctx, idStr)
+	if token, ok := args.Get(0).(*models.AccessTokens); ok {
+		return token, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenRepositoryMock) FindActiveByTokenHash(ctx context.Context, tokenHash string) (*models.AccessTokens, error) {
+	args := m.Called(ctx, tokenHash)
+	if token, ok := args.Get(0).(*models.AccessTokens); ok {
+		return token, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenRepositoryMock) UpdateLastUsed(ctx context.Context, id uint, ip string) error {
+	args := m.Called(ctx, id, ip)
+	return args.Error(0)
+}
+
+func (m *AccessTokenRepositoryMock) RevokeAccessToken(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *AccessTokenRepositoryMock) RevokeAccessTokensByUserId(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
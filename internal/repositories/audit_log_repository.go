@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepositoryInterface interface {
+	CreateAuditLog(ctx context.Context, log *models.AuditLogs) error
+	FindAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLogs, error)
+	EachAuditLog(ctx context.Context, filter models.AuditLogFilter, fn func(log *models.AuditLogs) error) error
+}
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (repo *AuditLogRepository) CreateAuditLog(ctx context.Context, log *models.AuditLogs) error {
+	return repo.db.WithContext(ctx).Create(log).Error
+}
+
+func (repo *AuditLogRepository) query(ctx context.Context, filter models.AuditLogFilter) *gorm.DB {
+	query := repo.db.WithContext(ctx).Model(&models.AuditLogs{})
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.ActorEmail != "" {
+		query = query.Where("actor_email = ?", filter.ActorEmail)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	return query.Order("created_at desc, id desc")
+}
+
+func (repo *AuditLogRepository) FindAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLogs, error) {
+	var logs []models.AuditLogs
+
+	if err := repo.query(ctx, filter).Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("Failed to find audit logs: %w", err)
+	}
+	return logs, nil
+}
+
+// NOTE - อ่านทีละแถว ใช้ตอน export จะได้ไม่ต้องโหลดทั้งตารางเข้า memory
+func (repo *AuditLogRepository) EachAuditLog(ctx context.Context, filter models.AuditLogFilter, fn func(log *models.AuditLogs) error) error {
+	rows, err := repo.query(ctx, filter).Rows()
+	if err != nil {
+		return fmt.Errorf("Failed to find audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log models.AuditLogs
+		if err := repo.db.WithContext(ctx).ScanRows(rows, &log); err != nil {
+			return fmt.Errorf("Failed to read audit log: %w", err)
+		}
+		if err := fn(&log); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditLogRepositoryMock struct {
+	mock.Mock
+}
+
+func NewAuditLogRepositoryMock() *AuditLogRepositoryMock {
+	return &AuditLogRepositoryMock{}
+}
+
+func (m *AuditLogRepositoryMock) CreateAuditLog(ctx context.Context, log *models.AuditLogs) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *AuditLogRepositoryMock) FindAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLogs, error) {
+	args := m.Called(ctx, filter)
+	if logs, ok := args.Get(0).([]models.AuditLogs); ok {
+		return logs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// NOTE - ส่ง log ที่ Return ไว้ให้ fn ทีละตัวเหมือนของจริง
+func (m *AuditLogRepositoryMock) EachAuditLog(ctx context.Context, filter models.AuditLogFilter, fn func(log *models.AuditLogs) error) error {
+	args := m.Called(ctx, filter, fn)
+	if logs, ok := args.Get(0).([]models.AuditLogs); ok {
+		for i := range logs {
+			if err := fn(&logs[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
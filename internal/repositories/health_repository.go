@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type HealthRepositoryInterface interface {
+	Ping(ctx context.Context) error
+	PendingMigrations(ctx context.Context) ([]string, error)
+}
+
+type HealthRepository struct {
+	db *gorm.DB
+}
+
+func NewHealthRepository(db *gorm.DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+// NOTE - probe ถูกเรียกทุกไม่กี่วินาที ไม่ log SQL ของมันให้รก
+func (r *HealthRepository) quiet(ctx context.Context) *gorm.DB {
+	return r.db.Session(&gorm.Session{Context: ctx, Logger: logger.Discard})
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// NOTE - ตารางของ model ที่ยังไม่มีใน DB แปลว่า migrate ยังไม่เสร็จหรือต่อผิด database
+func (r *HealthRepository) PendingMigrations(ctx context.Context) ([]string, error) {
+	db := r.quiet(ctx)
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, table := range tables {
+		existing[table] = true
+	}
+
+	var pending []string
+	for _, model := range models.All() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		if !existing[stmt.Table] {
+			pending = append(pending, stmt.Table)
+		}
+	}
+
+	return pending, nil
+}
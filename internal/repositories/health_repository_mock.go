@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type HealthRepositoryMock struct {
+	mock.Mock
+}
+
+func NewHealthRepositoryMock() *HealthRepositoryMock {
+	return &HealthRepositoryMock{}
+}
+
+func (m *HealthRepositoryMock) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *HealthRepositoryMock) PendingMigrations(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if pending, ok := args.Get(0).([]string); ok {
+		return pending, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepositoryInterface interface {
+	FindByKeys(ctx context.Context, keys []string) ([]models.LoginAttempts, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempts, error)
+	LockUntil(ctx context.Context, key string, until time.Time) error
+	DeleteByKey(ctx context.Context, key string) error
+}
+
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (repo *LoginAttemptRepository) FindByKeys(ctx context.Context, keys []string) ([]models.LoginAttempts, error) {
+	var attempts []models.LoginAttempts
+
+	if err := repo.db.WithContext(ctx).Where("attempt_key IN ?", keys).Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("Failed to find login attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// NOTE - upsert ใน query เดียว หลาย replica นับพร้อมกันได้ไม่หาย
+// ถ้าครั้งล่าสุดที่ผิดเก่ากว่า window จะเริ่มนับใหม่จาก 1
+func (repo *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempts, error) {
+	now := time.Now()
+	attempt := models.LoginAttempts{AttemptKey: key, Failures: 1, LastFailedAt: now}
+
+	err := repo.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "attempt_key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":       gorm.Expr("CASE WHEN login_attempts.last_failed_at < ? THEN 1 ELSE login_attempts.failures + 1 END", now.Add(-window)),
+				"last_failed_at": now,
+				"updated_at":     now,
+			}),
+		},
+		clause.Returning{},
+	).Create(&attempt).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to record login attempt: %w", err)
+	}
+	return &attempt, nil
+}
+
+func (repo *LoginAttemptRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	return repo.db.WithContext(ctx).Model(&models.LoginAttempts{}).Where("attempt_key = ?", key).Update("locked_until", until).Error
+}
+
+func (repo *LoginAttemptRepository) DeleteByKey(ctx context.Context, key string) error {
+	return repo.db.WithContext(ctx).Where("attempt_key = ?", key).Delete(&models.LoginAttempts{}).Error
+}
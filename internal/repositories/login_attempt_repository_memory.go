@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+)
+
+// NOTE - เก็บใน memory ของ process เดียว ใช้ตอน test หรือรันเครื่องเดียว ถ้ามีหลาย replica ต้องใช้ตัว DB
+type LoginAttemptMemoryRepository struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempts
+}
+
+func NewLoginAttemptMemoryRepository() *LoginAttemptMemoryRepository {
+	return &LoginAttemptMemoryRepository{attempts: map[string]models.LoginAttempts{}}
+}
+
+func (repo *LoginAttemptMemoryRepository) FindByKeys(ctx context.Context, keys []string) ([]models.LoginAttempts, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var attempts []models.LoginAttempts
+	for _, key := range keys {
+		if attempt, ok := repo.attempts[key]; ok {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (repo *LoginAttemptMemoryRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempts, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	attempt, ok := repo.attempts[key]
+	if !ok || attempt.LastFailedAt.Before(now.Add(-window)) {
+		attempt.Failures = 0
+	}
+
+	attempt.AttemptKey = key
+	attempt.Failures++
+	attempt.LastFailedAt = now
+	attempt.UpdatedAt = now
+	repo.attempts[key] = attempt
+
+	return &attempt, nil
+}
+
+func (repo *LoginAttemptMemoryRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if attempt, ok := repo.attempts[key]; ok {
+		attempt.LockedUntil = &until
+		repo.attempts[key] = attempt
+	}
+	return nil
+}
+
+func (repo *LoginAttemptMemoryRepository) DeleteByKey(ctx context.Context, key string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.attempts, key)
+	return nil
+}
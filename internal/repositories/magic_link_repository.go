@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type MagicLinkRepositoryInterface interface {
+	CreateMagicLink(ctx context.Context, link *models.MagicLinks) error
+	MarkUsed(ctx context.Context, linkID string) (bool, error)
+}
+
+type MagicLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkRepository(db *gorm.DB) *MagicLinkRepository {
+	return &MagicLinkRepository{db: db}
+}
+
+func (repo *MagicLinkRepository) CreateMagicLink(ctx context.Context, link *models.MagicLinks) error {
+	return repo.db.WithContext(ctx).Create(link).Error
+}
+
+// NOTE - ใช้ได้ครั้งเดียว ถ้าใช้ไปแล้ว หมดอายุ หรือไม่มี link นี้จะได้ false
+func (repo *MagicLinkRepository) MarkUsed(ctx context.Context, linkID string) (bool, error) {
+	result := repo.db.WithContext(ctx).Model(&models.MagicLinks{}).
+		Where("link_id = ? AND used_at IS NULL AND expires_at > ?", linkID, time.Now()).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, fmt.Errorf("Failed to use magic link: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MagicLinkRepositoryMock struct {
+	mock.Mock
+}
+
+func NewMagicLinkRepositoryMock() *MagicLinkRepositoryMock {
+	return &MagicLinkRepositoryMock{}
+}
+
+func (m *MagicLinkRepositoryMock) CreateMagicLink(ctx context.Context, link *models.MagicLinks) error {
+	args := m.Called(ctx, link)
+	return args.Error(0)
+}
+
+func (m *MagicLinkRepositoryMock) MarkUsed(ctx context.Context, linkID string) (bool, error) {
+	args := m.Called(ctx, linkID)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,57 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/repositories/repositorytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskMemoryRepository(t *testing.T) {
+	repositorytest.RunTaskRepositoryContract(t, func(t *testing.T) (repositories.TaskRepositoryInterface, repositories.UserRepositoryInterface) {
+		return repositories.NewTaskMemoryRepository(), repositories.NewUserMemoryRepository()
+	})
+}
+
+func TestUserMemoryRepository(t *testing.T) {
+	repositorytest.RunUserRepositoryContract(t, func(t *testing.T) repositories.UserRepositoryInterface {
+		return repositories.NewUserMemoryRepository()
+	})
+}
+
+func TestRateLimitMemoryRepository(t *testing.T) {
+	repositorytest.RunRateLimitRepositoryContract(t, func(t *testing.T) repositories.RateLimitRepositoryInterface {
+		return repositories.NewRateLimitMemoryRepository()
+	})
+}
+
+func TestMemoryRepositoryConcurrentCreate(t *testing.T) {
+	tasks := repositories.NewTaskMemoryRepository()
+	users := repositories.NewUserMemoryRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, users.CreateUser(context.Background(), &models.Users{Email: fmt.Sprintf("user%d@example.com", i)}))
+			assert.NoError(t, tasks.CreateTask(context.Background(), &models.Tasks{Title: "Title", UserID: 1}))
+		}(i)
+	}
+	wg.Wait()
+
+	all, err := tasks.FindTaskAll(context.Background(), 1, "")
+	assert.NoError(t, err)
+	assert.Len(t, all, 50)
+
+	ids := map[uint]bool{}
+	for _, task := range all {
+		ids[task.ID] = true
+	}
+	assert.Len(t, ids, 50)
+}
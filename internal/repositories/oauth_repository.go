@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type OAuthRepositoryInterface interface {
+	FindIdentity(ctx context.Context, provider string, subject string) (*models.OAuthIdentities, error)
+	CreateIdentity(ctx context.Context, identity *models.OAuthIdentities) error
+	CreateLoginState(ctx context.Context, state *models.OIDCLoginStates) error
+	ConsumeLoginState(ctx context.Context, state string) (*models.OIDCLoginStates, error)
+}
+
+type OAuthRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthRepository(db *gorm.DB) *OAuthRepository {
+	return &OAuthRepository{db: db}
+}
+
+func (repo *OAuthRepository) FindIdentity(ctx context.Context, provider string, subject string) (*models.OAuthIdentities, error) {
+	var identity models.OAuthIdentities
+
+	result := repo.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	return &identity, nil
+}
+
+func (repo *OAuthRepository) CreateIdentity(ctx context.Context, identity *models.OAuthIdentities) error {
+	return repo.db.WithContext(ctx).Create(identity).Error
+}
+
+func (repo *OAuthRepository) CreateLoginState(ctx context.Context, state *models.OIDCLoginStates) error {
+	return repo.db.WithContext(ctx).Create(state).Error
+}
+
+// NOTE - ดึงแล้วลบทิ้งเลย callback เดิมใช้ซ้ำไม่ได้
+func (repo *OAuthRepository) ConsumeLoginState(ctx context.Context, state string) (*models.OIDCLoginStates, error) {
+	var loginState models.OIDCLoginStates
+
+	result := repo.db.WithContext(ctx).Where("state = ? AND expires_at > ?", state, time.Now()).First(&loginState)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	deleted := repo.db.WithContext(ctx).Unscoped().Where("id = ?", loginState.ID).Delete(&models.OIDCLoginStates{})
+	if deleted.Error != nil {
+		return nil, deleted.Error
+	}
+
+	if deleted.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &loginState, nil
+}
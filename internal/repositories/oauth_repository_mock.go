@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type OAuthRepositoryMock struct {
+	mock.Mock
+}
+
+func NewOAuthRepositoryMock() *OAuthRepositoryMock {
+	return &OAuthRepositoryMock{}
+}
+
+func (m *OAuthRepositoryMock) FindIdentity(ctx context.Context, provider string, subject string) (*models.OAuthIdentities, error) {
+	args := m.Called(ctx, provider, subject)
+	if identity, ok := args.Get(0).(*models.OAuthIdentities); ok {
+		return identity, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OAuthRepositoryMock) CreateIdentity(ctx context.Context, identity *models.OAuthIdentities) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *OAuthRepositoryMock) CreateLoginState(ctx context.Context, state *models.OIDCLoginStates) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func (m *OAuthRepositoryMock) ConsumeLoginState(ctx context.Context, state string) (*models.OIDCLoginStates, error) {
+	args := m.Called(ctx, state)
+	if loginState, ok := args.Get(0).(*models.OIDCLoginStates); ok {
+		return loginState, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
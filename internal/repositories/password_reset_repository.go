@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepositoryInterface interface {
+	CreatePasswordReset(ctx context.Context, reset *models.PasswordResets) error
+	FindValidByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResets, error)
+	MarkUsed(ctx context.Context, id uint) (bool, error)
+	InvalidateByUserId(ctx context.Context, userID uint) error
+}
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (repo *PasswordResetRepository) CreatePasswordReset(ctx context.Context, reset *models.PasswordResets) error {
+	return repo.db.WithContext(ctx).Create(reset).Error
+}
+
+func (repo *PasswordResetRepository) FindValidByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResets, error) {
+	var reset models.PasswordResets
+
+	result := repo.db.WithContext(ctx).Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).First(&reset)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	return &reset, nil
+}
+
+// NOTE - ใช้ได้ครั้งเดียว ถ้ามี request อื่นใช้ไปก่อนจะได้ false
+func (repo *PasswordResetRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	result := repo.db.WithContext(ctx).Model(&models.PasswordResets{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, fmt.Errorf("Failed to use reset token: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (repo *PasswordResetRepository) InvalidateByUserId(ctx context.Context, userID uint) error {
+	return repo.db.WithContext(ctx).Model(&models.PasswordResets{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordResetRepositoryMock struct {
+	mock.Mock
+}
+
+func NewPasswordResetRepositoryMock() *PasswordResetRepositoryMock {
+	return &PasswordResetRepositoryMock{}
+}
+
+func (m *PasswordResetRepositoryMock) CreatePasswordReset(ctx context.Context, reset *models.PasswordResets) error {
+	args := m.Called(ctx, reset)
+	return args.Error(0)
+}
+
+func (m *PasswordResetRepositoryMock) FindValidByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResets, error) {
+	args := m.Called(ctx, tokenHash)
+
+	if reset, ok := args.Get(0).(*models.PasswordResets); ok {
+		return reset, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *PasswordResetRepositoryMock) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PasswordResetRepositoryMock) InvalidateByUserId(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RateLimitRepositoryInterface interface {
+	Take(ctx context.Context, key string, limit utils.RateLimit) (utils.RateLimitResult, error)
+	DeleteIdle(ctx context.Context, before time.Time) error
+}
+
+type RateLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewRateLimitRepository(db *gorm.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+// NOTE - lock แถวด้วย SELECT FOR UPDATE หลาย instance หยิบ token จากถังเดียวกันได้ถูกต้อง
+func (repo *RateLimitRepository) Take(ctx context.Context, key string, limit utils.RateLimit) (utils.RateLimitResult, error) {
+	var result utils.RateLimitResult
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		empty := models.RateLimitBuckets{BucketKey: key, Tokens: float64(limit.Requests), RefilledAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&empty).Error; err != nil {
+			return err
+		}
+
+		var bucket models.RateLimitBuckets
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("bucket_key = ?", key).First(&bucket).Error; err != nil {
+			return err
+		}
+
+		tokenBucket := utils.TokenBucket{Tokens: bucket.Tokens, RefilledAt: bucket.RefilledAt}
+		result = limit.Take(&tokenBucket, now)
+
+		return tx.Model(&models.RateLimitBuckets{}).Where("bucket_key = ?", key).Updates(map[string]interface{}{
+			"tokens":      tokenBucket.Tokens,
+			"refilled_at": tokenBucket.RefilledAt,
+		}).Error
+	})
+
+	if err != nil {
+		return utils.RateLimitResult{}, fmt.Errorf("Failed to take rate limit token: %w", err)
+	}
+	return result, nil
+}
+
+// NOTE - ถังที่ไม่ได้ใช้นานกว่าช่วง limit เต็มแล้ว ลบทิ้งได้โดยผลไม่เปลี่ยน
+func (repo *RateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) error {
+	return repo.db.WithContext(ctx).Where("refilled_at < ?", before).Delete(&models.RateLimitBuckets{}).Error
+}
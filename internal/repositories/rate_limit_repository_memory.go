@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+// NOTE - ใช้ได้เมื่อรัน instance เดียว ถ้ามีหลาย replica ต้องใช้ตัว DB ไม่งั้นแต่ละตัวนับแยกกัน
+type RateLimitMemoryRepository struct {
+	mu      sync.Mutex
+	buckets map[string]*utils.TokenBucket
+}
+
+func NewRateLimitMemoryRepository() *RateLimitMemoryRepository {
+	return &RateLimitMemoryRepository{buckets: map[string]*utils.TokenBucket{}}
+}
+
+func (repo *RateLimitMemoryRepository) Take(ctx context.Context, key string, limit utils.RateLimit) (utils.RateLimitResult, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	bucket, ok := repo.buckets[key]
+	if !ok {
+		bucket = &utils.TokenBucket{}
+		repo.buckets[key] = bucket
+	}
+
+	return limit.Take(bucket, time.Now()), nil
+}
+
+func (repo *RateLimitMemoryRepository) DeleteIdle(ctx context.Context, before time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for key, bucket := range repo.buckets {
+		if bucket.RefilledAt.Before(before) {
+			delete(repo.buckets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepositoryInterface interface {
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	DeleteByUserId(ctx context.Context, userID uint) error
+}
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// NOTE - ลบ code ชุดเก่าทิ้งแล้วสร้างชุดใหม่ใน transaction เดียว
+func (repo *RecoveryCodeRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCodes{}).Error; err != nil {
+			return fmt.Errorf("Failed to delete recovery codes: %w", err)
+		}
+
+		codes := make([]models.RecoveryCodes, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.RecoveryCodes{UserID: userID, CodeHash: hash})
+		}
+
+		if len(codes) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&codes).Error; err != nil {
+			return fmt.Errorf("Failed to create recovery codes: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// NOTE - ใช้ได้ครั้งเดียว update แบบมีเงื่อนไขกันใช้ซ้ำพร้อมกัน
+func (repo *RecoveryCodeRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := repo.db.WithContext(ctx).Model(&models.RecoveryCodes{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, fmt.Errorf("Failed to use recovery code: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (repo *RecoveryCodeRepository) DeleteByUserId(ctx context.Context, userID uint) error {
+	return repo.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCodes{}).Error
+}
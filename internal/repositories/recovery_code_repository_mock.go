@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RecoveryCodeRepositoryMock struct {
+	mock.Mock
+}
+
+func NewRecoveryCodeRepositoryMock() *RecoveryCodeRepositoryMock {
+	return &RecoveryCodeRepositoryMock{}
+}
+
+func (m *RecoveryCodeRepositoryMock) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	args := m.Called(ctx, userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *RecoveryCodeRepositoryMock) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *RecoveryCodeRepositoryMock) DeleteByUserId(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,461 @@
+// NOTE - ชุด test กลางที่ทุก implementation ของ TaskRepositoryInterface/UserRepositoryInterface/RateLimitRepositoryInterface ต้องผ่านเหมือนกัน
+// ตัว memory รันใน internal/repositories ตัว GORM รันใน tests/integration กับ DB จริง
+package repositorytest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// NOTE - setup ถูกเรียกทุก sub test ต้องคืน repository ที่ไม่มีข้อมูลค้างเลย
+type TaskSetup func(t *testing.T) (repositories.TaskRepositoryInterface, repositories.UserRepositoryInterface)
+
+type UserSetup func(t *testing.T) repositories.UserRepositoryInterface
+
+type RateLimitSetup func(t *testing.T) repositories.RateLimitRepositoryInterface
+
+func RunTaskRepositoryContract(t *testing.T, setup TaskSetup) {
+	ctx := context.Background()
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+
+	t.Run("Create assigns ID and column defaults", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+
+		task := &models.Tasks{Title: "Title", Description: "Description", UserID: owner.ID}
+		require.NoError(t, tasks.CreateTask(ctx, task))
+
+		assert.NotZero(t, task.ID)
+		assert.Equal(t, models.Active, task.Status)
+		assert.Equal(t, models.Low, task.Priority)
+
+		found, err := tasks.FindTaskById(ctx, idString(task.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "Title", found.Title)
+		assert.Equal(t, owner.ID, found.UserID)
+	})
+
+	t.Run("Create rejects empty title", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+
+		assert.Error(t, tasks.CreateTask(ctx, &models.Tasks{UserID: owner.ID}))
+	})
+
+	t.Run("Create rejects unknown priority", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+
+		assert.Error(t, tasks.CreateTask(ctx, &models.Tasks{Title: "Title", Priority: "urgent", UserID: owner.ID}))
+	})
+
+	t.Run("Create in batches assigns IDs and column defaults", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+
+		batch := make([]models.Tasks, 5)
+		for i := range batch {
+			batch[i] = models.Tasks{Title: "Task " + strconv.Itoa(i), UserID: owner.ID}
+		}
+		batch[4].Priority = models.High
+		require.NoError(t, tasks.CreateTasks(ctx, batch, 2))
+
+		for _, task := range batch {
+			assert.NotZero(t, task.ID)
+		}
+		found, err := tasks.FindTaskAll(ctx, owner.ID, "")
+		require.NoError(t, err)
+		assert.Len(t, found, 5)
+		high, err := tasks.FindTaskAll(ctx, owner.ID, string(models.High))
+		require.NoError(t, err)
+		require.Len(t, high, 1)
+		assert.Equal(t, "Task 4", high[0].Title)
+		assert.Equal(t, models.Active, high[0].Status)
+	})
+
+	t.Run("Create in batches rejects empty title", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+
+		assert.Error(t, tasks.CreateTasks(ctx, []models.Tasks{{Title: "Title", UserID: owner.ID}, {UserID: owner.ID}}, 10))
+	})
+
+	t.Run("Find by invalid or missing ID", func(t *testing.T) {
+		tasks, _ := setup(t)
+
+		_, err := tasks.FindTaskById(ctx, "abc")
+		assert.EqualError(t, err, "Invalid Task ID fomat")
+
+		_, err = tasks.FindTaskById(ctx, "999")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Find all filters by owner and priority newest first", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+		other := createUser(t, users, "other@example.com")
+
+		base := now.Add(-time.Hour).Truncate(time.Second)
+		createTask(t, tasks, &models.Tasks{Title: "first", Priority: models.High, UserID: owner.ID, Model: gorm.Model{CreatedAt: base}})
+		createTask(t, tasks, &models.Tasks{Title: "second", Priority: models.Low, UserID: owner.ID, Model: gorm.Model{CreatedAt: base.Add(time.Minute)}})
+		createTask(t, tasks, &models.Tasks{Title: "third", Priority: models.High, UserID: owner.ID, Model: gorm.Model{CreatedAt: base.Add(2 * time.Minute)}})
+		createTask(t, tasks, &models.Tasks{Title: "not mine", Priority: models.High, UserID: other.ID, Model: gorm.Model{CreatedAt: base.Add(3 * time.Minute)}})
+
+		all, err := tasks.FindTaskAll(ctx, owner.ID, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"third", "second", "first"}, titles(all))
+
+		high, err := tasks.FindTaskAll(ctx, owner.ID, string(models.High))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"third", "first"}, titles(high))
+	})
+
+	t.Run("Update overwrites non-zero fields and always completed", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+		task := createTask(t, tasks, &models.Tasks{Title: "Title", Description: "Description", Priority: models.High, Completed: true, UserID: owner.ID})
+
+		require.NoError(t, tasks.UpdateTaskById(ctx, &models.Tasks{Title: "New title", Completed: false}, task.ID))
+
+		found, err := tasks.FindTaskById(ctx, idString(task.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "New title", found.Title)
+		assert.Equal(t, "Description", found.Description)
+		assert.Equal(t, models.High, found.Priority)
+		assert.False(t, found.Completed)
+	})
+
+	t.Run("Update missing task", func(t *testing.T) {
+		tasks, _ := setup(t)
+
+		err := tasks.UpdateTaskById(ctx, &models.Tasks{Title: "New title"}, 999)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Delete is soft and idempotent", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+		task := createTask(t, tasks, &models.Tasks{Title: "Title", UserID: owner.ID})
+
+		require.NoError(t, tasks.DeleteTaskById(ctx, task.ID))
+
+		_, err := tasks.FindTaskById(ctx, idString(task.ID))
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		all, err := tasks.FindTaskAll(ctx, owner.ID, "")
+		require.NoError(t, err)
+		assert.Empty(t, all)
+		assert.ErrorIs(t, tasks.UpdateTaskById(ctx, &models.Tasks{Title: "New title"}, task.ID), gorm.ErrRecordNotFound)
+
+		assert.NoError(t, tasks.DeleteTaskById(ctx, task.ID))
+		assert.NoError(t, tasks.DeleteTaskById(ctx, 999))
+	})
+
+	t.Run("Find complete pending and overdue", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+		createTask(t, tasks, &models.Tasks{Title: "done late", DueDate: yesterday, Completed: true, UserID: owner.ID})
+		createTask(t, tasks, &models.Tasks{Title: "late", DueDate: yesterday, UserID: owner.ID})
+		createTask(t, tasks, &models.Tasks{Title: "upcoming", DueDate: tomorrow, UserID: owner.ID})
+
+		complete, err := tasks.FindTaskComplete(ctx, owner.ID, "", true)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"done late"}, titles(complete))
+
+		incomplete, err := tasks.FindTaskComplete(ctx, owner.ID, "", false)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"late", "upcoming"}, titles(incomplete))
+
+		pending, err := tasks.FindTaskPending(ctx, owner.ID, "", false)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"upcoming"}, titles(pending))
+
+		overdue, err := tasks.FindTaskOverdue(ctx, owner.ID, "", false)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"done late", "late"}, titles(overdue))
+	})
+
+	t.Run("Count overdue across users skips completed and deleted", func(t *testing.T) {
+		tasks, users := setup(t)
+		owner := createUser(t, users, "owner@example.com")
+		other := createUser(t, users, "other@example.com")
+		createTask(t, tasks, &models.Tasks{Title: "late", DueDate: yesterday, Priority: models.High, UserID: owner.ID})
+		createTask(t, tasks, &models.Tasks{Title: "late too", DueDate: yesterday, Priority: models.High, UserID: other.ID})
+		createTask(t, tasks, &models.Tasks{Title: "late low", DueDate: yesterday, Priority: models.Low, UserID: other.ID})
+		createTask(t, tasks, &models.Tasks{Title: "done", DueDate: yesterday, Completed: true, UserID: owner.ID})
+		createTask(t, tasks, &models.Tasks{Title: "upcoming", DueDate: tomorrow, UserID: owner.ID})
+		deleted := createTask(t, tasks, &models.Tasks{Title: "deleted", DueDate: yesterday, Priority: models.Medium, UserID: owner.ID})
+		require.NoError(t, tasks.DeleteTaskById(ctx, deleted.ID))
+
+		counts, err := tasks.CountOverdueByPriority(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[models.Priority]int64{models.High: 2, models.Low: 1}, counts)
+	})
+}
+
+func RunUserRepositoryContract(t *testing.T, setup UserSetup) {
+	ctx := context.Background()
+
+	t.Run("Create assigns ID and column defaults", func(t *testing.T) {
+		users := setup(t)
+
+		user := createUser(t, users, "user@example.com")
+
+		assert.NotZero(t, user.ID)
+		assert.Equal(t, models.User, user.Role)
+		assert.NotEmpty(t, user.Photo)
+	})
+
+	t.Run("Create rejects duplicate email", func(t *testing.T) {
+		users := setup(t)
+		createUser(t, users, "user@example.com")
+
+		assert.Error(t, users.CreateUser(ctx, &models.Users{Email: "user@example.com", Name: "Other", Password: "hash"}))
+	})
+
+	t.Run("Create in batches assigns IDs", func(t *testing.T) {
+		users := setup(t)
+
+		batch := []models.Users{
+			{Email: "one@example.com", Name: "One", Password: "hash"},
+			{Email: "two@example.com", Name: "Two", Password: "hash"},
+			{Email: "three@example.com", Name: "Three", Password: "hash", Role: models.Admin},
+		}
+		require.NoError(t, users.CreateUsers(ctx, batch, 2))
+
+		for _, user := range batch {
+			found, err := users.FindByEmail(ctx, user.Email)
+			require.NoError(t, err)
+			require.NotNil(t, found)
+			assert.NotZero(t, user.ID)
+			assert.Equal(t, user.ID, found.ID)
+		}
+		found, _ := users.FindByEmail(ctx, "one@example.com")
+		assert.Equal(t, models.User, found.Role)
+	})
+
+	t.Run("Find by email", func(t *testing.T) {
+		users := setup(t)
+		created := createUser(t, users, "user@example.com")
+
+		found, err := users.FindByEmail(ctx, "user@example.com")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, created.ID, found.ID)
+
+		missing, err := users.FindByEmail(ctx, "missing@example.com")
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("Find by invalid or missing ID", func(t *testing.T) {
+		users := setup(t)
+
+		_, err := users.FindUserById(ctx, "abc")
+		assert.Error(t, err)
+
+		_, err = users.FindUserById(ctx, "999")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Update overwrites non-zero fields", func(t *testing.T) {
+		users := setup(t)
+		user := createUser(t, users, "user@example.com")
+
+		require.NoError(t, users.UpdateUserById(ctx, &models.Users{Bio: "Hello"}, user.ID))
+
+		found, err := users.FindUserById(ctx, idString(user.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", found.Bio)
+		assert.Equal(t, "Test User", found.Name)
+		assert.Equal(t, "user@example.com", found.Email)
+
+		assert.ErrorIs(t, users.UpdateUserById(ctx, &models.Users{Bio: "Hello"}, 999), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Update password stamps change time", func(t *testing.T) {
+		users := setup(t)
+		user := createUser(t, users, "user@example.com")
+
+		require.NoError(t, users.UpdatePassword(ctx, user.ID, "new hash"))
+
+		found, err := users.FindUserById(ctx, idString(user.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "new hash", found.Password)
+		assert.NotNil(t, found.PasswordChangedAt)
+
+		assert.ErrorIs(t, users.UpdatePassword(ctx, 999, "new hash"), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Update two factor can turn it off", func(t *testing.T) {
+		users := setup(t)
+		user := createUser(t, users, "user@example.com")
+
+		require.NoError(t, users.UpdateTwoFactor(ctx, user.ID, "SECRET", true))
+		require.NoError(t, users.UpdateTwoFactor(ctx, user.ID, "", false))
+
+		found, err := users.FindUserById(ctx, idString(user.ID))
+		require.NoError(t, err)
+		assert.False(t, found.TwoFactorEnabled)
+		assert.Empty(t, found.TwoFactorSecret)
+
+		assert.ErrorIs(t, users.UpdateTwoFactor(ctx, 999, "", false), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Use TOTP step only moves forward", func(t *testing.T) {
+		users := setup(t)
+		user := createUser(t, users, "user@example.com")
+
+		used, err := users.UseTOTPStep(ctx, user.ID, 100)
+		require.NoError(t, err)
+		assert.True(t, used)
+
+		used, err = users.UseTOTPStep(ctx, user.ID, 100)
+		require.NoError(t, err)
+		assert.False(t, used)
+
+		used, err = users.UseTOTPStep(ctx, user.ID, 99)
+		require.NoError(t, err)
+		assert.False(t, used)
+
+		used, err = users.UseTOTPStep(ctx, user.ID, 101)
+		require.NoError(t, err)
+		assert.True(t, used)
+
+		found, err := users.FindUserById(ctx, idString(user.ID))
+		require.NoError(t, err)
+		assert.Equal(t, int64(101), found.TwoFactorLastStep)
+
+		used, err = users.UseTOTPStep(ctx, 999, 100)
+		require.NoError(t, err)
+		assert.False(t, used)
+	})
+
+	t.Run("Update role", func(t *testing.T) {
+		users := setup(t)
+		user := createUser(t, users, "user@example.com")
+
+		require.NoError(t, users.UpdateRole(ctx, user.ID, models.Admin))
+		found, err := users.FindUserById(ctx, idString(user.ID))
+		require.NoError(t, err)
+		assert.Equal(t, models.Admin, found.Role)
+
+		assert.Error(t, users.UpdateRole(ctx, user.ID, "owner"))
+		assert.ErrorIs(t, users.UpdateRole(ctx, 999, models.Admin), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Disable and enable", func(t *testing.T) {
+		users := setup(t)
+		user := createUser(t, users, "user@example.com")
+
+		disabledAt := time.Now()
+		require.NoError(t, users.UpdateDisabled(ctx, user.ID, &disabledAt))
+		found, err := users.FindByEmail(ctx, "user@example.com")
+		require.NoError(t, err)
+		assert.NotNil(t, found.DisabledAt)
+
+		require.NoError(t, users.UpdateDisabled(ctx, user.ID, nil))
+		found, err = users.FindByEmail(ctx, "user@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, found.DisabledAt)
+
+		assert.ErrorIs(t, users.UpdateDisabled(ctx, 999, nil), gorm.ErrRecordNotFound)
+	})
+}
+
+// NOTE - เช็คแค่ burst กับการแยกถังตาม key ส่วนการเติม token ตามเวลาอยู่ใน utils.RateLimit.Take
+func RunRateLimitRepositoryContract(t *testing.T, setup RateLimitSetup) {
+	ctx := context.Background()
+	limit := utils.RateLimit{Requests: 3, Per: time.Hour}
+
+	t.Run("Take allows a burst up to the limit", func(t *testing.T) {
+		store := setup(t)
+
+		for i := 0; i < limit.Requests; i++ {
+			result, err := store.Take(ctx, "api:ip:127.0.0.1", limit)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, limit.Requests-1-i, result.Remaining)
+		}
+
+		result, err := store.Take(ctx, "api:ip:127.0.0.1", limit)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.InDelta(t, (20 * time.Minute).Seconds(), result.RetryAfter.Seconds(), 1)
+	})
+
+	t.Run("Take counts each key separately", func(t *testing.T) {
+		store := setup(t)
+
+		for i := 0; i < limit.Requests; i++ {
+			_, err := store.Take(ctx, "api:user:1", limit)
+			require.NoError(t, err)
+		}
+
+		result, err := store.Take(ctx, "api:user:2", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+
+		result, err = store.Take(ctx, "auth:user:1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("DeleteIdle removes only buckets refilled before the cutoff", func(t *testing.T) {
+		store := setup(t)
+
+		for i := 0; i < limit.Requests; i++ {
+			_, err := store.Take(ctx, "api:user:1", limit)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, store.DeleteIdle(ctx, time.Now().Add(-time.Minute)))
+		result, err := store.Take(ctx, "api:user:1", limit)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+
+		require.NoError(t, store.DeleteIdle(ctx, time.Now().Add(time.Minute)))
+		result, err = store.Take(ctx, "api:user:1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+}
+
+func createUser(t *testing.T, users repositories.UserRepositoryInterface, email string) *models.Users {
+	t.Helper()
+	user := &models.Users{Email: email, Name: "Test User", Password: "hash"}
+	require.NoError(t, users.CreateUser(context.Background(), user))
+	return user
+}
+
+func createTask(t *testing.T, tasks repositories.TaskRepositoryInterface, task *models.Tasks) *models.Tasks {
+	t.Helper()
+	require.NoError(t, tasks.CreateTask(context.Background(), task))
+	return task
+}
+
+func titles(tasks []models.Tasks) []string {
+	result := []string{}
+	for _, task := range tasks {
+		result = append(result, task.Title)
+	}
+	return result
+}
+
+func idString(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type SessionRepositoryInterface interface {
+	CreateSession(ctx context.Context, session *models.Sessions) error
+	FindActiveSessionsByUserId(ctx context.Context, userID uint, since time.Time) ([]models.Sessions, error)
+	FindSessionById(ctx context.Context, idStr string) (*models.Sessions, error)
+	FindActiveBySessionID(ctx context.Context, sessionID string) (*models.Sessions, error)
+	UpdateLastSeen(ctx context.Context, id uint, ip string) error
+	RevokeSession(ctx context.Context, id uint) error
+	RevokeSessionsByUserId(ctx context.Context, userID uint, exceptSessionID string) error
+}
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (repo *SessionRepository) CreateSession(ctx context.Context, session *models.Sessions) error {
+	return repo.db.WithContext(ctx).Create(session).Error
+}
+
+// NOTE - session ที่สร้างก่อน since ถือว่าหมดอายุไปพร้อม JWT แล้ว ไม่ต้องแสดง
+func (repo *SessionRepository) FindActiveSessionsByUserId(ctx context.Context, userID uint, since time.Time) ([]models.Sessions, error) {
+	var sessions []models.Sessions
+
+	err := repo.db.WithContext(ctx).Where("user_id = ? AND revoked_at IS NULL AND created_at > ?", userID, since).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (repo *SessionRepository) FindSessionById(ctx context.Context, idStr string) (*models.Sessions, error) {
+	var session models.Sessions
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return nil, errors.New("Invalid Session ID fomat")
+	}
+
+	if err := repo.db.WithContext(ctx).First(&session, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// NOTE - คืน nil ถ้าไม่เจอหรือถูก revoke แล้ว
+func (repo *SessionRepository) FindActiveBySessionID(ctx context.Context, sessionID string) (*models.Sessions, error) {
+	var session models.Sessions
+
+	err := repo.db.WithContext(ctx).Where("session_id = ? AND revoked_at IS NULL", sessionID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find session: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (repo *SessionRepository) UpdateLastSeen(ctx context.Context, id uint, ip string) error {
+	err := repo.db.WithContext(ctx).Model(&models.Sessions{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"ip":           ip,
+	}).Error
+
+	if err != nil {
+		return fmt.Errorf("Failed to update session: %w", err)
+	}
+	return nil
+}
+
+func (repo *SessionRepository) RevokeSession(ctx context.Context, id uint) error {
+	return repo.db.WithContext(ctx).Model(&models.Sessions{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now()).Error
+}
+
+// NOTE - exceptSessionID ว่างคือ revoke ทุก session ของ user
+func (repo *SessionRepository) RevokeSessionsByUserId(ctx context.Context, userID uint, exceptSessionID string) error {
+	return repo.db.WithContext(ctx).Model(&models.Sessions{}).
+		Where("user_id = ? AND revoked_at IS NULL AND session_id <> ?", userID, exceptSessionID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionRepositoryMock struct {
+	mock.Mock
+}
+
+func NewSessionRepositoryMock() *SessionRepositoryMock {
+	return &SessionRepositoryMock{}
+}
+
+func (m *SessionRepositoryMock) CreateSession(ctx context.Context, session *models.Sessions) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *SessionRepositoryMock) FindActiveSessionsByUserId(ctx context.Context, userID uint, since time.Time) ([]models.Sessions, error) {
+	args := m.Called(ctx, userID, since)
+	if sessions, ok := args.Get(0).([]models.Sessions); ok {
+		return sessions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionRepositoryMock) FindSessionById(ctx context.Context, idStr string) (*models.Sessions, error) {
+	args := m.Called(ctx, idStr)
+	if session, ok := args.Get(0).(*models.Sessions); ok {
+		return session, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionRepositoryMock) FindActiveBySessionID(ctx context.Context, sessionID string) (*models.Sessions, error) {
+	args := m.Called(ctx, sessionID)
+	if session, ok := args.Get(0).(*models.Sessions); ok {
+		return session, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionRepositoryMock) UpdateLastSeen(ctx context.Context, id uint, ip string) error {
+	args := m.Called(ctx, id, ip)
+	return args.Error(0)
+}
+
+func (m *SessionRepositoryMock) RevokeSession(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *SessionRepositoryMock) RevokeSessionsByUserId(ctx context.Context, userID uint, exceptSessionID string) error {
+	args := m.Called(ctx, userID, exceptSessionID)
+	return args.Error(0)
+}
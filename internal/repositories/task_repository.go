@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -12,14 +13,16 @@ import (
 )
 
 type TaskRepositoryInterface interface {
-	CreateTask(task *models.Tasks)error
-	FindTaskAll(userId uint, priority string) ([]models.Tasks,error)
-	FindTaskById(idStr string) (*models.Tasks, error)
-	UpdateTaskById(updatedTaskValue *models.Tasks, taskID uint) error
-	DeleteTaskById(id uint) error
-	FindTaskComplete(userId uint, priority string, complete bool) ([]models.Tasks,error)
-	FindTaskPending(userId uint, priority string, complete bool) ([]models.Tasks,error)
-	FindTaskOverdue(userId uint, priority string, complete bool) ([]models.Tasks,error)
+	CreateTask(ctx context.Context, task *models.Tasks)error
+	CreateTasks(ctx context.Context, tasks []models.Tasks, batchSize int) error
+	FindTaskAll(ctx context.Context, userId uint, priority string) ([]models.Tasks,error)
+	FindTaskById(ctx context.Context, idStr string) (*models.Tasks, error)
+	UpdateTaskById(ctx context.Context, updatedTaskValue *models.Tasks, taskID uint) error
+	DeleteTaskById(ctx context.Context, id uint) error
+	FindTaskComplete(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error)
+	FindTaskPending(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error)
+	FindTaskOverdue(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error)
+	CountOverdueByPriority(ctx context.Context) (map[models.Priority]int64, error)
 }
 
 type TaskRepository struct {
@@ -30,19 +33,30 @@ func NewTaskRepository(db *gorm.DB) *TaskRepository{
 	return &TaskRepository{db:db}
 }
 
-func (repo *TaskRepository) CreateTask(task *models.Tasks)error {
+func (repo *TaskRepository) CreateTask(ctx context.Context, task *models.Tasks)error {
 	if strings.Trim(task.Title,"") == ""{
 		return errors.New("Task Title can't be empty")
 	}
 
-	return repo.db.Create(task).Error
+	return repo.db.WithContext(ctx).Create(task).Error
 }
 
+// NOTE - insert ทีละ batchSize แถวต่อ statement ใช้ตอน seed ข้อมูลเยอะๆ ID ถูกเติมกลับเข้า slice
+func (repo *TaskRepository) CreateTasks(ctx context.Context, tasks []models.Tasks, batchSize int) error {
+	for i := range tasks {
+		if strings.TrimSpace(tasks[i].Title) == "" {
+			return errors.New("Task Title can't be empty")
+		}
+	}
+
+	return repo.db.WithContext(ctx).CreateInBatches(tasks, batchSize).Error
+}
 
-func (repo *TaskRepository) FindTaskAll(userId uint, priority string) ([]models.Tasks,error) {
+
+func (repo *TaskRepository) FindTaskAll(ctx context.Context, userId uint, priority string) ([]models.Tasks,error) {
 	var tasks []models.Tasks
 	
-	query := repo.db.Where("user_id = ?",userId)
+	query := repo.db.WithContext(ctx).Where("user_id = ?",userId)
 
 	if priority != ""{
 		query = query.Where("priority = ?",priority)
@@ -55,7 +69,7 @@ func (repo *TaskRepository) FindTaskAll(userId uint, priority string) ([]models.
 	return tasks,nil
 }
 
-func (repo *TaskRepository) FindTaskById(idStr string) (*models.Tasks, error) {
+func (repo *TaskRepository) FindTaskById(ctx context.Context, idStr string) (*models.Tasks, error) {
 	var task models.Tasks
 
 	id,err :=  strconv.Atoi(idStr)
@@ -64,7 +78,7 @@ func (repo *TaskRepository) FindTaskById(idStr string) (*models.Tasks, error) {
 		return nil,errors.New("Invalid Task ID fomat")
 	}
 
-	result := repo.db.First(&task, id)
+	result := repo.db.WithContext(ctx).First(&task, id)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -73,10 +87,10 @@ func (repo *TaskRepository) FindTaskById(idStr string) (*models.Tasks, error) {
 	return &task,nil
 }
 
-func (repo *TaskRepository) UpdateTaskById(updatedTaskValue *models.Tasks, taskID uint) error {
+func (repo *TaskRepository) UpdateTaskById(ctx context.Context, updatedTaskValue *models.Tasks, taskID uint) error {
 	var task models.Tasks
 
-	result := repo.db.First(&task, taskID)
+	result := repo.db.WithContext(ctx).First(&task, taskID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return fmt.Errorf("Can't find task by ID: %w", result.Error)
@@ -85,20 +99,20 @@ func (repo *TaskRepository) UpdateTaskById(updatedTaskValue *models.Tasks, taskI
 	}
 
 	// NOTE - Update task 
-	if err:= repo.db.Model(&task).Updates(updatedTaskValue).Error; err != nil {
+	if err:= repo.db.WithContext(ctx).Model(&task).Updates(updatedTaskValue).Error; err != nil {
 		return fmt.Errorf("Failed to update task: %w",err)
 	} 
-	if err := repo.db.Model(&task).UpdateColumn("Completed", updatedTaskValue.Completed).Error; err != nil {
+	if err := repo.db.WithContext(ctx).Model(&task).UpdateColumn("Completed", updatedTaskValue.Completed).Error; err != nil {
 		return fmt.Errorf("Failed to update Completed: %w", err)
 	}
 
 	return nil
 }
 
-func (repo *TaskRepository) DeleteTaskById(id uint) error {
+func (repo *TaskRepository) DeleteTaskById(ctx context.Context, id uint) error {
 
 	// ลบข้อมูลในฐานข้อมูลโดยใช้ id
-	result := repo.db.Delete(&models.Tasks{}, id)
+	result := repo.db.WithContext(ctx).Delete(&models.Tasks{}, id)
 	if result.Error != nil {
 		return result.Error // ส่งคืนข้อผิดพลาดหากการลบล้มเหลว
 	}
@@ -106,10 +120,10 @@ func (repo *TaskRepository) DeleteTaskById(id uint) error {
 	return nil // ส่งคืน nil หากลบสำเร็จ
 }
 
-func (repo *TaskRepository) FindTaskComplete(userId uint, priority string, complete bool) ([]models.Tasks,error) {
+func (repo *TaskRepository) FindTaskComplete(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error) {
 	var tasks []models.Tasks
 	
-	query := repo.db.Where("user_id = ?",userId).Where("completed = ?", complete)
+	query := repo.db.WithContext(ctx).Where("user_id = ?",userId).Where("completed = ?", complete)
 
 	if priority != ""{
 		query = query.Where("priority = ?",priority)
@@ -121,10 +135,10 @@ func (repo *TaskRepository) FindTaskComplete(userId uint, priority string, compl
 	return tasks,nil
 }
 
-func (repo *TaskRepository) FindTaskPending(userId uint, priority string, complete bool) ([]models.Tasks,error) {
+func (repo *TaskRepository) FindTaskPending(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error) {
 	var tasks []models.Tasks
 	
-	query := repo.db.Where("user_id = ?",userId).Where("due_date >= ?", time.Now())
+	query := repo.db.WithContext(ctx).Where("user_id = ?",userId).Where("due_date >= ?", time.Now())
 
 	if priority != ""{
 		query = query.Where("priority = ?",priority)
@@ -136,10 +150,10 @@ func (repo *TaskRepository) FindTaskPending(userId uint, priority string, comple
 	return tasks,nil
 }
 
-func (repo *TaskRepository) FindTaskOverdue(userId uint, priority string, complete bool) ([]models.Tasks,error) {
+func (repo *TaskRepository) FindTaskOverdue(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error) {
 	var tasks []models.Tasks
 	
-	query := repo.db.Where("user_id = ?",userId).Where("due_date < ?", time.Now())
+	query := repo.db.WithContext(ctx).Where("user_id = ?",userId).Where("due_date < ?", time.Now())
 
 	if priority != ""{
 		query = query.Where("priority = ?",priority)
@@ -149,4 +163,28 @@ func (repo *TaskRepository) FindTaskOverdue(userId uint, priority string, comple
 		return  nil,err
 	}
 	return tasks,nil
-}
\ No newline at end of file
+}
+
+// NOTE - ของทุก user รวมกัน ใช้กับ metric ไม่ได้ใช้ตอบ API
+func (repo *TaskRepository) CountOverdueByPriority(ctx context.Context) (map[models.Priority]int64, error) {
+	var rows []struct {
+		Priority models.Priority
+		Count    int64
+	}
+
+	err := repo.db.WithContext(ctx).Model(&models.Tasks{}).
+		Select("priority, COUNT(*) AS count").
+		Where("due_date < ?", time.Now()).
+		Where("completed = ?", false).
+		Group("priority").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[models.Priority]int64{}
+	for _, row := range rows {
+		counts[row.Priority] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// NOTE - ทำตัวเหมือน TaskRepository ทุกอย่าง (soft delete, default ของ column, check constraint, เรียง created_at ล่าสุดก่อน)
+// แต่ไม่เช็ค foreign key ไปที่ users ใช้ใน test หรือ demo ที่ไม่อยากต่อ DB
+type TaskMemoryRepository struct {
+	mu     sync.Mutex
+	tasks  map[uint]models.Tasks
+	nextID uint
+}
+
+func NewTaskMemoryRepository() *TaskMemoryRepository {
+	return &TaskMemoryRepository{tasks: map[uint]models.Tasks{}}
+}
+
+func (repo *TaskMemoryRepository) CreateTask(ctx context.Context, task *models.Tasks) error {
+	if task.Title == "" {
+		return errors.New("Task Title can't be empty")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if task.Status == "" {
+		task.Status = models.Active
+	}
+	if task.Priority == "" {
+		task.Priority = models.Low
+	}
+	if err := checkTask(task); err != nil {
+		return err
+	}
+
+	if task.ID == 0 {
+		repo.nextID++
+		task.ID = repo.nextID
+	} else if _, ok := repo.tasks[task.ID]; ok {
+		return fmt.Errorf("Duplicate task ID: %d", task.ID)
+	} else if task.ID > repo.nextID {
+		repo.nextID = task.ID
+	}
+
+	now := time.Now()
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now
+	}
+	if task.UpdatedAt.IsZero() {
+		task.UpdatedAt = now
+	}
+
+	repo.tasks[task.ID] = *task
+	return nil
+}
+
+// NOTE - ไม่มี statement ให้รวม batchSize เลยไม่มีผล
+func (repo *TaskMemoryRepository) CreateTasks(ctx context.Context, tasks []models.Tasks, batchSize int) error {
+	for i := range tasks {
+		if err := repo.CreateTask(ctx, &tasks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *TaskMemoryRepository) FindTaskAll(ctx context.Context, userId uint, priority string) ([]models.Tasks, error) {
+	return repo.find(userId, priority, func(task models.Tasks) bool { return true }), nil
+}
+
+func (repo *TaskMemoryRepository) FindTaskById(ctx context.Context, idStr string) (*models.Tasks, error) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return nil, errors.New("Invalid Task ID fomat")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	task, ok := repo.live(uint(id))
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &task, nil
+}
+
+// NOTE - เหมือน Updates แบบ struct ของ GORM ทับเฉพาะ field ที่ไม่ใช่ zero value ยกเว้น Completed ที่ทับเสมอ
+func (repo *TaskMemoryRepository) UpdateTaskById(ctx context.Context, updatedTaskValue *models.Tasks, taskID uint) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	task, ok := repo.live(taskID)
+	if !ok {
+		return fmt.Errorf("Can't find task by ID: %w", gorm.ErrRecordNotFound)
+	}
+
+	if !updatedTaskValue.DueDate.IsZero() {
+		task.DueDate = updatedTaskValue.DueDate
+	}
+	if updatedTaskValue.Title != "" {
+		task.Title = updatedTaskValue.Title
+	}
+	if updatedTaskValue.Description != "" {
+		task.Description = updatedTaskValue.Description
+	}
+	if updatedTaskValue.Status != "" {
+		task.Status = updatedTaskValue.Status
+	}
+	if updatedTaskValue.Priority != "" {
+		task.Priority = updatedTaskValue.Priority
+	}
+	if updatedTaskValue.UserID != 0 {
+		task.UserID = updatedTaskValue.UserID
+	}
+	task.Completed = updatedTaskValue.Completed
+	if err := checkTask(&task); err != nil {
+		return fmt.Errorf("Failed to update task: %w", err)
+	}
+	task.UpdatedAt = time.Now()
+
+	repo.tasks[taskID] = task
+	return nil
+}
+
+// NOTE - soft delete เหมือน gorm.Model ไม่เจอก็ไม่ error
+func (repo *TaskMemoryRepository) DeleteTaskById(ctx context.Context, id uint) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if task, ok := repo.live(id); ok {
+		task.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		repo.tasks[id] = task
+	}
+	return nil
+}
+
+func (repo *TaskMemoryRepository) FindTaskComplete(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks, error) {
+	return repo.find(userId, priority, func(task models.Tasks) bool { return task.Completed == complete }), nil
+}
+
+func (repo *TaskMemoryRepository) FindTaskPending(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks, error) {
+	now := time.Now()
+	return repo.find(userId, priority, func(task models.Tasks) bool { return !task.DueDate.Before(now) }), nil
+}
+
+func (repo *TaskMemoryRepository) FindTaskOverdue(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks, error) {
+	now := time.Now()
+	return repo.find(userId, priority, func(task models.Tasks) bool { return task.DueDate.Before(now) }), nil
+}
+
+func (repo *TaskMemoryRepository) CountOverdueByPriority(ctx context.Context) (map[models.Priority]int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	counts := map[models.Priority]int64{}
+	for _, task := range repo.tasks {
+		if !task.DeletedAt.Valid && !task.Completed && task.DueDate.Before(now) {
+			counts[task.Priority]++
+		}
+	}
+	return counts, nil
+}
+
+func (repo *TaskMemoryRepository) live(id uint) (models.Tasks, bool) {
+	task, ok := repo.tasks[id]
+	if !ok || task.DeletedAt.Valid {
+		return models.Tasks{}, false
+	}
+	return task, true
+}
+
+func (repo *TaskMemoryRepository) find(userId uint, priority string, match func(models.Tasks) bool) []models.Tasks {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tasks := []models.Tasks{}
+	for _, task := range repo.tasks {
+		if task.DeletedAt.Valid || task.UserID != userId {
+			continue
+		}
+		if priority != "" && string(task.Priority) != priority {
+			continue
+		}
+		if match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID > tasks[j].ID
+	})
+	return tasks
+}
+
+// NOTE - แทน check constraint chk_tasks_status/chk_tasks_priority ของ DB
+func checkTask(task *models.Tasks) error {
+	switch task.Status {
+	case models.Active, models.Inactive:
+	default:
+		return fmt.Errorf("Invalid task status: %s", task.Status)
+	}
+	switch task.Priority {
+	case models.Low, models.Medium, models.High:
+	default:
+		return fmt.Errorf("Invalid task priority: %s", task.Priority)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,39 +15,44 @@ func NewTaskRepositoryMock() *TaskRepositoryMock{
 	return &TaskRepositoryMock{}
 }
 
-func (m *TaskRepositoryMock) CreateTask(task *models.Tasks)error {
-	args := m.Called(task)
+func (m *TaskRepositoryMock) CreateTask(ctx context.Context, task *models.Tasks)error {
+	args := m.Called(ctx, task)
 	return args.Error(0)
 }
 
-func (m *TaskRepositoryMock) FindTaskAll(userId uint, priority string) ([]models.Tasks, error) {
-	args := m.Called(userId, priority)
+func (m *TaskRepositoryMock) CreateTasks(ctx context.Context, tasks []models.Tasks, batchSize int) error {
+	args := m.Called(ctx, tasks, batchSize)
+	return args.Error(0)
+}
+
+func (m *TaskRepositoryMock) FindTaskAll(ctx context.Context, userId uint, priority string) ([]models.Tasks, error) {
+	args := m.Called(ctx, userId, priority)
 	if  tasks,ok := args.Get(0).([]models.Tasks);ok {
 		return tasks,nil
 	}
 	return nil, args.Error(1)
 }
 
-func (m *TaskRepositoryMock) FindTaskById(idStr string) (*models.Tasks, error) {
-	args := m.Called(idStr)
+func (m *TaskRepositoryMock) FindTaskById(ctx context.Context, idStr string) (*models.Tasks, error) {
+	args := m.Called(ctx, idStr)
 	if task, ok := args.Get(0).(*models.Tasks); ok {
 		return task, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *TaskRepositoryMock) UpdateTaskById(updatedTaskValue *models.Tasks, taskID uint) error {
-	args := m.Called(updatedTaskValue,taskID)
+func (m *TaskRepositoryMock) UpdateTaskById(ctx context.Context, updatedTaskValue *models.Tasks, taskID uint) error {
+	args := m.Called(ctx, updatedTaskValue,taskID)
 	return args.Error(0)
 }
 
-func (m *TaskRepositoryMock) DeleteTaskById(id uint) error{
-	args := m.Called(id)
+func (m *TaskRepositoryMock) DeleteTaskById(ctx context.Context, id uint) error{
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *TaskRepositoryMock)FindTaskComplete(userId uint, priority string, complete bool) ([]models.Tasks,error){
-	args := m.Called(userId,priority,complete)
+func (m *TaskRepositoryMock)FindTaskComplete(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error){
+	args := m.Called(ctx, userId,priority,complete)
 
 	if tasks, ok := args.Get(0).([]models.Tasks); ok {
 		return tasks, nil
@@ -54,21 +61,26 @@ func (m *TaskRepositoryMock)FindTaskComplete(userId uint, priority string, compl
 	return nil, args.Error(1)
 }
 
-func (m *TaskRepositoryMock)FindTaskPending(userId uint, priority string, complete bool) ([]models.Tasks,error){
-	args := m.Called(userId,priority,complete)
+func (m *TaskRepositoryMock)FindTaskPending(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error){
+	args := m.Called(ctx, userId,priority,complete)
 	if task,ok :=  args.Get(0).([]models.Tasks); ok {
 		return  task,nil
 	}
 	return nil,args.Error(1)
 }
 
-func (m *TaskRepositoryMock)FindTaskOverdue(userId uint, priority string, complete bool) ([]models.Tasks,error){
-	args := m.Called(userId,priority,complete)
+func (m *TaskRepositoryMock)FindTaskOverdue(ctx context.Context, userId uint, priority string, complete bool) ([]models.Tasks,error){
+	args := m.Called(ctx, userId,priority,complete)
 	if task,ok := args.Get(0).([]models.Tasks) ; ok{
 		return task,nil
 	}
 	return nil,args.Error(1)
 }
 
-
-
+func (m *TaskRepositoryMock) CountOverdueByPriority(ctx context.Context) (map[models.Priority]int64, error) {
+	args := m.Called(ctx)
+	if counts, ok := args.Get(0).(map[models.Priority]int64); ok {
+		return counts, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// NOTE - repository ชุดนี้ผูกกับ transaction เดียวกัน ใช้ได้แค่ใน fn ที่ส่งให้ Do ห้ามเก็บไว้ใช้ต่อ
+type TxRepositories struct {
+	Tasks TaskRepositoryInterface
+	Users UserRepositoryInterface
+}
+
+type UnitOfWorkInterface interface {
+	Do(ctx context.Context, fn func(ctx context.Context, repos TxRepositories) error) error
+}
+
+type txKey struct{}
+
+type txState struct {
+	tx    *gorm.DB
+	depth int
+}
+
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// NOTE - fn คืน error หรือ panic = rollback ทั้งหมด (panic ถูกโยนต่อหลัง rollback)
+// เรียก Do ซ้อนด้วย ctx ที่ได้จาก fn จะกลายเป็น savepoint ข้างในพังแล้ว rollback แค่ส่วนของตัวเอง
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos TxRepositories) error) error {
+	state, nested := ctx.Value(txKey{}).(txState)
+	if !nested {
+		return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return run(ctx, txState{tx: tx}, fn)
+		})
+	}
+	return savepoint(ctx, state, fn)
+}
+
+// NOTE - ไม่ใช้ nested Transaction ของ GORM เพราะตั้งชื่อ savepoint จาก pointer ของ fn
+// Do ซ้อนกันหลายชั้นจะได้ชื่อซ้ำ rollback ชั้นนอกแล้วไปถอยแค่ถึง savepoint ของชั้นใน
+func savepoint(ctx context.Context, state txState, fn func(ctx context.Context, repos TxRepositories) error) (err error) {
+	tx := state.tx.WithContext(ctx)
+	name := fmt.Sprintf("uow_%d", state.depth+1)
+	if err := tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	panicked := true
+	defer func() {
+		if panicked || err != nil {
+			tx.RollbackTo(name)
+		}
+	}()
+
+	err = run(ctx, txState{tx: state.tx, depth: state.depth + 1}, fn)
+	panicked = false
+	if err != nil {
+		return err
+	}
+	return tx.Exec("RELEASE SAVEPOINT " + name).Error
+}
+
+func run(ctx context.Context, state txState, fn func(ctx context.Context, repos TxRepositories) error) error {
+	return fn(context.WithValue(ctx, txKey{}, state), TxRepositories{
+		Tasks: NewTaskRepository(state.tx),
+		Users: NewUserRepository(state.tx),
+	})
+}
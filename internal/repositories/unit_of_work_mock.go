@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+)
+
+// NOTE - ไม่มี transaction จริง เรียก fn ทันทีด้วย repository mock ชุดเดิม
+// test ที่ตั้ง .On ไว้กับ TaskRepositoryMock/UserRepositoryMock ใช้ต่อได้เลย
+type UnitOfWorkMock struct {
+	tasks TaskRepositoryInterface
+	users UserRepositoryInterface
+}
+
+func NewUnitOfWorkMock(tasks TaskRepositoryInterface, users UserRepositoryInterface) *UnitOfWorkMock {
+	return &UnitOfWorkMock{tasks: tasks, users: users}
+}
+
+func (m *UnitOfWorkMock) Do(ctx context.Context, fn func(ctx context.Context, repos TxRepositories) error) error {
+	return fn(ctx, TxRepositories{Tasks: m.tasks, Users: m.users})
+}
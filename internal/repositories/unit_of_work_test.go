@@ -0,0 +1,187 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NOTE - SQLite ":memory:" ใช้ได้ทั้ง transaction และ savepoint เหมือน Postgres ไม่ต้องมี DB จริง
+// connection เดียวเท่านั้น ไม่งั้นแต่ละ connection ได้ DB คนละก้อน
+func newUnitOfWork(t *testing.T) (*repositories.UnitOfWork, *repositories.UserRepository) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=1"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&models.Users{}, &models.Tasks{}))
+
+	return repositories.NewUnitOfWork(db), repositories.NewUserRepository(db)
+}
+
+func createInTx(ctx context.Context, repos repositories.TxRepositories, email string) error {
+	return repos.Users.CreateUser(ctx, &models.Users{Email: email, Name: email, Password: "hash"})
+}
+
+func assertUsers(t *testing.T, users repositories.UserRepositoryInterface, exists map[string]bool) {
+	t.Helper()
+	for email, want := range exists {
+		user, err := users.FindByEmail(context.Background(), email)
+		require.NoError(t, err)
+		assert.Equal(t, want, user != nil, email)
+	}
+}
+
+func TestUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Commit when fn succeeds", func(t *testing.T) {
+		uow, users := newUnitOfWork(t)
+
+		err := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+			if err := createInTx(ctx, repos, "a@example.com"); err != nil {
+				return err
+			}
+			user, err := repos.Users.FindByEmail(ctx, "a@example.com")
+			if err != nil {
+				return err
+			}
+			return repos.Tasks.CreateTask(ctx, &models.Tasks{Title: "Title", UserID: user.ID})
+		})
+
+		require.NoError(t, err)
+		assertUsers(t, users, map[string]bool{"a@example.com": true})
+	})
+
+	t.Run("Rollback on error", func(t *testing.T) {
+		uow, users := newUnitOfWork(t)
+		failure := errors.New("boom")
+
+		err := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+			if err := createInTx(ctx, repos, "a@example.com"); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		assert.ErrorIs(t, err, failure)
+		assertUsers(t, users, map[string]bool{"a@example.com": false})
+	})
+
+	t.Run("Rollback on panic and re-panic", func(t *testing.T) {
+		uow, users := newUnitOfWork(t)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+				if err := createInTx(ctx, repos, "a@example.com"); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		})
+
+		assertUsers(t, users, map[string]bool{"a@example.com": false})
+	})
+
+	t.Run("Nested error rolls back only the savepoint", func(t *testing.T) {
+		uow, users := newUnitOfWork(t)
+		failure := errors.New("boom")
+
+		err := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+			if err := createInTx(ctx, repos, "outer@example.com"); err != nil {
+				return err
+			}
+
+			innerErr := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+				if err := createInTx(ctx, repos, "inner@example.com"); err != nil {
+					return err
+				}
+				return failure
+			})
+			assert.ErrorIs(t, innerErr, failure)
+
+			return createInTx(ctx, repos, "after@example.com")
+		})
+
+		require.NoError(t, err)
+		assertUsers(t, users, map[string]bool{"outer@example.com": true, "inner@example.com": false, "after@example.com": true})
+	})
+
+	t.Run("Nested panic rolls back the savepoint and the outer transaction", func(t *testing.T) {
+		uow, users := newUnitOfWork(t)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+				if err := createInTx(ctx, repos, "outer@example.com"); err != nil {
+					return err
+				}
+				return uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+					if err := createInTx(ctx, repos, "inner@example.com"); err != nil {
+						return err
+					}
+					panic("boom")
+				})
+			})
+		})
+
+		assertUsers(t, users, map[string]bool{"outer@example.com": false, "inner@example.com": false})
+	})
+
+	t.Run("Outer error rolls back committed savepoints at every depth", func(t *testing.T) {
+		uow, users := newUnitOfWork(t)
+		failure := errors.New("boom")
+
+		err := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+			err := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+				if err := createInTx(ctx, repos, "level1@example.com"); err != nil {
+					return err
+				}
+				return uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+					return createInTx(ctx, repos, "level2@example.com")
+				})
+			})
+			if err != nil {
+				return err
+			}
+			return failure
+		})
+
+		assert.ErrorIs(t, err, failure)
+		assertUsers(t, users, map[string]bool{"level1@example.com": false, "level2@example.com": false})
+	})
+
+	t.Run("Inner failure after a sibling savepoint keeps the sibling", func(t *testing.T) {
+		uow, users := newUnitOfWork(t)
+
+		err := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+			if err := uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+				return createInTx(ctx, repos, "first@example.com")
+			}); err != nil {
+				return err
+			}
+
+			// NOTE - ชื่อ savepoint ซ้ำกับตัวแรก (uow_1) ต้อง rollback แค่ของตัวเอง
+			uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+				if err := createInTx(ctx, repos, "second@example.com"); err != nil {
+					return err
+				}
+				return errors.New("boom")
+			})
+			return nil
+		})
+
+		require.NoError(t, err)
+		assertUsers(t, users, map[string]bool{"first@example.com": true, "second@example.com": false})
+	})
+}
@@ -1,20 +1,27 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/Beluga-Whale/management-api/internal/models"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type UserRepositoryInterface interface {
-	CreateUser(user *models.Users) error
-	FindByEmail(email string) (*models.Users, error)
-	FindUserById(idStr string) (*models.Users, error)
-	UpdateUserById(updatedUserValue *models.Users, userID uint) error
+	CreateUser(ctx context.Context, user *models.Users) error
+	CreateUsers(ctx context.Context, users []models.Users, batchSize int) error
+	FindByEmail(ctx context.Context, email string) (*models.Users, error)
+	FindUserById(ctx context.Context, idStr string) (*models.Users, error)
+	UpdateUserById(ctx context.Context, updatedUserValue *models.Users, userID uint) error
+	UpdatePassword(ctx context.Context, userID uint, hashedPassword string) error
+	UpdateTwoFactor(ctx context.Context, userID uint, secret string, enabled bool) error
+	UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	UpdateRole(ctx context.Context, userID uint, role models.Role) error
+	UpdateDisabled(ctx context.Context, userID uint, disabledAt *time.Time) error
 }
 
 type UserRepository struct {
@@ -25,22 +32,20 @@ func NewUserRepository(db *gorm.DB) *UserRepository{
 	return &UserRepository{db:db}
 }
 
-func (repo *UserRepository) CreateUser(user *models.Users)error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-
-	if err != nil {
-		return err
-	}
-
-	user.Password = string(hashedPassword)
+func (repo *UserRepository) CreateUser(ctx context.Context, user *models.Users)error {
+	// NOTE - Password ต้องถูก hash มาจาก service แล้ว
+	return repo.db.WithContext(ctx).Create(user).Error
+}
 
-	return repo.db.Create(user).Error
+// NOTE - insert ทีละ batchSize แถวต่อ statement ID ถูกเติมกลับเข้า slice
+func (repo *UserRepository) CreateUsers(ctx context.Context, users []models.Users, batchSize int) error {
+	return repo.db.WithContext(ctx).CreateInBatches(users, batchSize).Error
 }
 
-func (repo *UserRepository) FindByEmail(email string) (*models.Users, error ){
+func (repo *UserRepository) FindByEmail(ctx context.Context, email string) (*models.Users, error ){
 	var user models.Users
 	
-	result := repo.db.Where("email = ?", email).First(&user)
+	result := repo.db.WithContext(ctx).Where("email = ?", email).First(&user)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound{
@@ -53,7 +58,7 @@ func (repo *UserRepository) FindByEmail(email string) (*models.Users, error ){
 
 }
 
-func (repo *UserRepository) FindUserById(idStr string) (*models.Users, error ){
+func (repo *UserRepository) FindUserById(ctx context.Context, idStr string) (*models.Users, error ){
 	var user models.Users
 	id,err :=  strconv.Atoi(idStr)
 
@@ -61,7 +66,7 @@ func (repo *UserRepository) FindUserById(idStr string) (*models.Users, error ){
 		return nil,errors.New("Invalid Task ID fomat")
 	}
 
-	result := repo.db.First(&user, id)
+	result := repo.db.WithContext(ctx).First(&user, id)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -70,10 +75,10 @@ func (repo *UserRepository) FindUserById(idStr string) (*models.Users, error ){
 	return &user,nil
 }
 
-func (repo *UserRepository) UpdateUserById(updatedUserValue *models.Users, userID uint) error {
+func (repo *UserRepository) UpdateUserById(ctx context.Context, updatedUserValue *models.Users, userID uint) error {
 	var user models.Users
 
-	result := repo.db.First(&user, userID)
+	result := repo.db.WithContext(ctx).First(&user, userID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return fmt.Errorf("Can't find user by ID: %w", result.Error)
@@ -82,9 +87,86 @@ func (repo *UserRepository) UpdateUserById(updatedUserValue *models.Users, userI
 	}
 
 	// NOTE - Update task 
-	if err:= repo.db.Model(&user).Updates(updatedUserValue).Error; err != nil {
+	if err:= repo.db.WithContext(ctx).Model(&user).Updates(updatedUserValue).Error; err != nil {
 		return fmt.Errorf("Failed to update user: %w",err)
 	} 
 
 	return nil
 }
+
+func (repo *UserRepository) UpdatePassword(ctx context.Context, userID uint, hashedPassword string) error {
+	result := repo.db.WithContext(ctx).Model(&models.Users{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password":            hashedPassword,
+		"password_changed_at": time.Now(),
+	})
+
+	if result.Error != nil {
+		return fmt.Errorf("Failed to update password: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Can't find user by ID: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (repo *UserRepository) UpdateTwoFactor(ctx context.Context, userID uint, secret string, enabled bool) error {
+	// NOTE - ใช้ map เพราะ Updates แบบ struct จะข้าม false กับ "" ไป
+	result := repo.db.WithContext(ctx).Model(&models.Users{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"two_factor_secret":  secret,
+		"two_factor_enabled": enabled,
+	})
+
+	if result.Error != nil {
+		return fmt.Errorf("Failed to update two factor: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Can't find user by ID: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// NOTE - update แบบมีเงื่อนไขใน statement เดียว request ที่ส่ง code เดียวกันมาพร้อมกันจะผ่านได้แค่ตัวเดียว
+func (repo *UserRepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	result := repo.db.WithContext(ctx).Model(&models.Users{}).
+		Where("id = ? AND two_factor_last_step < ?", userID, step).
+		Update("two_factor_last_step", step)
+
+	if result.Error != nil {
+		return false, fmt.Errorf("Failed to use TOTP code: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (repo *UserRepository) UpdateRole(ctx context.Context, userID uint, role models.Role) error {
+	result := repo.db.WithContext(ctx).Model(&models.Users{}).Where("id = ?", userID).Update("role", role)
+
+	if result.Error != nil {
+		return fmt.Errorf("Failed to update role: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Can't find user by ID: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// NOTE - disabledAt เป็น nil = เปิดบัญชีกลับ
+func (repo *UserRepository) UpdateDisabled(ctx context.Context, userID uint, disabledAt *time.Time) error {
+	result := repo.db.WithContext(ctx).Model(&models.Users{}).Where("id = ?", userID).Update("disabled_at", disabledAt)
+
+	if result.Error != nil {
+		return fmt.Errorf("Failed to update disabled: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Can't find user by ID: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// NOTE - ค่า default ของ column photo ใน Users ต้องตรงกับ tag ของ model
+const defaultUserPhoto = "https://images.unsplash.com/photo-1438761681033-6461ffad8d80?q=80&w=2070&auto=format&fit=crop&ixlib=rb-4.0.3&ixid=M3wxMjA3fDB8MHxwaG90by1wYWdlfHx8fGVufDB8fHx8fA%3D%3D"
+
+// NOTE - ทำตัวเหมือน UserRepository (unique email, default ของ column, check constraint ของ role)
+type UserMemoryRepository struct {
+	mu     sync.Mutex
+	users  map[uint]models.Users
+	nextID uint
+}
+
+func NewUserMemoryRepository() *UserMemoryRepository {
+	return &UserMemoryRepository{users: map[uint]models.Users{}}
+}
+
+func (repo *UserMemoryRepository) CreateUser(ctx context.Context, user *models.Users) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if user.Role == "" {
+		user.Role = models.User
+	}
+	if user.Photo == "" {
+		user.Photo = defaultUserPhoto
+	}
+	if err := checkRole(user.Role); err != nil {
+		return err
+	}
+	for _, existing := range repo.users {
+		if existing.Email == user.Email {
+			return fmt.Errorf("Duplicate email: %s", user.Email)
+		}
+	}
+
+	if user.ID == 0 {
+		repo.nextID++
+		user.ID = repo.nextID
+	} else if _, ok := repo.users[user.ID]; ok {
+		return fmt.Errorf("Duplicate user ID: %d", user.ID)
+	} else if user.ID > repo.nextID {
+		repo.nextID = user.ID
+	}
+
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+
+	stored := *user
+	stored.Tasks = nil
+	repo.users[user.ID] = stored
+	return nil
+}
+
+// NOTE - ไม่มี statement ให้รวม batchSize เลยไม่มีผล
+func (repo *UserMemoryRepository) CreateUsers(ctx context.Context, users []models.Users, batchSize int) error {
+	for i := range users {
+		if err := repo.CreateUser(ctx, &users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *UserMemoryRepository) FindByEmail(ctx context.Context, email string) (*models.Users, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, user := range repo.users {
+		if !user.DeletedAt.Valid && user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *UserMemoryRepository) FindUserById(ctx context.Context, idStr string) (*models.Users, error) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return nil, errors.New("Invalid Task ID fomat")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.live(uint(id))
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+// NOTE - เหมือน Updates แบบ struct ของ GORM ทับเฉพาะ field ที่ไม่ใช่ zero value
+func (repo *UserMemoryRepository) UpdateUserById(ctx context.Context, updatedUserValue *models.Users, userID uint) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.live(userID)
+	if !ok {
+		return fmt.Errorf("Can't find user by ID: %w", gorm.ErrRecordNotFound)
+	}
+
+	if updatedUserValue.Email != "" && updatedUserValue.Email != user.Email {
+		for id, existing := range repo.users {
+			if id != userID && existing.Email == updatedUserValue.Email {
+				return fmt.Errorf("Failed to update user: Duplicate email: %s", updatedUserValue.Email)
+			}
+		}
+		user.Email = updatedUserValue.Email
+	}
+	if updatedUserValue.Name != "" {
+		user.Name = updatedUserValue.Name
+	}
+	if updatedUserValue.Password != "" {
+		user.Password = updatedUserValue.Password
+	}
+	if updatedUserValue.Photo != "" {
+		user.Photo = updatedUserValue.Photo
+	}
+	if updatedUserValue.Bio != "" {
+		user.Bio = updatedUserValue.Bio
+	}
+	if updatedUserValue.Role != "" {
+		if err := checkRole(updatedUserValue.Role); err != nil {
+			return fmt.Errorf("Failed to update user: %w", err)
+		}
+		user.Role = updatedUserValue.Role
+	}
+	if updatedUserValue.PasswordChangedAt != nil {
+		user.PasswordChangedAt = updatedUserValue.PasswordChangedAt
+	}
+	if updatedUserValue.TwoFactorEnabled {
+		user.TwoFactorEnabled = true
+	}
+	if updatedUserValue.TwoFactorSecret != "" {
+		user.TwoFactorSecret = updatedUserValue.TwoFactorSecret
+	}
+	user.UpdatedAt = time.Now()
+
+	repo.users[userID] = user
+	return nil
+}
+
+func (repo *UserMemoryRepository) UpdatePassword(ctx context.Context, userID uint, hashedPassword string) error {
+	return repo.update(userID, func(user *models.Users) error {
+		now := time.Now()
+		user.Password = hashedPassword
+		user.PasswordChangedAt = &now
+		return nil
+	})
+}
+
+func (repo *UserMemoryRepository) UpdateTwoFactor(ctx context.Context, userID uint, secret string, enabled bool) error {
+	return repo.update(userID, func(user *models.Users) error {
+		user.TwoFactorSecret = secret
+		user.TwoFactorEnabled = enabled
+		return nil
+	})
+}
+
+func (repo *UserMemoryRepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	used := false
+	err := repo.update(userID, func(user *models.Users) error {
+		if user.TwoFactorLastStep < step {
+			user.TwoFactorLastStep = step
+			used = true
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return used, err
+}
+
+func (repo *UserMemoryRepository) UpdateRole(ctx context.Context, userID uint, role models.Role) error {
+	return repo.update(userID, func(user *models.Users) error {
+		if err := checkRole(role); err != nil {
+			return fmt.Errorf("Failed to update role: %w", err)
+		}
+		user.Role = role
+		return nil
+	})
+}
+
+func (repo *UserMemoryRepository) UpdateDisabled(ctx context.Context, userID uint, disabledAt *time.Time) error {
+	return repo.update(userID, func(user *models.Users) error {
+		user.DisabledAt = disabledAt
+		return nil
+	})
+}
+
+func (repo *UserMemoryRepository) update(userID uint, apply func(user *models.Users) error) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.live(userID)
+	if !ok {
+		return fmt.Errorf("Can't find user by ID: %w", gorm.ErrRecordNotFound)
+	}
+	if err := apply(&user); err != nil {
+		return err
+	}
+	user.UpdatedAt = time.Now()
+
+	repo.users[userID] = user
+	return nil
+}
+
+func (repo *UserMemoryRepository) live(id uint) (models.Users, bool) {
+	user, ok := repo.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return models.Users{}, false
+	}
+	return user, true
+}
+
+// NOTE - แทน check constraint chk_users_role ของ DB
+func checkRole(role models.Role) error {
+	switch role {
+	case models.Admin, models.User:
+		return nil
+	default:
+		return fmt.Errorf("Invalid user role: %s", role)
+	}
+}
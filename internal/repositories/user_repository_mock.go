@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,13 +16,18 @@ func NewUserRepositoryMock() *UserRepositoryMock{
 	return &UserRepositoryMock{}
 }
 
-func (m *UserRepositoryMock) CreateUser(user *models.Users)error{
-	args :=m.Called(user)
+func (m *UserRepositoryMock) CreateUser(ctx context.Context, user *models.Users)error{
+	args :=m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *UserRepositoryMock) FindByEmail(email string) (*models.Users, error ){
-	args :=m.Called(email)
+func (m *UserRepositoryMock) CreateUsers(ctx context.Context, users []models.Users, batchSize int) error {
+	args :=m.Called(ctx, users, batchSize)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) FindByEmail(ctx context.Context, email string) (*models.Users, error ){
+	args :=m.Called(ctx, email)
 
 	// NOTE- ต้องเช็คว่า args.Get(0) เป็น nil ไหม ไม่งั้นมันจะ panic
 	if user, ok := args.Get(0).(*models.Users); ok {
@@ -29,8 +37,8 @@ func (m *UserRepositoryMock) FindByEmail(email string) (*models.Users, error ){
 	return nil, args.Error(1)
 }
 
-func (m *UserRepositoryMock)FindUserById(idStr string) (*models.Users, error ){
-	args :=m.Called(idStr)
+func (m *UserRepositoryMock)FindUserById(ctx context.Context, idStr string) (*models.Users, error ){
+	args :=m.Called(ctx, idStr)
 
 	if user,ok := args.Get(0).(*models.Users); ok{
 		return user, args.Error(1)
@@ -39,8 +47,32 @@ func (m *UserRepositoryMock)FindUserById(idStr string) (*models.Users, error ){
 	return  nil,args.Error(1)
 }
 
-func (m *UserRepositoryMock) UpdateUserById(updatedUserValue *models.Users, userID uint) error{
-	args :=m.Called(updatedUserValue,userID)
+func (m *UserRepositoryMock) UpdateUserById(ctx context.Context, updatedUserValue *models.Users, userID uint) error{
+	args :=m.Called(ctx, updatedUserValue,userID)
 	return args.Error(0)
 }
 
+func (m *UserRepositoryMock) UpdatePassword(ctx context.Context, userID uint, hashedPassword string) error {
+	args :=m.Called(ctx, userID,hashedPassword)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) UpdateTwoFactor(ctx context.Context, userID uint, secret string, enabled bool) error {
+	args :=m.Called(ctx, userID,secret,enabled)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	args :=m.Called(ctx, userID,step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *UserRepositoryMock) UpdateRole(ctx context.Context, userID uint, role models.Role) error {
+	args :=m.Called(ctx, userID,role)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) UpdateDisabled(ctx context.Context, userID uint, disabledAt *time.Time) error {
+	args :=m.Called(ctx, userID,disabledAt)
+	return args.Error(0)
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type WebAuthnRepositoryInterface interface {
+	CreateCredential(ctx context.Context, credential *models.WebAuthnCredentials) error
+	FindCredentialsByUserId(ctx context.Context, userID uint) ([]models.WebAuthnCredentials, error)
+	FindCredentialById(ctx context.Context, idStr string) (*models.WebAuthnCredentials, error)
+	UpdateCredentialUsage(ctx context.Context, id uint, signCount uint32, cloneWarning bool, backupState bool) error
+	RenameCredential(ctx context.Context, id uint, name string) error
+	DeleteCredentialById(ctx context.Context, id uint) error
+	CreateSession(ctx context.Context, session *models.WebAuthnSessions) error
+	ConsumeSession(ctx context.Context, sessionKey string) (*models.WebAuthnSessions, error)
+}
+
+type WebAuthnRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnRepository(db *gorm.DB) *WebAuthnRepository {
+	return &WebAuthnRepository{db: db}
+}
+
+func (repo *WebAuthnRepository) CreateCredential(ctx context.Context, credential *models.WebAuthnCredentials) error {
+	return repo.db.WithContext(ctx).Create(credential).Error
+}
+
+func (repo *WebAuthnRepository) FindCredentialsByUserId(ctx context.Context, userID uint) ([]models.WebAuthnCredentials, error) {
+	var credentials []models.WebAuthnCredentials
+
+	if err := repo.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (repo *WebAuthnRepository) FindCredentialById(ctx context.Context, idStr string) (*models.WebAuthnCredentials, error) {
+	var credential models.WebAuthnCredentials
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return nil, errors.New("Invalid Credential ID fomat")
+	}
+
+	if err := repo.db.WithContext(ctx).First(&credential, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+func (repo *WebAuthnRepository) UpdateCredentialUsage(ctx context.Context, id uint, signCount uint32, cloneWarning bool, backupState bool) error {
+	err := repo.db.WithContext(ctx).Model(&models.WebAuthnCredentials{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"sign_count":    signCount,
+		"clone_warning": cloneWarning,
+		"backup_state":  backupState,
+		"last_used_at":  time.Now(),
+	}).Error
+
+	if err != nil {
+		return fmt.Errorf("Failed to update credential: %w", err)
+	}
+	return nil
+}
+
+func (repo *WebAuthnRepository) RenameCredential(ctx context.Context, id uint, name string) error {
+	return repo.db.WithContext(ctx).Model(&models.WebAuthnCredentials{}).Where("id = ?", id).Update("name", name).Error
+}
+
+func (repo *WebAuthnRepository) DeleteCredentialById(ctx context.Context, id uint) error {
+	return repo.db.WithContext(ctx).Delete(&models.WebAuthnCredentials{}, id).Error
+}
+
+func (repo *WebAuthnRepository) CreateSession(ctx context.Context, session *models.WebAuthnSessions) error {
+	return repo.db.WithContext(ctx).Create(session).Error
+}
+
+// NOTE - ดึงแล้วลบทิ้งเลย challenge ใช้ซ้ำไม่ได้
+func (repo *WebAuthnRepository) ConsumeSession(ctx context.Context, sessionKey string) (*models.WebAuthnSessions, error) {
+	var session models.WebAuthnSessions
+
+	result := repo.db.WithContext(ctx).Where("session_key = ? AND expires_at > ?", sessionKey, time.Now()).First(&session)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	deleted := repo.db.WithContext(ctx).Unscoped().Where("id = ?", session.ID).Delete(&models.WebAuthnSessions{})
+	if deleted.Error != nil {
+		return nil, deleted.Error
+	}
+
+	// NOTE - มีอีก request ใช้ session นี้ไปก่อนแล้ว
+	if deleted.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &session, nil
+}
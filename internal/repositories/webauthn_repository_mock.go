@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebAuthnRepositoryMock struct {
+	mock.Mock
+}
+
+func NewWebAuthnRepositoryMock() *WebAuthnRepositoryMock {
+	return &WebAuthnRepositoryMock{}
+}
+
+func (m *WebAuthnRepositoryMock) CreateCredential(ctx context.Context, credential *models.WebAuthnCredentials) error {
+	args := m.Called(ctx, credential)
+	return args.Error(0)
+}
+
+func (m *WebAuthnRepositoryMock) FindCredentialsByUserId(ctx context.Context, userID uint) ([]models.WebAuthnCredentials, error) {
+	args := m.Called(ctx, userID)
+	if credentials, ok := args.Get(0).([]models.WebAuthnCredentials); ok {
+		return credentials, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebAuthnRepositoryMock) FindCredentialById(ctx context.Context, idStr string) (*models.WebAuthnCredentials, error) {
+	args := m.Called(ctx, idStr)
+	if credential, ok := args.Get(0).(*models.WebAuthnCredentials); ok {
+		return credential, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebAuthnRepositoryMock) UpdateCredentialUsage(ctx context.Context, id uint, signCount uint32, cloneWarning bool, backupState bool) error {
+	args := m.Called(ctx, id, signCount, cloneWarning, backupState)
+	return args.Error(0)
+}
+
+func (m *WebAuthnRepositoryMock) RenameCredential(ctx context.Context, id uint, name string) error {
+	args := m.Called(ctx, id, name)
+	return args.Error(0)
+}
+
+func (m *WebAuthnRepositoryMock) DeleteCredentialById(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *WebAuthnRepositoryMock) CreateSession(ctx context.Context, session *models.WebAuthnSessions) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *WebAuthnRepositoryMock) ConsumeSession(ctx context.Context, sessionKey string) (*models.WebAuthnSessions, error) {
+	args := m.Called(ctx, sessionKey)
+	if session, ok := args.Get(0).(*models.WebAuthnSessions); ok {
+		return session, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -3,30 +3,123 @@ package routes
 import (
 	"github.com/Beluga-Whale/management-api/internal/handlers"
 	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App, userHandler *handlers.UserHandler, taskHandler *handlers.TaskHandler ){
+type Handlers struct {
+	User        *handlers.UserHandler
+	Task        *handlers.TaskHandler
+	Password    *handlers.PasswordHandler
+	TwoFactor   *handlers.TwoFactorHandler
+	WebAuthn    *handlers.WebAuthnHandler
+	AccessToken *handlers.AccessTokenHandler
+	JWKS        *handlers.JWKSHandler
+	OIDC        *handlers.OIDCHandler
+	MagicLink   *handlers.MagicLinkHandler
+	Session     *handlers.SessionHandler
+	Admin       *handlers.AdminHandler
+	CSRF        *handlers.CSRFHandler
+	Health      *handlers.HealthHandler
+	Metrics     fiber.Handler
+}
+
+type Middlewares struct {
+	Auth fiber.Handler
+	// NOTE - rate limit ของ route login/สมัคร/ลืมรหัส นับตาม IP
+	AuthRateLimit fiber.Handler
+	// NOTE - rate limit ของ route ที่ต้อง login ใส่ทั้งก่อน auth (นับตาม IP) และหลัง auth (นับตาม user)
+	APIRateLimit fiber.Handler
+	CSRF         fiber.Handler
+}
+
+func SetupRoutes(app *fiber.App, h Handlers, m Middlewares){
+	userHandler := h.User
+	taskHandler := h.Task
+	passwordHandler := h.Password
+	twoFactorHandler := h.TwoFactor
+	webAuthnHandler := h.WebAuthn
+	accessTokenHandler := h.AccessToken
+	authLimit := m.AuthRateLimit
+
+	// NOTE - probe ของ orchestrator ไม่ต้อง login และไม่ผ่าน rate limit/CSRF
+	app.Get("/healthz", h.Health.Healthz)
+	app.Get("/readyz", h.Health.Readyz)
+	app.Get("/version", h.Health.Version)
+	app.Get("/metrics", h.Metrics)
+
+	app.Get("/.well-known/jwks.json", h.JWKS.GetJWKS)
+
 	api := app.Group("/api")
-	api.Post("/user/register", userHandler.RegisterUser)
-	api.Post("/user/login", userHandler.Login)
+	// NOTE - ทุก route ที่แก้ข้อมูลต้องส่ง X-CSRF-Token ถ้ายืนยันตัวด้วย cookie
+	api.Use(m.CSRF)
+	api.Get("/csrf", h.CSRF.GetToken)
+	api.Post("/user/register", authLimit, userHandler.RegisterUser)
+	api.Post("/user/login", authLimit, userHandler.Login)
+	api.Post("/user/login/mfa", authLimit, twoFactorHandler.LoginMFA)
+	api.Post("/user/login/webauthn/begin", authLimit, webAuthnHandler.BeginLogin)
+	api.Post("/user/login/webauthn/finish", authLimit, webAuthnHandler.FinishLogin)
+	api.Post("/user/login/magic", authLimit, h.MagicLink.RequestLink)
+	api.Get("/user/login/magic/callback", authLimit, h.MagicLink.Callback)
+	api.Get("/user/oidc/:provider/login", authLimit, h.OIDC.Login)
+	api.Get("/user/oidc/:provider/callback", authLimit, h.OIDC.Callback)
 	api.Post("/user/logout", userHandler.Logout)
+	api.Post("/user/password/forgot", authLimit, passwordHandler.ForgotPassword)
+	api.Post("/user/password/reset", authLimit, passwordHandler.ResetPassword)
+
+	// NOTE - นับตาม IP ก่อนเช็ค token ไม่งั้น request ที่ไม่ได้ login หรือส่ง token มั่วจะยิง DB ได้ไม่จำกัด
+	api.Use(m.APIRateLimit)
 
 	// NOTE - Protect routes by authMiddleware
-	api.Use(middleware.AuthMiddleware)
+	api.Use(m.Auth)
+	api.Use(m.APIRateLimit)
+
+	// NOTE - personal access token ใช้ได้เฉพาะ route ที่มี scope ตรง
+	tasksRead := middleware.RequireScope(services.ScopeTasksRead)
+	tasksWrite := middleware.RequireScope(services.ScopeTasksWrite)
+	userRead := middleware.RequireScope(services.ScopeUserRead)
+	userWrite := middleware.RequireScope(services.ScopeUserWrite)
+	session := middleware.RequireSession()
 
 	// NOTE - Task routes
-	api.Post("/task", taskHandler.CreateTask)
-	api.Get("/task", taskHandler.GetAllTask)
-	api.Get("/task/complete", taskHandler.GetCompleteTask)
-	api.Get("/task/pending", taskHandler.GetPendingTask)
-	api.Get("/task/overdue", taskHandler.GetOverdueTask)
+	api.Post("/task", tasksWrite, taskHandler.CreateTask)
+	api.Get("/task", tasksRead, taskHandler.GetAllTask)
+	api.Get("/task/complete", tasksRead, taskHandler.GetCompleteTask)
+	api.Get("/task/pending", tasksRead, taskHandler.GetPendingTask)
+	api.Get("/task/overdue", tasksRead, taskHandler.GetOverdueTask)
 	
-	api.Get("/task/:id", taskHandler.FindTaskById)
-	api.Put("/task/:id",taskHandler.UpdateTask)
-	api.Delete("task/:id", taskHandler.DeleteTask)
+	api.Get("/task/:id", tasksRead, taskHandler.FindTaskById)
+	api.Put("/task/:id", tasksWrite, taskHandler.UpdateTask)
+	api.Delete("task/:id", tasksWrite, taskHandler.DeleteTask)
 
 	// NOTE - User routes
-	api.Get("/user", userHandler.GetUser)
-	api.Put("/user/:id", userHandler.EditUser)
+	api.Get("/user", userRead, userHandler.GetUser)
+	api.Put("/user/:id", userWrite, userHandler.EditUser)
+	api.Post("/user/password/change", session, passwordHandler.ChangePassword)
+	api.Post("/user/2fa/enroll", session, twoFactorHandler.Enroll)
+	api.Post("/user/2fa/verify", session, twoFactorHandler.Verify)
+	api.Post("/user/2fa/disable", session, twoFactorHandler.Disable)
+	api.Post("/user/webauthn/register/begin", session, webAuthnHandler.BeginRegistration)
+	api.Post("/user/webauthn/register/finish", session, webAuthnHandler.FinishRegistration)
+	api.Get("/user/webauthn/credentials", session, webAuthnHandler.GetCredentials)
+	api.Put("/user/webauthn/credentials/:id", session, webAuthnHandler.RenameCredential)
+	api.Delete("/user/webauthn/credentials/:id", session, webAuthnHandler.DeleteCredential)
+
+	// NOTE - Personal access token routes
+	api.Post("/user/tokens", session, accessTokenHandler.CreateToken)
+	api.Get("/user/tokens", session, accessTokenHandler.GetTokens)
+	api.Delete("/user/tokens/:id", session, accessTokenHandler.RevokeToken)
+
+	// NOTE - Session routes
+	api.Get("/user/sessions", session, h.Session.GetSessions)
+	// NOTE - logout ทุกเครื่องยกเว้นเครื่องนี้
+	api.Delete("/user/sessions", session, h.Session.RevokeOtherSessions)
+	api.Delete("/user/sessions/:id", session, h.Session.RevokeSession)
+
+	// NOTE - Admin routes ต้อง login แบบ interactive และเป็น admin
+	admin := api.Group("/admin", session, middleware.RequireRole(models.Admin))
+	admin.Get("/audit", h.Admin.GetAuditLogs)
+	admin.Get("/audit/export", h.Admin.ExportAuditLogs)
+	admin.Put("/users/:id/role", h.Admin.UpdateUserRole)
 }
\ No newline at end of file
@@ -0,0 +1,43 @@
+package routes_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/routes"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtectedRoutesRateLimit(t *testing.T) {
+	t.Run("Unauthenticated requests are limited by IP before auth", func(t *testing.T) {
+		authCalls := 0
+		app := fiber.New()
+		routes.SetupRoutes(app, routes.Handlers{}, routes.Middlewares{
+			Auth: func(c *fiber.Ctx) error {
+				authCalls++
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Unauthorized"})
+			},
+			AuthRateLimit: func(c *fiber.Ctx) error { return c.Next() },
+			APIRateLimit:  middleware.NewRateLimiter(repositories.NewRateLimitMemoryRepository(), "api", utils.RateLimit{Requests: 2, Per: time.Minute}),
+			CSRF:          func(c *fiber.Ctx) error { return c.Next() },
+		})
+
+		statuses := []int{}
+		for range 3 {
+			req := httptest.NewRequest("GET", "/api/task", nil)
+			req.Header.Set("Authorization", "Bearer bt_pat_fake")
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			statuses = append(statuses, res.StatusCode)
+		}
+
+		assert.Equal(t, []int{fiber.StatusUnauthorized, fiber.StatusUnauthorized, fiber.StatusTooManyRequests}, statuses)
+		assert.Equal(t, 2, authCalls)
+	})
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeUserRead   = "user:read"
+	ScopeUserWrite  = "user:write"
+
+	// NOTE - prefix ทำให้แยก PAT ออกจาก JWT ได้ และ secret scanner จับได้ถ้าหลุดไปใน repo
+	AccessTokenPrefix = "bt_pat_"
+
+	defaultAccessTokenDays = 30
+	maxAccessTokenDays     = 365
+	lastUsedUpdateInterval = time.Minute
+)
+
+var validScopes = map[string]bool{
+	ScopeTasksRead:  true,
+	ScopeTasksWrite: true,
+	ScopeUserRead:   true,
+	ScopeUserWrite:  true,
+}
+
+type AccessTokenServiceInterface interface {
+	CreateToken(ctx context.Context, email string, name string, scopes []string, expiresInDays int, client ClientInfo) (string, *models.AccessTokens, error)
+	GetTokens(ctx context.Context, email string) ([]models.AccessTokens, error)
+	RevokeToken(ctx context.Context, email string, idStr string) error
+	Authenticate(ctx context.Context, token string, ip string) (*models.Users, *models.AccessTokens, error)
+}
+
+type AccessTokenService struct {
+	userRepo        repositories.UserRepositoryInterface
+	accessTokenRepo repositories.AccessTokenRepositoryInterface
+	auditService    AuditServiceInterface
+}
+
+func NewAccessTokenService(userRepo repositories.UserRepositoryInterface, accessTokenRepo repositories.AccessTokenRepositoryInterface, auditService AuditServiceInterface) *AccessTokenService {
+	return &AccessTokenService{userRepo: userRepo, accessTokenRepo: accessTokenRepo, auditService: auditService}
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("At least one scope is required")
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !validScopes[scope] {
+			return nil, fmt.Errorf("Invalid scope: %s", scope)
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+
+	return result, nil
+}
+
+// NOTE - token จริงแสดงให้ user เห็นครั้งเดียวตอนสร้าง ใน DB เก็บแค่ hash
+func (s *AccessTokenService) CreateToken(ctx context.Context, email string, name string, scopes []string, expiresInDays int, client ClientInfo) (string, *models.AccessTokens, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("Name is required")
+	}
+
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = defaultAccessTokenDays
+	}
+	if expiresInDays < 0 || expiresInDays > maxAccessTokenDays {
+		return "", nil, fmt.Errorf("Expiration must be between 1 and %d days", maxAccessTokenDays)
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return "", nil, errors.New("User not found")
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to generate token: %w", err)
+	}
+	token := AccessTokenPrefix + secret
+
+	accessToken := &models.AccessTokens{
+		UserID:      user.ID,
+		Name:        name,
+		TokenPrefix: token[:len(AccessTokenPrefix)+8],
+		TokenHash:   utils.HashToken(token),
+		Scopes:      strings.Join(normalized, " "),
+		ExpiresAt:   time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
+	}
+
+	if err := s.accessTokenRepo.CreateAccessToken(ctx, accessToken); err != nil {
+		return "", nil, fmt.Errorf("Failed to create access token: %w", err)
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:     models.AuditTokenCreate,
+		ActorID:    user.ID,
+		ActorEmail: user.Email,
+		TargetID:   accessToken.ID,
+		Client:     client,
+		Metadata: models.AuditMetadata{
+			"name":         accessToken.Name,
+			"scopes":       accessToken.Scopes,
+			"token_prefix": accessToken.TokenPrefix,
+		},
+	})
+
+	return token, accessToken, nil
+}
+
+func (s *AccessTokenService) GetTokens(ctx context.Context, email string) ([]models.AccessTokens, error) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
+	}
+
+	return s.accessTokenRepo.FindAccessTokensByUserId(ctx, user.ID)
+}
+
+func (s *AccessTokenService) RevokeToken(ctx context.Context, email string, idStr string) error {
+	if idStr == "" {
+		return errors.New("Id is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return errors.New("User not found")
+	}
+
+	token, err := s.accessTokenRepo.FindAccessTokenById(ctx, idStr)
+	if err != nil {
+		return fmt.Errorf("failed to find access token by ID: %w", err)
+	}
+
+	if token.UserID != user.ID {
+		return errors.New("you do not have permission to access this token")
+	}
+
+	return s.accessTokenRepo.RevokeAccessToken(ctx, token.ID)
+}
+
+func (s *AccessTokenService) Authenticate(ctx context.Context, token string, ip string) (*models.Users, *models.AccessTokens, error) {
+	if !strings.HasPrefix(token, AccessTokenPrefix) {
+		return nil, nil, errors.New("Invalid access token")
+	}
+
+	accessToken, err := s.accessTokenRepo.FindActiveByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if accessToken == nil {
+		return nil, nil, errors.New("Invalid access token")
+	}
+
+	user, err := s.userRepo.FindUserById(ctx, strconv.FormatUint(uint64(accessToken.UserID), 10))
+	if err != nil || user == nil {
+		return nil, nil, errors.New("User not found")
+	}
+
+	// NOTE - ไม่ต้องเขียน DB ทุก request ถ้าเพิ่งใช้ไปจาก IP เดิม
+	if accessToken.LastUsedAt == nil || time.Since(*accessToken.LastUsedAt) > lastUsedUpdateInterval || accessToken.LastUsedIP != ip {
+		if err := s.accessTokenRepo.UpdateLastUsed(ctx, accessToken.ID, ip); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return user, accessToken, nil
+}
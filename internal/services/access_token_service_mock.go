@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type AccessTokenServiceMock struct {
+	mock.Mock
+}
+
+func NewAccessTokenServiceMock() *AccessTokenServiceMock {
+	return &AccessTokenServiceMock{}
+}
+
+func (m *AccessTokenServiceMock) CreateToken(ctx context.Context, email string, name string, scopes []string, expiresInDays int, client ClientInfo) (string, *models.AccessTokens, error) {
+	args := m.Called(ctx, email, name, scopes, expiresInDays, client)
+	if token, ok := args.Get(1).(*models.AccessTokens); ok {
+		return args.String(0), token, args.Error(2)
+	}
+	return "", nil, args.Error(2)
+}
+
+func (m *AccessTokenServiceMock) GetTokens(ctx context.Context, email string) ([]models.AccessTokens, error) {
+	args := m.Called(ctx, email)
+	if tokens, ok := args.Get(0).([]models.AccessTokens); ok {
+		return tokens, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenServiceMock) RevokeToken(ctx context.Context, email string, idStr string) error {
+	args := m.Called(ctx, email, idStr)
+	return args.Error(0)
+}
+
+func (m *AccessTokenServiceMock) Authenticate(ctx context.Context, token string, ip string) (*models.Users, *models.AccessTokens, error) {
+	args := m.Called(ctx, token, ip)
+	user, _ := args.Get(0).(*models.Users)
+	accessToken, _ := args.Get(1).(*models.AccessTokens)
+	return user, accessToken, args.Error(2)
+}
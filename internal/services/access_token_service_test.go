@@ -0,0 +1,181 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newAccessTokenService() (*services.AccessTokenService, *repositories.UserRepositoryMock, *repositories.AccessTokenRepositoryMock) {
+	userRepo := repositories.NewUserRepositoryMock()
+	accessTokenRepo := repositories.NewAccessTokenRepositoryMock()
+
+	accessTokenService := services.NewAccessTokenService(userRepo, accessTokenRepo, newAuditRecorder())
+
+	return accessTokenService, userRepo, accessTokenRepo
+}
+
+func TestCreateAccessToken(t *testing.T) {
+	user := &models.Users{
+		Email: "test@gmail.com",
+		Model: gorm.Model{ID: 1},
+	}
+
+	t.Run("Create token success", func(t *testing.T) {
+		accessTokenService, userRepo, accessTokenRepo := newAccessTokenService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+
+		var saved *models.AccessTokens
+		accessTokenRepo.On("CreateAccessToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*models.AccessTokens)
+		}).Return(nil)
+
+		token, accessToken, err := accessTokenService.CreateToken(context.Background(), user.Email, "CI", []string{"tasks:read", "tasks:write", "tasks:read"}, 7, testClient)
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token, services.AccessTokenPrefix))
+		assert.Equal(t, saved, accessToken)
+		assert.Equal(t, user.ID, saved.UserID)
+		assert.Equal(t, "tasks:read tasks:write", saved.Scopes)
+		assert.Equal(t, utils.HashToken(token), saved.TokenHash)
+		assert.NotContains(t, saved.TokenHash, token)
+		assert.True(t, strings.HasPrefix(token, saved.TokenPrefix))
+		assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), saved.ExpiresAt, time.Minute)
+	})
+
+	t.Run("Default expiration", func(t *testing.T) {
+		accessTokenService, userRepo, accessTokenRepo := newAccessTokenService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		accessTokenRepo.On("CreateAccessToken", mock.Anything, mock.Anything).Return(nil)
+
+		_, accessToken, err := accessTokenService.CreateToken(context.Background(), user.Email, "CI", []string{"tasks:read"}, 0, testClient)
+
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), accessToken.ExpiresAt, time.Minute)
+	})
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		accessTokenService, _, accessTokenRepo := newAccessTokenService()
+
+		_, _, err := accessTokenService.CreateToken(context.Background(), user.Email, "CI", []string{"admin"}, 7, testClient)
+
+		assert.EqualError(t, err, "Invalid scope: admin")
+		accessTokenRepo.AssertNotCalled(t, "CreateAccessToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Scope required", func(t *testing.T) {
+		accessTokenService, _, _ := newAccessTokenService()
+
+		_, _, err := accessTokenService.CreateToken(context.Background(), user.Email, "CI", nil, 7, testClient)
+
+		assert.EqualError(t, err, "At least one scope is required")
+	})
+
+	t.Run("Expiration too long", func(t *testing.T) {
+		accessTokenService, _, _ := newAccessTokenService()
+
+		_, _, err := accessTokenService.CreateToken(context.Background(), user.Email, "CI", []string{"tasks:read"}, 400, testClient)
+
+		assert.EqualError(t, err, "Expiration must be between 1 and 365 days")
+	})
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	user := &models.Users{
+		Email: "test@gmail.com",
+		Model: gorm.Model{ID: 1},
+	}
+
+	t.Run("Revoke success", func(t *testing.T) {
+		accessTokenService, userRepo, accessTokenRepo := newAccessTokenService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		accessTokenRepo.On("FindAccessTokenById", mock.Anything, "3").Return(&models.AccessTokens{UserID: 1, Model: gorm.Model{ID: 3}}, nil)
+		accessTokenRepo.On("RevokeAccessToken", mock.Anything, uint(3)).Return(nil)
+
+		err := accessTokenService.RevokeToken(context.Background(), user.Email, "3")
+
+		assert.NoError(t, err)
+		accessTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Cannot revoke other user token", func(t *testing.T) {
+		accessTokenService, userRepo, accessTokenRepo := newAccessTokenService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		accessTokenRepo.On("FindAccessTokenById", mock.Anything, "3").Return(&models.AccessTokens{UserID: 2, Model: gorm.Model{ID: 3}}, nil)
+
+		err := accessTokenService.RevokeToken(context.Background(), user.Email, "3")
+
+		assert.EqualError(t, err, "you do not have permission to access this token")
+		accessTokenRepo.AssertNotCalled(t, "RevokeAccessToken", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthenticateAccessToken(t *testing.T) {
+	user := &models.Users{
+		Email: "test@gmail.com",
+		Model: gorm.Model{ID: 1},
+	}
+	token := services.AccessTokenPrefix + "secret"
+
+	t.Run("Authenticate success", func(t *testing.T) {
+		accessTokenService, userRepo, accessTokenRepo := newAccessTokenService()
+
+		accessToken := &models.AccessTokens{UserID: 1, Scopes: "tasks:read", Model: gorm.Model{ID: 3}}
+		accessTokenRepo.On("FindActiveByTokenHash", mock.Anything, utils.HashToken(token)).Return(accessToken, nil)
+		userRepo.On("FindUserById", mock.Anything, "1").Return(user, nil)
+		accessTokenRepo.On("UpdateLastUsed", mock.Anything, uint(3), "10.0.0.1").Return(nil)
+
+		returnUser, returnToken, err := accessTokenService.Authenticate(context.Background(), token, "10.0.0.1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, user, returnUser)
+		assert.Equal(t, accessToken, returnToken)
+		accessTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Skip last used update for recent use", func(t *testing.T) {
+		accessTokenService, userRepo, accessTokenRepo := newAccessTokenService()
+
+		lastUsed := time.Now().Add(-10 * time.Second)
+		accessToken := &models.AccessTokens{UserID: 1, LastUsedAt: &lastUsed, LastUsedIP: "10.0.0.1", Model: gorm.Model{ID: 3}}
+		accessTokenRepo.On("FindActiveByTokenHash", mock.Anything, utils.HashToken(token)).Return(accessToken, nil)
+		userRepo.On("FindUserById", mock.Anything, "1").Return(user, nil)
+
+		_, _, err := accessTokenService.Authenticate(context.Background(), token, "10.0.0.1")
+
+		assert.NoError(t, err)
+		accessTokenRepo.AssertNotCalled(t, "UpdateLastUsed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Revoked or expired token", func(t *testing.T) {
+		accessTokenService, _, accessTokenRepo := newAccessTokenService()
+
+		accessTokenRepo.On("FindActiveByTokenHash", mock.Anything, utils.HashToken(token)).Return(nil, nil)
+
+		_, _, err := accessTokenService.Authenticate(context.Background(), token, "10.0.0.1")
+
+		assert.EqualError(t, err, "Invalid access token")
+	})
+
+	t.Run("Not a personal access token", func(t *testing.T) {
+		accessTokenService, _, accessTokenRepo := newAccessTokenService()
+
+		_, _, err := accessTokenService.Authenticate(context.Background(), "eyJhbGciOiJIUzI1NiJ9", "10.0.0.1")
+
+		assert.EqualError(t, err, "Invalid access token")
+		accessTokenRepo.AssertNotCalled(t, "FindActiveByTokenHash", mock.Anything, mock.Anything)
+	})
+}
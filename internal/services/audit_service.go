@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// NOTE - ActorID/TargetID เป็น 0 ถ้าไม่รู้หรือไม่มี
+type AuditEvent struct {
+	Action     string
+	ActorID    uint
+	ActorEmail string
+	TargetID   uint
+	Client     ClientInfo
+	Metadata   models.AuditMetadata
+}
+
+type AuditServiceInterface interface {
+	Record(ctx context.Context, event AuditEvent)
+	GetAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLogs, error)
+	ExportAuditLogs(ctx context.Context, adminEmail string, filter models.AuditLogFilter, client ClientInfo, w io.Writer) error
+}
+
+type AuditService struct {
+	userRepo  repositories.UserRepositoryInterface
+	auditRepo repositories.AuditLogRepositoryInterface
+	metrics   metrics.RecorderInterface
+}
+
+func NewAuditService(userRepo repositories.UserRepositoryInterface, auditRepo repositories.AuditLogRepositoryInterface, metrics metrics.RecorderInterface) *AuditService {
+	return &AuditService{userRepo: userRepo, auditRepo: auditRepo, metrics: metrics}
+}
+
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// NOTE - เขียน audit ไม่สำเร็จไม่ควรทำให้ login/เปลี่ยน password ล้มเหลวตาม แค่ log ไว้
+func (s *AuditService) Record(ctx context.Context, event AuditEvent) {
+	entry := &models.AuditLogs{
+		Action:     event.Action,
+		ActorID:    optionalID(event.ActorID),
+		ActorEmail: event.ActorEmail,
+		TargetID:   optionalID(event.TargetID),
+		IP:         event.Client.IP,
+		UserAgent:  event.Client.UserAgent,
+		Metadata:   event.Metadata,
+	}
+
+	if err := s.auditRepo.CreateAuditLog(ctx, entry); err != nil {
+		slog.Error("Failed to write audit log", "action", event.Action, "actor_email", event.ActorEmail, "error", err)
+	}
+
+	// NOTE - login ทุกช่องทางผ่านตรงนี้อยู่แล้ว นับ metric ที่เดียวไม่ต้องไล่ใส่ทุก service
+	switch event.Action {
+	case models.AuditLogin:
+		s.metrics.LoginSucceeded()
+	case models.AuditLoginFailed:
+		s.metrics.LoginFailed(event.Metadata["reason"])
+	}
+}
+
+func (s *AuditService) GetAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLogs, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	if filter.Offset < 0 {
+		return nil, errors.New("Offset must not be negative")
+	}
+
+	return s.auditRepo.FindAuditLogs(ctx, filter)
+}
+
+// NOTE - เขียนเป็น NDJSON หนึ่งบรรทัดต่อหนึ่ง event ไม่จำกัดจำนวนแถวเหมือน GetAuditLogs
+func (s *AuditService) ExportAuditLogs(ctx context.Context, adminEmail string, filter models.AuditLogFilter, client ClientInfo, w io.Writer) error {
+	admin, err := s.userRepo.FindByEmail(ctx, adminEmail)
+	if err != nil || admin == nil {
+		return errors.New("User not found")
+	}
+
+	// NOTE - การ export เองก็เป็น admin action ต้องถูกบันทึก
+	s.Record(ctx, AuditEvent{
+		Action:     models.AuditAdminExport,
+		ActorID:    admin.ID,
+		ActorEmail: admin.Email,
+		Client:     client,
+		Metadata:   exportFilterMetadata(filter),
+	})
+
+	encoder := json.NewEncoder(w)
+	return s.auditRepo.EachAuditLog(ctx, filter, func(entry *models.AuditLogs) error {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("Failed to write audit log: %w", err)
+		}
+		return nil
+	})
+}
+
+func exportFilterMetadata(filter models.AuditLogFilter) models.AuditMetadata {
+	metadata := models.AuditMetadata{}
+	if filter.Action != "" {
+		metadata["action"] = filter.Action
+	}
+	if filter.ActorID != nil {
+		metadata["actor_id"] = fmt.Sprint(*filter.ActorID)
+	}
+	if filter.ActorEmail != "" {
+		metadata["actor_email"] = filter.ActorEmail
+	}
+	if filter.IP != "" {
+		metadata["ip"] = filter.IP
+	}
+	if filter.From != nil {
+		metadata["from"] = filter.From.UTC().Format(time.RFC3339)
+	}
+	if filter.To != nil {
+		metadata["to"] = filter.To.UTC().Format(time.RFC3339)
+	}
+	return metadata
+}
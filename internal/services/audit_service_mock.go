@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditServiceMock struct {
+	mock.Mock
+}
+
+func NewAuditServiceMock() *AuditServiceMock {
+	return &AuditServiceMock{}
+}
+
+func (m *AuditServiceMock) Record(ctx context.Context, event AuditEvent) {
+	m.Called(ctx, event)
+}
+
+func (m *AuditServiceMock) GetAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]models.AuditLogs, error) {
+	args := m.Called(ctx, filter)
+	if logs, ok := args.Get(0).([]models.AuditLogs); ok {
+		return logs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuditServiceMock) ExportAuditLogs(ctx context.Context, adminEmail string, filter models.AuditLogFilter, client ClientInfo, w io.Writer) error {
+	args := m.Called(ctx, adminEmail, filter, client, w)
+	if out, ok := args.Get(0).(string); ok {
+		io.WriteString(w, out)
+	}
+	return args.Error(1)
+}
@@ -0,0 +1,181 @@
+package services_test
+
+import (
+	"context"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// NOTE - service อื่นที่ไม่ได้ทดสอบเรื่อง audit ใช้ตัวนี้รับ event ไปเฉยๆ
+func newAuditRecorder() *services.AuditServiceMock {
+	auditService := services.NewAuditServiceMock()
+	auditService.On("Record", mock.Anything, mock.Anything).Return()
+	return auditService
+}
+
+// NOTE - test ที่ไม่ได้เช็ค metric ใช้ตัวนี้ รับทุก event ไปเฉยๆ
+func newMetricsRecorder() *metrics.RecorderMock {
+	recorder := metrics.NewRecorderMock()
+	recorder.On("TaskCreated").Return()
+	recorder.On("TaskCompleted").Return()
+	recorder.On("LoginSucceeded").Return()
+	recorder.On("LoginFailed", mock.Anything).Return()
+	return recorder
+}
+
+func newAuditService() (*services.AuditService, *repositories.UserRepositoryMock, *repositories.AuditLogRepositoryMock) {
+	userRepo := repositories.NewUserRepositoryMock()
+	auditRepo := repositories.NewAuditLogRepositoryMock()
+
+	return services.NewAuditService(userRepo, auditRepo, newMetricsRecorder()), userRepo, auditRepo
+}
+
+func TestRecordAudit(t *testing.T) {
+	t.Run("Record event", func(t *testing.T) {
+		auditService, _, auditRepo := newAuditService()
+
+		var saved *models.AuditLogs
+		auditRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*models.AuditLogs)
+		}).Return(nil)
+
+		auditService.Record(context.Background(), services.AuditEvent{
+			Action:     models.AuditLogin,
+			ActorID:    1,
+			ActorEmail: "test@gmail.com",
+			Client:     testClient,
+		})
+
+		assert.Equal(t, models.AuditLogin, saved.Action)
+		assert.Equal(t, uint(1), *saved.ActorID)
+		assert.Nil(t, saved.TargetID)
+		assert.Equal(t, testClient.IP, saved.IP)
+		assert.Equal(t, testClient.UserAgent, saved.UserAgent)
+	})
+
+	t.Run("Count login metrics", func(t *testing.T) {
+		userRepo := repositories.NewUserRepositoryMock()
+		auditRepo := repositories.NewAuditLogRepositoryMock()
+		recorder := metrics.NewRecorderMock()
+		auditService := services.NewAuditService(userRepo, auditRepo, recorder)
+
+		auditRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Return(nil)
+		recorder.On("LoginSucceeded").Return()
+		recorder.On("LoginFailed", "locked").Return()
+
+		auditService.Record(context.Background(), services.AuditEvent{Action: models.AuditLogin, ActorEmail: "test@gmail.com"})
+		auditService.Record(context.Background(), services.AuditEvent{Action: models.AuditLoginFailed, ActorEmail: "test@gmail.com", Metadata: models.AuditMetadata{"reason": "locked"}})
+		auditService.Record(context.Background(), services.AuditEvent{Action: models.AuditLogout, ActorEmail: "test@gmail.com"})
+
+		recorder.AssertExpectations(t)
+		recorder.AssertNumberOfCalls(t, "LoginSucceeded", 1)
+	})
+
+	t.Run("Unknown actor", func(t *testing.T) {
+		auditService, _, auditRepo := newAuditService()
+
+		var saved *models.AuditLogs
+		auditRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*models.AuditLogs)
+		}).Return(nil)
+
+		auditService.Record(context.Background(), services.AuditEvent{Action: models.AuditLoginFailed, ActorEmail: "nobody@gmail.com"})
+
+		assert.Nil(t, saved.ActorID)
+		assert.Equal(t, "nobody@gmail.com", saved.ActorEmail)
+	})
+
+	t.Run("Write failure does not panic", func(t *testing.T) {
+		auditService, _, auditRepo := newAuditService()
+
+		auditRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+		assert.NotPanics(t, func() {
+			auditService.Record(context.Background(), services.AuditEvent{Action: models.AuditLogin})
+		})
+	})
+}
+
+func TestGetAuditLogs(t *testing.T) {
+	t.Run("Default limit", func(t *testing.T) {
+		auditService, _, auditRepo := newAuditService()
+
+		auditRepo.On("FindAuditLogs", mock.Anything, models.AuditLogFilter{Action: models.AuditLogin, Limit: 50}).Return([]models.AuditLogs{}, nil)
+
+		_, err := auditService.GetAuditLogs(context.Background(), models.AuditLogFilter{Action: models.AuditLogin})
+
+		assert.NoError(t, err)
+		auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("Cap limit", func(t *testing.T) {
+		auditService, _, auditRepo := newAuditService()
+
+		auditRepo.On("FindAuditLogs", mock.Anything, models.AuditLogFilter{Limit: 500}).Return([]models.AuditLogs{}, nil)
+
+		_, err := auditService.GetAuditLogs(context.Background(), models.AuditLogFilter{Limit: 100000})
+
+		assert.NoError(t, err)
+		auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("Negative offset", func(t *testing.T) {
+		auditService, _, _ := newAuditService()
+
+		_, err := auditService.GetAuditLogs(context.Background(), models.AuditLogFilter{Offset: -1})
+
+		assert.EqualError(t, err, "Offset must not be negative")
+	})
+}
+
+func TestExportAuditLogs(t *testing.T) {
+	admin := &models.Users{Email: "admin@gmail.com", Role: models.Admin, Model: gorm.Model{ID: 9}}
+
+	t.Run("Export NDJSON", func(t *testing.T) {
+		auditService, userRepo, auditRepo := newAuditService()
+
+		filter := models.AuditLogFilter{Action: models.AuditLogin}
+
+		userRepo.On("FindByEmail", mock.Anything, admin.Email).Return(admin, nil)
+
+		var recorded *models.AuditLogs
+		auditRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			recorded = args.Get(1).(*models.AuditLogs)
+		}).Return(nil)
+		auditRepo.On("EachAuditLog", mock.Anything, filter, mock.Anything).Return([]models.AuditLogs{
+			{ID: 1, Action: models.AuditLogin, ActorEmail: "a@gmail.com"},
+			{ID: 2, Action: models.AuditLogin, ActorEmail: "b@gmail.com", Metadata: models.AuditMetadata{"reason": "x"}},
+		}, nil)
+
+		var out bytes.Buffer
+		err := auditService.ExportAuditLogs(context.Background(), admin.Email, filter, testClient, &out)
+
+		assert.NoError(t, err)
+
+		var lines []models.AuditLogs
+		scanner := bufio.NewScanner(&out)
+		for scanner.Scan() {
+			var entry models.AuditLogs
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			lines = append(lines, entry)
+		}
+		assert.Len(t, lines, 2)
+		assert.Equal(t, "b@gmail.com", lines[1].ActorEmail)
+		assert.Equal(t, "x", lines[1].Metadata["reason"])
+
+		assert.Equal(t, models.AuditAdminExport, recorded.Action)
+		assert.Equal(t, admin.ID, *recorded.ActorID)
+		assert.Equal(t, models.AuditLogin, recorded.Metadata["action"])
+	})
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+// NOTE - probe ต้องตอบเร็ว DB ที่ช้ากว่านี้ถือว่าไม่พร้อม
+const readinessTimeout = 2 * time.Second
+
+type HealthServiceInterface interface {
+	Ready(ctx context.Context) (bool, map[string]string)
+	Version() utils.BuildInfo
+}
+
+type HealthService struct {
+	healthRepo repositories.HealthRepositoryInterface
+	lifecycle  utils.LifecycleInterface
+	buildInfo  utils.BuildInfo
+}
+
+func NewHealthService(healthRepo repositories.HealthRepositoryInterface, lifecycle utils.LifecycleInterface, buildInfo utils.BuildInfo) *HealthService {
+	return &HealthService{healthRepo: healthRepo, lifecycle: lifecycle, buildInfo: buildInfo}
+}
+
+// NOTE - คืนผลของแต่ละ check ด้วย ให้ดูได้ว่า check ไหนไม่พร้อม
+// /readyz เปิดให้ทุกคนเรียกได้ เลยตอบแค่ "unavailable" รายละเอียด error (host, ชื่อ migration) ไปดูใน log แทน
+func (s *HealthService) Ready(ctx context.Context) (bool, map[string]string) {
+	checks := map[string]string{}
+	ready := true
+	check := func(name string, err error) {
+		if err != nil {
+			slog.WarnContext(ctx, "Readiness check failed", "check", name, "error", err)
+			checks[name] = "unavailable"
+			ready = false
+			return
+		}
+		checks[name] = "ok"
+	}
+
+	if !s.lifecycle.Ready() {
+		check("server", errors.New("Server is shutting down"))
+	} else {
+		check("server", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := s.healthRepo.Ping(ctx); err != nil {
+		check("database", err)
+		check("migrations", errors.New("Database is unreachable"))
+	} else {
+		check("database", nil)
+
+		pending, err := s.healthRepo.PendingMigrations(ctx)
+		if err == nil && len(pending) > 0 {
+			err = fmt.Errorf("Pending migrations: %s", strings.Join(pending, ", "))
+		}
+		check("migrations", err)
+	}
+
+	check("workers", s.lifecycle.CheckWorkers())
+	// NOTE - worker ที่รอบล่าสุดพังแต่ยังทำงานตามรอบ ไม่ทำให้ไม่ ready แค่บอกไว้ให้เห็น
+	if failed := s.lifecycle.FailedWorkers(); len(failed) > 0 {
+		checks["workers_last_error"] = strings.Join(failed, ", ")
+	}
+
+	return ready, checks
+}
+
+func (s *HealthService) Version() utils.BuildInfo {
+	return s.buildInfo
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+)
+
+type HealthServiceMock struct {
+	mock.Mock
+}
+
+func NewHealthServiceMock() *HealthServiceMock {
+	return &HealthServiceMock{}
+}
+
+func (m *HealthServiceMock) Ready(ctx context.Context) (bool, map[string]string) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Get(1).(map[string]string)
+}
+
+func (m *HealthServiceMock) Version() utils.BuildInfo {
+	args := m.Called()
+	return args.Get(0).(utils.BuildInfo)
+}
@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newHealthService() (*services.HealthService, *repositories.HealthRepositoryMock, *utils.LifecycleMock) {
+	healthRepo := repositories.NewHealthRepositoryMock()
+	lifecycle := utils.NewLifecycleMock()
+
+	return services.NewHealthService(healthRepo, lifecycle, utils.BuildInfo{GitSHA: "abc123"}), healthRepo, lifecycle
+}
+
+// NOTE - รายละเอียดที่ไม่ได้ส่งกลับใน /readyz ต้องไปอยู่ใน log แทน
+func captureLogs(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return buf
+}
+
+func TestReady(t *testing.T) {
+	t.Run("All checks pass", func(t *testing.T) {
+		healthService, healthRepo, lifecycle := newHealthService()
+
+		lifecycle.On("Ready").Return(true)
+		lifecycle.On("CheckWorkers").Return(nil)
+		lifecycle.On("FailedWorkers").Return(nil)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("PendingMigrations", mock.Anything).Return([]string{}, nil)
+
+		ready, checks := healthService.Ready(context.Background())
+
+		assert.True(t, ready)
+		assert.Equal(t, map[string]string{"server": "ok", "database": "ok", "migrations": "ok", "workers": "ok"}, checks)
+	})
+
+	t.Run("Database ping uses timeout", func(t *testing.T) {
+		healthService, healthRepo, lifecycle := newHealthService()
+
+		lifecycle.On("Ready").Return(true)
+		lifecycle.On("CheckWorkers").Return(nil)
+		lifecycle.On("FailedWorkers").Return(nil)
+		healthRepo.On("Ping", mock.Anything).Run(func(args mock.Arguments) {
+			_, ok := args.Get(0).(context.Context).Deadline()
+			assert.True(t, ok)
+		}).Return(context.DeadlineExceeded)
+
+		ready, checks := healthService.Ready(context.Background())
+
+		assert.False(t, ready)
+		assert.Equal(t, map[string]string{"server": "ok", "database": "unavailable", "migrations": "unavailable", "workers": "ok"}, checks)
+		healthRepo.AssertNotCalled(t, "PendingMigrations", mock.Anything)
+	})
+
+	t.Run("Pending migrations", func(t *testing.T) {
+		healthService, healthRepo, lifecycle := newHealthService()
+		logs := captureLogs(t)
+
+		lifecycle.On("Ready").Return(true)
+		lifecycle.On("CheckWorkers").Return(nil)
+		lifecycle.On("FailedWorkers").Return(nil)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("PendingMigrations", mock.Anything).Return([]string{"sessions", "audit_log"}, nil)
+
+		ready, checks := healthService.Ready(context.Background())
+
+		assert.False(t, ready)
+		assert.Equal(t, "unavailable", checks["migrations"])
+		assert.Contains(t, logs.String(), "Pending migrations: sessions, audit_log")
+	})
+
+	t.Run("Worker stopped running", func(t *testing.T) {
+		healthService, healthRepo, lifecycle := newHealthService()
+		logs := captureLogs(t)
+
+		lifecycle.On("Ready").Return(true)
+		lifecycle.On("CheckWorkers").Return(errors.New("rate limit cleanup has not run for 3m0s"))
+		lifecycle.On("FailedWorkers").Return(nil)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("PendingMigrations", mock.Anything).Return(nil, nil)
+
+		ready, checks := healthService.Ready(context.Background())
+
+		assert.False(t, ready)
+		assert.Equal(t, "unavailable", checks["workers"])
+		assert.Contains(t, logs.String(), "rate limit cleanup has not run for 3m0s")
+	})
+
+	t.Run("Single failed worker run stays ready", func(t *testing.T) {
+		healthService, healthRepo, lifecycle := newHealthService()
+
+		lifecycle.On("Ready").Return(true)
+		lifecycle.On("CheckWorkers").Return(nil)
+		lifecycle.On("FailedWorkers").Return([]string{"rate limit cleanup"})
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("PendingMigrations", mock.Anything).Return(nil, nil)
+
+		ready, checks := healthService.Ready(context.Background())
+
+		assert.True(t, ready)
+		assert.Equal(t, "ok", checks["workers"])
+		assert.Equal(t, "rate limit cleanup", checks["workers_last_error"])
+	})
+
+	t.Run("Shutting down", func(t *testing.T) {
+		healthService, healthRepo, lifecycle := newHealthService()
+		logs := captureLogs(t)
+
+		lifecycle.On("Ready").Return(false)
+		lifecycle.On("CheckWorkers").Return(nil)
+		lifecycle.On("FailedWorkers").Return(nil)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("PendingMigrations", mock.Anything).Return(nil, nil)
+
+		ready, checks := healthService.Ready(context.Background())
+
+		assert.False(t, ready)
+		assert.Equal(t, "unavailable", checks["server"])
+		assert.Contains(t, logs.String(), "Server is shutting down")
+	})
+}
+
+func TestVersion(t *testing.T) {
+	healthService, _, _ := newHealthService()
+
+	assert.Equal(t, "abc123", healthService.Version().GitSHA)
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+)
+
+const loginAttemptWindow = 15 * time.Minute
+
+// NOTE - ข้อความเดียวกันทั้ง account และ IP ไม่บอกว่า email มีอยู่จริงหรือไม่
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "Too many login attempts, please try again later"
+}
+
+type LoginThrottleInterface interface {
+	Check(ctx context.Context, email string, ip string) error
+	RegisterFailure(ctx context.Context, email string, ip string) error
+	RegisterSuccess(ctx context.Context, email string) error
+}
+
+// NOTE - ผิดครบ freeAttempts แล้วต้องรอนานขึ้นเท่าตัวทุกครั้ง ครบ lockoutAfter ล็อกยาว
+type throttlePolicy struct {
+	freeAttempts    int
+	maxDelay        time.Duration
+	lockoutAfter    int
+	lockoutDuration time.Duration
+}
+
+func (p throttlePolicy) delay(failures int) time.Duration {
+	if failures >= p.lockoutAfter {
+		return p.lockoutDuration
+	}
+	if failures < p.freeAttempts {
+		return 0
+	}
+
+	delay := time.Second << (failures - p.freeAttempts)
+	if delay > p.maxDelay {
+		return p.maxDelay
+	}
+	return delay
+}
+
+var (
+	accountThrottlePolicy = throttlePolicy{freeAttempts: 3, maxDelay: 30 * time.Second, lockoutAfter: 10, lockoutDuration: 15 * time.Minute}
+	// NOTE - IP ให้เยอะกว่าเพราะหลายคนอาจใช้ NAT เดียวกัน
+	ipThrottlePolicy = throttlePolicy{freeAttempts: 20, maxDelay: 30 * time.Second, lockoutAfter: 100, lockoutDuration: 15 * time.Minute}
+)
+
+type LoginThrottle struct {
+	loginAttemptRepo repositories.LoginAttemptRepositoryInterface
+}
+
+func NewLoginThrottle(loginAttemptRepo repositories.LoginAttemptRepositoryInterface) *LoginThrottle {
+	return &LoginThrottle{loginAttemptRepo: loginAttemptRepo}
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (s *LoginThrottle) Check(ctx context.Context, email string, ip string) error {
+	attempts, err := s.loginAttemptRepo.FindByKeys(ctx, []string{accountAttemptKey(email), ipAttemptKey(ip)})
+	if err != nil {
+		return err
+	}
+
+	var retryAfter time.Duration
+	for _, attempt := range attempts {
+		if attempt.LockedUntil == nil {
+			continue
+		}
+		if wait := time.Until(*attempt.LockedUntil); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// NOTE - นับ email ที่ไม่มีในระบบด้วย ไม่งั้นดูจากการโดนล็อกก็รู้ว่า email ไหนมีจริง
+func (s *LoginThrottle) RegisterFailure(ctx context.Context, email string, ip string) error {
+	keys := []struct {
+		key    string
+		policy throttlePolicy
+	}{
+		{accountAttemptKey(email), accountThrottlePolicy},
+		{ipAttemptKey(ip), ipThrottlePolicy},
+	}
+
+	for _, k := range keys {
+		attempt, err := s.loginAttemptRepo.RecordFailure(ctx, k.key, loginAttemptWindow)
+		if err != nil {
+			return err
+		}
+
+		if delay := k.policy.delay(attempt.Failures); delay > 0 {
+			if err := s.loginAttemptRepo.LockUntil(ctx, k.key, attempt.LastFailedAt.Add(delay)); err != nil {
+				return fmt.Errorf("Failed to lock login: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NOTE - reset แค่ฝั่ง account ถ้า reset IP ด้วย คนร้ายจะ login บัญชีตัวเองสลับเพื่อล้างตัวนับได้
+func (s *LoginThrottle) RegisterSuccess(ctx context.Context, email string) error {
+	return s.loginAttemptRepo.DeleteByKey(ctx, accountAttemptKey(email))
+}
@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	t.Run("Free attempts are not delayed", func(t *testing.T) {
+		loginThrottle := newLoginThrottle()
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, loginThrottle.RegisterFailure(context.Background(), "test@gmail.com", "10.0.0.1"))
+		}
+
+		assert.NoError(t, loginThrottle.Check(context.Background(), "test@gmail.com", "10.0.0.1"))
+	})
+
+	t.Run("Delay grows with each failure", func(t *testing.T) {
+		loginThrottle := newLoginThrottle()
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, loginThrottle.RegisterFailure(context.Background(), "test@gmail.com", "10.0.0.1"))
+		}
+
+		var first *services.LoginThrottledError
+		require.ErrorAs(t, loginThrottle.Check(context.Background(), "test@gmail.com", "10.0.0.1"), &first)
+
+		require.NoError(t, loginThrottle.RegisterFailure(context.Background(), "test@gmail.com", "10.0.0.1"))
+
+		var second *services.LoginThrottledError
+		require.ErrorAs(t, loginThrottle.Check(context.Background(), "test@gmail.com", "10.0.0.1"), &second)
+		assert.Greater(t, second.RetryAfter, first.RetryAfter)
+	})
+
+	t.Run("Account locked from any IP", func(t *testing.T) {
+		loginThrottle := newLoginThrottle()
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, loginThrottle.RegisterFailure(context.Background(), "Test@gmail.com", "10.0.0.1"))
+		}
+
+		var throttled *services.LoginThrottledError
+		require.ErrorAs(t, loginThrottle.Check(context.Background(), "test@gmail.com", "10.0.0.2"), &throttled)
+		assert.Greater(t, throttled.RetryAfter, 14*time.Minute)
+	})
+
+	t.Run("IP locked across accounts", func(t *testing.T) {
+		loginThrottle := newLoginThrottle()
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, loginThrottle.RegisterFailure(context.Background(), "user"+string(rune('a'+i%26))+"@gmail.com", "10.0.0.1"))
+		}
+
+		var throttled *services.LoginThrottledError
+		assert.ErrorAs(t, loginThrottle.Check(context.Background(), "new@gmail.com", "10.0.0.1"), &throttled)
+		assert.NoError(t, loginThrottle.Check(context.Background(), "new@gmail.com", "10.0.0.2"))
+	})
+
+	t.Run("Success resets account but not IP", func(t *testing.T) {
+		loginAttemptRepo := repositories.NewLoginAttemptMemoryRepository()
+		loginThrottle := services.NewLoginThrottle(loginAttemptRepo)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, loginThrottle.RegisterFailure(context.Background(), "test@gmail.com", "10.0.0.1"))
+		}
+		require.NoError(t, loginThrottle.RegisterSuccess(context.Background(), "test@gmail.com"))
+
+		attempts, err := loginAttemptRepo.FindByKeys(context.Background(), []string{"account:test@gmail.com", "ip:10.0.0.1"})
+		require.NoError(t, err)
+		assert.Len(t, attempts, 1)
+		assert.Equal(t, "ip:10.0.0.1", attempts[0].AttemptKey)
+		assert.Equal(t, 3, attempts[0].Failures)
+	})
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+type MagicLinkServiceInterface interface {
+	RequestLink(ctx context.Context, email string, fingerprint string) error
+	Login(ctx context.Context, token string, fingerprint string, client ClientInfo) (string, *models.Users, error)
+}
+
+type MagicLinkService struct {
+	userRepo       repositories.UserRepositoryInterface
+	magicLinkRepo  repositories.MagicLinkRepositoryInterface
+	jwtUtil        utils.JwtInterface
+	sessionService SessionServiceInterface
+	mailer         utils.MailerInterface
+	callbackURL    string
+}
+
+func NewMagicLinkService(userRepo repositories.UserRepositoryInterface, magicLinkRepo repositories.MagicLinkRepositoryInterface, jwtUtil utils.JwtInterface, sessionService SessionServiceInterface, mailer utils.MailerInterface, callbackURL string) *MagicLinkService {
+	return &MagicLinkService{userRepo: userRepo, magicLinkRepo: magicLinkRepo, jwtUtil: jwtUtil, sessionService: sessionService, mailer: mailer, callbackURL: callbackURL}
+}
+
+// NOTE - fingerprint คือค่าที่ระบุ device ที่ขอ link ต้องเปิด link จาก device เดียวกันถึงจะ login ได้
+func (s *MagicLinkService) RequestLink(ctx context.Context, email string, fingerprint string) error {
+	if email == "" {
+		return errors.New("Email is required")
+	}
+
+	if fingerprint == "" {
+		return errors.New("Device fingerprint is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("Fail To Check Email : %w", err)
+	}
+
+	// NOTE - ไม่บอกว่าไม่มี email นี้ในระบบ กันการเดา email
+	if user == nil {
+		return nil
+	}
+
+	// NOTE - เหมือน ForgotPassword ส่งไม่สำเร็จก็ตอบเหมือนส่งได้ ไม่งั้น error จะบอกได้ว่า email นี้มีบัญชี
+	if err := s.sendLink(ctx, user, fingerprint); err != nil {
+		slog.ErrorContext(ctx, "Failed to send magic link email", "user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+func (s *MagicLinkService) sendLink(ctx context.Context, user *models.Users, fingerprint string) error {
+	linkID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return fmt.Errorf("Failed to generate token: %w", err)
+	}
+
+	link := &models.MagicLinks{
+		UserID:    user.ID,
+		LinkID:    linkID,
+		ExpiresAt: time.Now().Add(utils.MagicLinkTTL),
+	}
+
+	if err := s.magicLinkRepo.CreateMagicLink(ctx, link); err != nil {
+		return fmt.Errorf("Failed to create magic link: %w", err)
+	}
+
+	token, err := s.jwtUtil.GenerateMagicLinkToken(user.Email, linkID, utils.HashToken(fingerprint))
+	if err != nil {
+		return fmt.Errorf("Failed to generate token: %w", err)
+	}
+
+	loginURL := fmt.Sprintf("%s?token=%s", s.callbackURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to sign in. It expires in %d minutes, can only be used once and only works in the browser where you requested it.\n\n%s\n\nIf you did not request this, you can ignore this email.",
+		user.Name, int(utils.MagicLinkTTL.Minutes()), loginURL)
+
+	if err := s.mailer.Send(user.Email, "Your sign-in link", body); err != nil {
+		return fmt.Errorf("Failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MagicLinkService) Login(ctx context.Context, token string, fingerprint string, client ClientInfo) (string, *models.Users, error) {
+	if token == "" {
+		return "", nil, errors.New("Token is required")
+	}
+
+	claims, err := s.jwtUtil.ParseMagicLinkToken(token)
+	if err != nil {
+		return "", nil, errors.New("Invalid or expired link")
+	}
+
+	// NOTE - เช็ค device ก่อน mark ว่าใช้แล้ว เปิดผิด device link จะยังใช้ได้จาก device ที่ถูกต้อง
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(fingerprint)), []byte(claims.Fingerprint)) != 1 {
+		return "", nil, errors.New("This link must be opened in the browser where it was requested")
+	}
+
+	used, err := s.magicLinkRepo.MarkUsed(ctx, claims.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !used {
+		return "", nil, errors.New("Invalid or expired link")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+	if err != nil || user == nil {
+		return "", nil, errors.New("User not found")
+	}
+
+	// NOTE - เปิด 2FA ไว้ต้องยืนยันต่อเหมือน login ด้วย password
+	if user.TwoFactorEnabled {
+		mfaToken, err := s.jwtUtil.GenerateMFAToken(user.Email)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to generate token: %w", err)
+		}
+		return mfaToken, user, ErrMFARequired
+	}
+
+	jwtToken, err := s.sessionService.StartSession(ctx, user, client)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return jwtToken, user, nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MagicLinkServiceMock struct {
+	mock.Mock
+}
+
+func NewMagicLinkServiceMock() *MagicLinkServiceMock {
+	return &MagicLinkServiceMock{}
+}
+
+func (m *MagicLinkServiceMock) RequestLink(ctx context.Context, email string, fingerprint string) error {
+	args := m.Called(ctx, email, fingerprint)
+	return args.Error(0)
+}
+
+func (m *MagicLinkServiceMock) Login(ctx context.Context, token string, fingerprint string, client ClientInfo) (string, *models.Users, error) {
+	args := m.Called(ctx, token, fingerprint, client)
+	if user, ok := args.Get(1).(*models.Users); ok {
+		return args.String(0), user, args.Error(2)
+	}
+	return args.String(0), nil, args.Error(2)
+}
@@ -0,0 +1,191 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+const testFingerprint = "deviceID|Mozilla/5.0"
+
+func newMagicLinkService() (*services.MagicLinkService, *repositories.UserRepositoryMock, *repositories.MagicLinkRepositoryMock, *utils.JwtMock, *services.SessionServiceMock, *utils.MailerMock) {
+	userRepo := repositories.NewUserRepositoryMock()
+	magicLinkRepo := repositories.NewMagicLinkRepositoryMock()
+	jwtUtil := utils.NewJwtMock()
+	sessionService := services.NewSessionServiceMock()
+	mailer := utils.NewMailerMock()
+
+	magicLinkService := services.NewMagicLinkService(userRepo, magicLinkRepo, jwtUtil, sessionService, mailer, "http://localhost:8080/api/user/login/magic/callback")
+
+	return magicLinkService, userRepo, magicLinkRepo, jwtUtil, sessionService, mailer
+}
+
+func magicLinkClaims(email string) *utils.JWTClaims {
+	return &utils.JWTClaims{
+		Email:            email,
+		Fingerprint:      utils.HashToken(testFingerprint),
+		RegisteredClaims: jwt.RegisteredClaims{ID: "linkID"},
+	}
+}
+
+func TestRequestMagicLink(t *testing.T) {
+	t.Run("Request link success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Name:  "Test User",
+			Model: gorm.Model{ID: 1},
+		}
+
+		magicLinkService, userRepo, magicLinkRepo, jwtUtil, _, mailer := newMagicLinkService()
+
+		var linkID string
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		magicLinkRepo.On("CreateMagicLink", mock.Anything, mock.MatchedBy(func(link *models.MagicLinks) bool {
+			linkID = link.LinkID
+			return link.UserID == user.ID && link.LinkID != ""
+		})).Return(nil)
+		jwtUtil.On("GenerateMagicLinkToken", user.Email, mock.AnythingOfType("string"), utils.HashToken(testFingerprint)).Return("magicToken", nil)
+		mailer.On("Send", user.Email, "Your sign-in link", mock.MatchedBy(func(body string) bool {
+			return strings.Contains(body, "http://localhost:8080/api/user/login/magic/callback?token=magicToken")
+		})).Return(nil)
+
+		err := magicLinkService.RequestLink(context.Background(), user.Email, testFingerprint)
+
+		assert.NoError(t, err)
+		jwtUtil.AssertCalled(t, "GenerateMagicLinkToken", user.Email, linkID, utils.HashToken(testFingerprint))
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("Unknown email does not send mail", func(t *testing.T) {
+		magicLinkService, userRepo, magicLinkRepo, _, _, mailer := newMagicLinkService()
+
+		userRepo.On("FindByEmail", mock.Anything, "unknown@gmail.com").Return(nil, nil)
+
+		err := magicLinkService.RequestLink(context.Background(), "unknown@gmail.com", testFingerprint)
+
+		assert.NoError(t, err)
+		magicLinkRepo.AssertNotCalled(t, "CreateMagicLink", mock.Anything, mock.Anything)
+		mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Email is required", func(t *testing.T) {
+		magicLinkService, _, _, _, _, _ := newMagicLinkService()
+
+		err := magicLinkService.RequestLink(context.Background(), "", testFingerprint)
+
+		assert.EqualError(t, err, "Email is required")
+	})
+
+	t.Run("Fail to send email looks like success", func(t *testing.T) {
+		user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+		magicLinkService, userRepo, magicLinkRepo, jwtUtil, _, mailer := newMagicLinkService()
+		logs := captureLogs(t)
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		magicLinkRepo.On("CreateMagicLink", mock.Anything, mock.Anything).Return(nil)
+		jwtUtil.On("GenerateMagicLinkToken", user.Email, mock.Anything, mock.Anything).Return("magicToken", nil)
+		mailer.On("Send", user.Email, mock.Anything, mock.Anything).Return(errors.New("smtp down"))
+
+		err := magicLinkService.RequestLink(context.Background(), user.Email, testFingerprint)
+
+		assert.NoError(t, err)
+		assert.Contains(t, logs.String(), "Failed to send email: smtp down")
+	})
+
+	t.Run("Fail to create magic link looks like success", func(t *testing.T) {
+		user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+		magicLinkService, userRepo, magicLinkRepo, _, _, mailer := newMagicLinkService()
+		logs := captureLogs(t)
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		magicLinkRepo.On("CreateMagicLink", mock.Anything, mock.Anything).Return(errors.New("database is down"))
+
+		err := magicLinkService.RequestLink(context.Background(), user.Email, testFingerprint)
+
+		assert.NoError(t, err)
+		assert.Contains(t, logs.String(), "Failed to create magic link: database is down")
+		mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMagicLinkLogin(t *testing.T) {
+	t.Run("Login success", func(t *testing.T) {
+		user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+		magicLinkService, userRepo, magicLinkRepo, jwtUtil, sessionService, _ := newMagicLinkService()
+
+		jwtUtil.On("ParseMagicLinkToken", "magicToken").Return(magicLinkClaims(user.Email), nil)
+		magicLinkRepo.On("MarkUsed", mock.Anything, "linkID").Return(true, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		sessionService.On("StartSession", mock.Anything, user, testClient).Return("jwtToken", nil)
+
+		token, result, err := magicLinkService.Login(context.Background(), "magicToken", testFingerprint, testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jwtToken", token)
+		assert.Equal(t, user, result)
+	})
+
+	t.Run("Other device", func(t *testing.T) {
+		magicLinkService, _, magicLinkRepo, jwtUtil, _, _ := newMagicLinkService()
+
+		jwtUtil.On("ParseMagicLinkToken", "magicToken").Return(magicLinkClaims("test@gmail.com"), nil)
+
+		_, _, err := magicLinkService.Login(context.Background(), "magicToken", "otherDevice|curl/8.0", testClient)
+
+		assert.EqualError(t, err, "This link must be opened in the browser where it was requested")
+		magicLinkRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Link already used", func(t *testing.T) {
+		magicLinkService, userRepo, magicLinkRepo, jwtUtil, _, _ := newMagicLinkService()
+
+		jwtUtil.On("ParseMagicLinkToken", "magicToken").Return(magicLinkClaims("test@gmail.com"), nil)
+		magicLinkRepo.On("MarkUsed", mock.Anything, "linkID").Return(false, nil)
+
+		_, _, err := magicLinkService.Login(context.Background(), "magicToken", testFingerprint, testClient)
+
+		assert.EqualError(t, err, "Invalid or expired link")
+		userRepo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		magicLinkService, _, magicLinkRepo, jwtUtil, _, _ := newMagicLinkService()
+
+		jwtUtil.On("ParseMagicLinkToken", "forged").Return(nil, jwt.ErrTokenSignatureInvalid)
+
+		_, _, err := magicLinkService.Login(context.Background(), "forged", testFingerprint, testClient)
+
+		assert.EqualError(t, err, "Invalid or expired link")
+		magicLinkRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Two-factor enabled", func(t *testing.T) {
+		user := &models.Users{Email: "test@gmail.com", TwoFactorEnabled: true}
+
+		magicLinkService, userRepo, magicLinkRepo, jwtUtil, sessionService, _ := newMagicLinkService()
+
+		jwtUtil.On("ParseMagicLinkToken", "magicToken").Return(magicLinkClaims(user.Email), nil)
+		magicLinkRepo.On("MarkUsed", mock.Anything, "linkID").Return(true, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		jwtUtil.On("GenerateMFAToken", user.Email).Return("mfaToken", nil)
+
+		token, _, err := magicLinkService.Login(context.Background(), "magicToken", testFingerprint, testClient)
+
+		assert.ErrorIs(t, err, services.ErrMFARequired)
+		assert.Equal(t, "mfaToken", token)
+		sessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
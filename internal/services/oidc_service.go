@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcStateTTL        = 10 * time.Minute
+	oidcExchangeTimeout = 10 * time.Second
+)
+
+type OIDCServiceInterface interface {
+	BeginLogin(ctx context.Context, provider string) (string, string, error)
+	FinishLogin(ctx context.Context, provider string, state string, code string, client ClientInfo) (string, *models.Users, error)
+}
+
+type OIDCService struct {
+	userRepo       repositories.UserRepositoryInterface
+	oauthRepo      repositories.OAuthRepositoryInterface
+	hashUtil       utils.HashInterface
+	jwtUtil        utils.JwtInterface
+	sessionService SessionServiceInterface
+	providers      map[string]utils.OIDCProviderInterface
+}
+
+func NewOIDCService(userRepo repositories.UserRepositoryInterface, oauthRepo repositories.OAuthRepositoryInterface, hashUtil utils.HashInterface, jwtUtil utils.JwtInterface, sessionService SessionServiceInterface, providers map[string]utils.OIDCProviderInterface) *OIDCService {
+	return &OIDCService{userRepo: userRepo, oauthRepo: oauthRepo, hashUtil: hashUtil, jwtUtil: jwtUtil, sessionService: sessionService, providers: providers}
+}
+
+// NOTE - คืน state กับ URL ของ provider ให้ handler redirect ไป
+func (s *OIDCService) BeginLogin(ctx context.Context, provider string) (string, string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", errors.New("Unknown login provider")
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to generate state: %w", err)
+	}
+
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to generate nonce: %w", err)
+	}
+
+	codeVerifier := oauth2.GenerateVerifier()
+
+	err = s.oauthRepo.CreateLoginState(ctx, &models.OIDCLoginStates{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to save login state: %w", err)
+	}
+
+	return state, p.AuthCodeURL(state, nonce, codeVerifier), nil
+}
+
+func (s *OIDCService) FinishLogin(ctx context.Context, provider string, state string, code string, client ClientInfo) (string, *models.Users, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", nil, errors.New("Unknown login provider")
+	}
+
+	if state == "" || code == "" {
+		return "", nil, errors.New("State and code are required")
+	}
+
+	loginState, err := s.oauthRepo.ConsumeLoginState(ctx, state)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to load login state: %w", err)
+	}
+	if loginState == nil || loginState.Provider != provider {
+		return "", nil, errors.New("Invalid or expired login session")
+	}
+
+	exchangeCtx, cancel := context.WithTimeout(ctx, oidcExchangeTimeout)
+	defer cancel()
+
+	identity, err := p.Exchange(exchangeCtx, code, loginState.CodeVerifier)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to verify identity: %w", err)
+	}
+
+	// NOTE - nonce ต้องตรงกับที่ส่งไปตอน begin กัน ID token ถูกนำมาใช้ซ้ำ
+	if subtle.ConstantTimeCompare([]byte(identity.Nonce), []byte(loginState.Nonce)) != 1 {
+		return "", nil, errors.New("Invalid nonce")
+	}
+
+	user, err := s.findOrLinkUser(ctx, provider, identity)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// NOTE - เปิด 2FA ไว้ต้องยืนยันต่อเหมือน login ด้วย password
+	if user.TwoFactorEnabled {
+		mfaToken, err := s.jwtUtil.GenerateMFAToken(user.Email)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to generate token: %w", err)
+		}
+		return mfaToken, user, ErrMFARequired
+	}
+
+	token, err := s.sessionService.StartSession(ctx, user, client)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, user, nil
+}
+
+// NOTE - เคยผูกแล้วใช้ user เดิม ไม่งั้นผูกกับ user ที่ email ตรง (เฉพาะ email ที่ provider ยืนยันแล้ว) หรือสร้าง user ใหม่
+func (s *OIDCService) findOrLinkUser(ctx context.Context, provider string, identity *utils.OIDCIdentity) (*models.Users, error) {
+	linked, err := s.oauthRepo.FindIdentity(ctx, provider, identity.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find identity: %w", err)
+	}
+
+	if linked != nil {
+		user, err := s.userRepo.FindUserById(ctx, strconv.FormatUint(uint64(linked.UserID), 10))
+		if err != nil || user == nil {
+			return nil, errors.New("User not found")
+		}
+		return user, nil
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errors.New("Email from provider is not verified")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, fmt.Errorf("Fail To Check Email : %w", err)
+	}
+
+	if user == nil {
+		user, err = s.createUser(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.oauthRepo.CreateIdentity(ctx, &models.OAuthIdentities{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to link identity: %w", err)
+	}
+
+	return user, nil
+}
+
+// NOTE - user ที่สมัครผ่าน provider ได้ password สุ่มที่ไม่มีใครรู้ ถ้าอยากใช้ password ให้ไป reset เอา
+func (s *OIDCService) createUser(ctx context.Context, identity *utils.OIDCIdentity) (*models.Users, error) {
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate password: %w", err)
+	}
+
+	hashedPassword, err := hashPassword(s.hashUtil, randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+
+	user := &models.Users{
+		Email:    identity.Email,
+		Name:     name,
+		Password: hashedPassword,
+	}
+
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("Failed to create user: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type OIDCServiceMock struct {
+	mock.Mock
+}
+
+func NewOIDCServiceMock() *OIDCServiceMock {
+	return &OIDCServiceMock{}
+}
+
+func (m *OIDCServiceMock) BeginLogin(ctx context.Context, provider string) (string, string, error) {
+	args := m.Called(ctx, provider)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *OIDCServiceMock) FinishLogin(ctx context.Context, provider string, state string, code string, client ClientInfo) (string, *models.Users, error) {
+	args := m.Called(ctx, provider, state, code, client)
+	if user, ok := args.Get(1).(*models.Users); ok {
+		return args.String(0), user, args.Error(2)
+	}
+	return args.String(0), nil, args.Error(2)
+}
@@ -0,0 +1,262 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/Beluga-Whale/management-api/tests/oidcmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type oidcFixture struct {
+	provider  *oidcmock.Server
+	service   *services.OIDCService
+	userRepo  *repositories.UserRepositoryMock
+	oauthRepo *repositories.OAuthRepositoryMock
+	hashUtil  *utils.HashMock
+	jwtUtil   *utils.JwtMock
+	sessions  *services.SessionServiceMock
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	provider := oidcmock.NewServer()
+	t.Cleanup(provider.Close)
+
+	mockProvider, err := utils.NewOIDCProvider(context.Background(), provider.URL, oidcmock.ClientID, oidcmock.ClientSecret, "http://localhost:8080/api/user/oidc/mock/callback")
+	require.NoError(t, err)
+
+	f := &oidcFixture{
+		provider:  provider,
+		userRepo:  repositories.NewUserRepositoryMock(),
+		oauthRepo: repositories.NewOAuthRepositoryMock(),
+		hashUtil:  utils.NewHashMock(),
+		jwtUtil:   utils.NewJwtMock(),
+		sessions:  services.NewSessionServiceMock(),
+	}
+	f.service = services.NewOIDCService(f.userRepo, f.oauthRepo, f.hashUtil, f.jwtUtil, f.sessions, map[string]utils.OIDCProviderInterface{"mock": mockProvider})
+
+	return f
+}
+
+// NOTE - เริ่ม login แล้วตาม redirect ไปที่ provider เหมือน browser คืน state ที่บันทึกไว้กับ code ที่ได้กลับมา
+func (f *oidcFixture) authorize(t *testing.T) (*models.OIDCLoginStates, string) {
+	var saved *models.OIDCLoginStates
+	f.oauthRepo.On("CreateLoginState", mock.Anything, mock.AnythingOfType("*models.OIDCLoginStates")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*models.OIDCLoginStates)
+	}).Return(nil).Once()
+
+	state, authURL, err := f.service.BeginLogin(context.Background(), "mock")
+	require.NoError(t, err)
+	require.Equal(t, saved.State, state)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, state, location.Query().Get("state"))
+
+	return saved, location.Query().Get("code")
+}
+
+func TestOIDCBeginLogin(t *testing.T) {
+	t.Run("Begin login with PKCE and nonce", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		f.oauthRepo.On("CreateLoginState", mock.Anything, mock.AnythingOfType("*models.OIDCLoginStates")).Return(nil)
+
+		state, authURL, err := f.service.BeginLogin(context.Background(), "mock")
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, state)
+
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, state, parsed.Query().Get("state"))
+		assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+		assert.NotEmpty(t, parsed.Query().Get("code_challenge"))
+		assert.NotEmpty(t, parsed.Query().Get("nonce"))
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		_, _, err := f.service.BeginLogin(context.Background(), "unknown")
+
+		assert.EqualError(t, err, "Unknown login provider")
+		f.oauthRepo.AssertNotCalled(t, "CreateLoginState", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOIDCFinishLogin(t *testing.T) {
+	t.Run("Existing identity logs in", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 7}}
+
+		loginState, code := f.authorize(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, loginState.State).Return(loginState, nil)
+		f.oauthRepo.On("FindIdentity", mock.Anything, "mock", f.provider.Subject).Return(&models.OAuthIdentities{UserID: 7, Provider: "mock", Subject: f.provider.Subject}, nil)
+		f.userRepo.On("FindUserById", mock.Anything, "7").Return(user, nil)
+		f.sessions.On("StartSession", mock.Anything, user, testClient).Return("jwtToken", nil)
+
+		token, result, err := f.service.FinishLogin(context.Background(), "mock", loginState.State, code, testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jwtToken", token)
+		assert.Equal(t, user, result)
+		f.oauthRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Link to existing user by verified email", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		user := &models.Users{Email: f.provider.Email, Model: gorm.Model{ID: 3}}
+
+		loginState, code := f.authorize(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, loginState.State).Return(loginState, nil)
+		f.oauthRepo.On("FindIdentity", mock.Anything, "mock", f.provider.Subject).Return(nil, nil)
+		f.userRepo.On("FindByEmail", mock.Anything, f.provider.Email).Return(user, nil)
+		f.oauthRepo.On("CreateIdentity", mock.Anything, mock.MatchedBy(func(identity *models.OAuthIdentities) bool {
+			return identity.UserID == 3 && identity.Provider == "mock" && identity.Subject == f.provider.Subject
+		})).Return(nil)
+		f.sessions.On("StartSession", mock.Anything, user, testClient).Return("jwtToken", nil)
+
+		token, _, err := f.service.FinishLogin(context.Background(), "mock", loginState.State, code, testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jwtToken", token)
+		f.oauthRepo.AssertExpectations(t)
+		f.userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Create new user", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		loginState, code := f.authorize(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, loginState.State).Return(loginState, nil)
+		f.oauthRepo.On("FindIdentity", mock.Anything, "mock", f.provider.Subject).Return(nil, nil)
+		f.userRepo.On("FindByEmail", mock.Anything, f.provider.Email).Return(nil, nil)
+		f.hashUtil.On("HashPassword", mock.AnythingOfType("string")).Return("hashedPassword", nil)
+		f.userRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *models.Users) bool {
+			return user.Email == f.provider.Email && user.Name == f.provider.Name && user.Password == "hashedPassword"
+		})).Return(nil)
+		f.oauthRepo.On("CreateIdentity", mock.Anything, mock.AnythingOfType("*models.OAuthIdentities")).Return(nil)
+		f.sessions.On("StartSession", mock.Anything, mock.AnythingOfType("*models.Users"), testClient).Return("jwtToken", nil)
+
+		token, user, err := f.service.FinishLogin(context.Background(), "mock", loginState.State, code, testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jwtToken", token)
+		assert.Equal(t, f.provider.Email, user.Email)
+		f.userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject unverified email", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		f.provider.EmailVerified = false
+
+		loginState, code := f.authorize(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, loginState.State).Return(loginState, nil)
+		f.oauthRepo.On("FindIdentity", mock.Anything, "mock", f.provider.Subject).Return(nil, nil)
+
+		_, _, err := f.service.FinishLogin(context.Background(), "mock", loginState.State, code, testClient)
+
+		assert.EqualError(t, err, "Email from provider is not verified")
+		f.userRepo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Two-factor enabled", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		user := &models.Users{Email: "test@gmail.com", TwoFactorEnabled: true, Model: gorm.Model{ID: 7}}
+
+		loginState, code := f.authorize(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, loginState.State).Return(loginState, nil)
+		f.oauthRepo.On("FindIdentity", mock.Anything, "mock", f.provider.Subject).Return(&models.OAuthIdentities{UserID: 7}, nil)
+		f.userRepo.On("FindUserById", mock.Anything, "7").Return(user, nil)
+		f.jwtUtil.On("GenerateMFAToken", user.Email).Return("mfaToken", nil)
+
+		token, _, err := f.service.FinishLogin(context.Background(), "mock", loginState.State, code, testClient)
+
+		assert.ErrorIs(t, err, services.ErrMFARequired)
+		assert.Equal(t, "mfaToken", token)
+		f.sessions.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown or reused state", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, "state").Return(nil, nil)
+
+		_, _, err := f.service.FinishLogin(context.Background(), "mock", "state", "code", testClient)
+
+		assert.EqualError(t, err, "Invalid or expired login session")
+	})
+
+	t.Run("State from other provider", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, "state").Return(&models.OIDCLoginStates{State: "state", Provider: "other"}, nil)
+
+		_, _, err := f.service.FinishLogin(context.Background(), "mock", "state", "code", testClient)
+
+		assert.EqualError(t, err, "Invalid or expired login session")
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		loginState, code := f.authorize(t)
+		stolen := *loginState
+		stolen.CodeVerifier = "attacker-verifier-attacker-verifier-attacker"
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, loginState.State).Return(&stolen, nil)
+
+		_, _, err := f.service.FinishLogin(context.Background(), "mock", loginState.State, code, testClient)
+
+		assert.ErrorContains(t, err, "Failed to verify identity")
+		f.oauthRepo.AssertNotCalled(t, "FindIdentity", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Nonce mismatch", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		loginState, code := f.authorize(t)
+		replayed := *loginState
+		replayed.Nonce = "other-nonce"
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, loginState.State).Return(&replayed, nil)
+
+		_, _, err := f.service.FinishLogin(context.Background(), "mock", loginState.State, code, testClient)
+
+		assert.EqualError(t, err, "Invalid nonce")
+	})
+
+	t.Run("Fail to load state", func(t *testing.T) {
+		f := newOIDCFixture(t)
+
+		f.oauthRepo.On("ConsumeLoginState", mock.Anything, "state").Return(nil, errors.New("DB error"))
+
+		_, _, err := f.service.FinishLogin(context.Background(), "mock", "state", "code", testClient)
+
+		assert.ErrorContains(t, err, "Failed to load login state")
+	})
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+const resetTokenTTL = 30 * time.Minute
+
+type PasswordServiceInterface interface {
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string, client ClientInfo) error
+	ChangePassword(ctx context.Context, email string, currentPassword string, newPassword string, client ClientInfo) (string, error)
+}
+
+type PasswordService struct {
+	userRepo       repositories.UserRepositoryInterface
+	resetRepo      repositories.PasswordResetRepositoryInterface
+	tokenRepo      repositories.AccessTokenRepositoryInterface
+	hashUtil       utils.HashInterface
+	sessionService SessionServiceInterface
+	auditService   AuditServiceInterface
+	mailer         utils.MailerInterface
+	resetURL       string
+}
+
+func NewPasswordService(userRepo repositories.UserRepositoryInterface, resetRepo repositories.PasswordResetRepositoryInterface, tokenRepo repositories.AccessTokenRepositoryInterface, hashUtil utils.HashInterface, sessionService SessionServiceInterface, auditService AuditServiceInterface, mailer utils.MailerInterface, resetURL string) *PasswordService {
+	return &PasswordService{userRepo: userRepo, resetRepo: resetRepo, tokenRepo: tokenRepo, hashUtil: hashUtil, sessionService: sessionService, auditService: auditService, mailer: mailer, resetURL: resetURL}
+}
+
+func (s *PasswordService) ForgotPassword(ctx context.Context, email string) error {
+	if email == "" {
+		return errors.New("Email is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("Fail To Check Email : %w", err)
+	}
+
+	// NOTE - ไม่บอกว่าไม่มี email นี้ในระบบ กันการเดา email
+	if user == nil {
+		return nil
+	}
+
+	// NOTE - ส่งไม่สำเร็จก็ตอบเหมือนส่งได้ ไม่งั้น error จะเกิดเฉพาะ email ที่มีอยู่จริงแล้วใช้เดา email ได้
+	if err := s.sendResetLink(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "Failed to send reset password email", "user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+func (s *PasswordService) sendResetLink(ctx context.Context, user *models.Users) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return fmt.Errorf("Failed to generate token: %w", err)
+	}
+
+	reset := &models.PasswordResets{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(resetTokenTTL),
+	}
+
+	if err := s.resetRepo.CreatePasswordReset(ctx, reset); err != nil {
+		return fmt.Errorf("Failed to create reset token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s?token=%s", s.resetURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.",
+		user.Name, int(resetTokenTTL.Minutes()), link)
+
+	if err := s.mailer.Send(user.Email, "Reset your password", body); err != nil {
+		return fmt.Errorf("Failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PasswordService) ResetPassword(ctx context.Context, token string, newPassword string, client ClientInfo) error {
+	if token == "" {
+		return errors.New("Token is required")
+	}
+
+	reset, err := s.resetRepo.FindValidByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return fmt.Errorf("Fail To Check Token : %w", err)
+	}
+
+	if reset == nil {
+		return errors.New("Invalid or expired token")
+	}
+
+	hashedPassword, err := hashPassword(s.hashUtil, newPassword)
+	if err != nil {
+		return err
+	}
+
+	// NOTE - mark ว่าใช้แล้วก่อน ถ้ามีอีก request ใช้ token เดียวกันพร้อมกันจะไม่ผ่าน
+	used, err := s.resetRepo.MarkUsed(ctx, reset.ID)
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return errors.New("Invalid or expired token")
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, reset.UserID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := s.resetRepo.InvalidateByUserId(ctx, reset.UserID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:  models.AuditPasswordReset,
+		ActorID: reset.UserID,
+		Client:  client,
+	})
+
+	return s.revokeCredentials(ctx, reset.UserID)
+}
+
+func (s *PasswordService) ChangePassword(ctx context.Context, email string, currentPassword string, newPassword string, client ClientInfo) (string, error) {
+	if currentPassword == "" || newPassword == "" {
+		return "", errors.New("Current password and new password is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return "", errors.New("User not found")
+	}
+
+	if !s.hashUtil.CheckPassword(user, currentPassword) {
+		return "", errors.New("Current password is incorrect")
+	}
+
+	hashedPassword, err := hashPassword(s.hashUtil, newPassword)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return "", err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:     models.AuditPasswordChange,
+		ActorID:    user.ID,
+		ActorEmail: user.Email,
+		Client:     client,
+	})
+
+	// NOTE - token เก่าทั้งหมดใช้ไม่ได้แล้ว ปิด session เก่าแล้วเปิด session ใหม่ให้เครื่องนี้
+	// ไม่ใช่การ login ใหม่ เลยไม่ผ่าน StartSession (ไม่งั้นจะได้ audit login และ metric login สำเร็จเกินมา)
+	if err := s.revokeCredentials(ctx, user.ID); err != nil {
+		return "", err
+	}
+
+	token, err := s.sessionService.ReissueSession(ctx, user, client)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// NOTE - password เปลี่ยนแล้ว ทั้ง session และ personal access token ที่ออกไปก่อนหน้าต้องใช้ไม่ได้
+// ไม่งั้น token ที่หลุดไปพร้อม password เก่ายังใช้ต่อได้
+func (s *PasswordService) revokeCredentials(ctx context.Context, userID uint) error {
+	if err := s.sessionService.RevokeUserSessions(ctx, userID); err != nil {
+		return err
+	}
+	return s.tokenRepo.RevokeAccessTokensByUserId(ctx, userID)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordServiceMock struct {
+	mock.Mock
+}
+
+func NewPasswordServiceMock() *PasswordServiceMock {
+	return &PasswordServiceMock{}
+}
+
+func (m *PasswordServiceMock) ForgotPassword(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *PasswordServiceMock) ResetPassword(ctx context.Context, token string, newPassword string, client ClientInfo) error {
+	args := m.Called(ctx, token, newPassword, client)
+	return args.Error(0)
+}
+
+func (m *PasswordServiceMock) ChangePassword(ctx context.Context, email string, currentPassword string, newPassword string, client ClientInfo) (string, error) {
+	args := m.Called(ctx, email, currentPassword, newPassword, client)
+	return args.String(0), args.Error(1)
+}
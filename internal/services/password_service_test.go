@@ -0,0 +1,254 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newPasswordService() (*services.PasswordService, *repositories.UserRepositoryMock, *repositories.PasswordResetRepositoryMock, *utils.HashMock, *services.SessionServiceMock, *utils.MailerMock, *repositories.AccessTokenRepositoryMock) {
+	userRepo := repositories.NewUserRepositoryMock()
+	resetRepo := repositories.NewPasswordResetRepositoryMock()
+	tokenRepo := repositories.NewAccessTokenRepositoryMock()
+	hashUtil := utils.NewHashMock()
+	sessionService := services.NewSessionServiceMock()
+	mailer := utils.NewMailerMock()
+
+	passwordService := services.NewPasswordService(userRepo, resetRepo, tokenRepo, hashUtil, sessionService, newAuditRecorder(), mailer, "http://localhost:3000/reset-password")
+
+	return passwordService, userRepo, resetRepo, hashUtil, sessionService, mailer, tokenRepo
+}
+
+func TestForgotPassword(t *testing.T) {
+	t.Run("Forgot password success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Name:  "tester",
+			Model: gorm.Model{ID: 1},
+		}
+
+		passwordService, userRepo, resetRepo, _, _, mailer, _ := newPasswordService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		resetRepo.On("CreatePasswordReset", mock.Anything, mock.MatchedBy(func(reset *models.PasswordResets) bool {
+			return reset.UserID == user.ID && reset.TokenHash != ""
+		})).Return(nil)
+		mailer.On("Send", user.Email, "Reset your password", mock.MatchedBy(func(body string) bool {
+			return strings.Contains(body, "http://localhost:3000/reset-password?token=")
+		})).Return(nil)
+
+		err := passwordService.ForgotPassword(context.Background(), user.Email)
+
+		assert.NoError(t, err)
+		userRepo.AssertExpectations(t)
+		resetRepo.AssertExpectations(t)
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("Email is required", func(t *testing.T) {
+		passwordService, _, _, _, _, _, _ := newPasswordService()
+
+		err := passwordService.ForgotPassword(context.Background(), "")
+
+		assert.EqualError(t, err, "Email is required")
+	})
+
+	t.Run("Unknown email does not send mail", func(t *testing.T) {
+		passwordService, userRepo, resetRepo, _, _, mailer, _ := newPasswordService()
+
+		userRepo.On("FindByEmail", mock.Anything, "unknown@gmail.com").Return(nil, nil)
+
+		err := passwordService.ForgotPassword(context.Background(), "unknown@gmail.com")
+
+		assert.NoError(t, err)
+		resetRepo.AssertNotCalled(t, "CreatePasswordReset", mock.Anything, mock.Anything)
+		mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail to send email looks like success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Model: gorm.Model{ID: 1},
+		}
+
+		passwordService, userRepo, resetRepo, _, _, mailer, _ := newPasswordService()
+		logs := captureLogs(t)
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		resetRepo.On("CreatePasswordReset", mock.Anything, mock.Anything).Return(nil)
+		mailer.On("Send", user.Email, mock.Anything, mock.Anything).Return(errors.New("smtp down"))
+
+		err := passwordService.ForgotPassword(context.Background(), user.Email)
+
+		assert.NoError(t, err)
+		assert.Contains(t, logs.String(), "Failed to send email: smtp down")
+	})
+
+	t.Run("Fail to create reset token looks like success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Model: gorm.Model{ID: 1},
+		}
+
+		passwordService, userRepo, resetRepo, _, _, mailer, _ := newPasswordService()
+		logs := captureLogs(t)
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		resetRepo.On("CreatePasswordReset", mock.Anything, mock.Anything).Return(errors.New("database is down"))
+
+		err := passwordService.ForgotPassword(context.Background(), user.Email)
+
+		assert.NoError(t, err)
+		assert.Contains(t, logs.String(), "Failed to create reset token: database is down")
+		mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("Reset password success", func(t *testing.T) {
+		token := "resetToken"
+		reset := &models.PasswordResets{
+			UserID: 1,
+			Model:  gorm.Model{ID: 10},
+		}
+
+		passwordService, userRepo, resetRepo, hashUtil, sessionService, _, tokenRepo := newPasswordService()
+
+		resetRepo.On("FindValidByTokenHash", mock.Anything, utils.HashToken(token)).Return(reset, nil)
+		hashUtil.On("HashPassword", "newPassword").Return("hashedPassword", nil)
+		resetRepo.On("MarkUsed", mock.Anything, reset.ID).Return(true, nil)
+		userRepo.On("UpdatePassword", mock.Anything, reset.UserID, "hashedPassword").Return(nil)
+		resetRepo.On("InvalidateByUserId", mock.Anything, reset.UserID).Return(nil)
+		sessionService.On("RevokeUserSessions", mock.Anything, reset.UserID).Return(nil)
+		tokenRepo.On("RevokeAccessTokensByUserId", mock.Anything, reset.UserID).Return(nil)
+
+		err := passwordService.ResetPassword(context.Background(), token, "newPassword", testClient)
+
+		assert.NoError(t, err)
+		resetRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+		sessionService.AssertExpectations(t)
+		tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid or expired token", func(t *testing.T) {
+		token := "resetToken"
+
+		passwordService, _, resetRepo, _, _, _, _ := newPasswordService()
+
+		resetRepo.On("FindValidByTokenHash", mock.Anything, utils.HashToken(token)).Return(nil, nil)
+
+		err := passwordService.ResetPassword(context.Background(), token, "newPassword", testClient)
+
+		assert.EqualError(t, err, "Invalid or expired token")
+	})
+
+	t.Run("Token already used", func(t *testing.T) {
+		token := "resetToken"
+		reset := &models.PasswordResets{
+			UserID: 1,
+			Model:  gorm.Model{ID: 10},
+		}
+
+		passwordService, userRepo, resetRepo, hashUtil, _, _, _ := newPasswordService()
+
+		resetRepo.On("FindValidByTokenHash", mock.Anything, utils.HashToken(token)).Return(reset, nil)
+		hashUtil.On("HashPassword", "newPassword").Return("hashedPassword", nil)
+		resetRepo.On("MarkUsed", mock.Anything, reset.ID).Return(false, nil)
+
+		err := passwordService.ResetPassword(context.Background(), token, "newPassword", testClient)
+
+		assert.EqualError(t, err, "Invalid or expired token")
+		userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Password too short", func(t *testing.T) {
+		token := "resetToken"
+		reset := &models.PasswordResets{
+			UserID: 1,
+			Model:  gorm.Model{ID: 10},
+		}
+
+		passwordService, _, resetRepo, _, _, _, _ := newPasswordService()
+
+		resetRepo.On("FindValidByTokenHash", mock.Anything, utils.HashToken(token)).Return(reset, nil)
+
+		err := passwordService.ResetPassword(context.Background(), token, "abc", testClient)
+
+		assert.EqualError(t, err, "Password must more 6 char ")
+		resetRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	t.Run("Change password success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Model: gorm.Model{ID: 1},
+		}
+
+		passwordService, userRepo, _, hashUtil, sessionService, _, tokenRepo := newPasswordService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		hashUtil.On("CheckPassword", user, "oldPassword").Return(true)
+		hashUtil.On("HashPassword", "newPassword").Return("hashedPassword", nil)
+		userRepo.On("UpdatePassword", mock.Anything, user.ID, "hashedPassword").Return(nil)
+		sessionService.On("RevokeUserSessions", mock.Anything, user.ID).Return(nil)
+		tokenRepo.On("RevokeAccessTokensByUserId", mock.Anything, user.ID).Return(nil)
+		sessionService.On("ReissueSession", mock.Anything, user, testClient).Return("newToken", nil)
+
+		token, err := passwordService.ChangePassword(context.Background(), user.Email, "oldPassword", "newPassword", testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "newToken", token)
+		userRepo.AssertExpectations(t)
+		hashUtil.AssertExpectations(t)
+		sessionService.AssertExpectations(t)
+		tokenRepo.AssertExpectations(t)
+		sessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Current password is incorrect", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Model: gorm.Model{ID: 1},
+		}
+
+		passwordService, userRepo, _, hashUtil, _, _, _ := newPasswordService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		hashUtil.On("CheckPassword", user, "wrongPassword").Return(false)
+
+		_, err := passwordService.ChangePassword(context.Background(), user.Email, "wrongPassword", "newPassword", testClient)
+
+		assert.EqualError(t, err, "Current password is incorrect")
+		userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Password is required", func(t *testing.T) {
+		passwordService, _, _, _, _, _, _ := newPasswordService()
+
+		_, err := passwordService.ChangePassword(context.Background(), "test@gmail.com", "", "", testClient)
+
+		assert.EqualError(t, err, "Current password and new password is required")
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		passwordService, userRepo, _, _, _, _, _ := newPasswordService()
+
+		userRepo.On("FindByEmail", mock.Anything, "test@gmail.com").Return(nil, nil)
+
+		_, err := passwordService.ChangePassword(context.Background(), "test@gmail.com", "oldPassword", "newPassword", testClient)
+
+		assert.EqualError(t, err, "User not found")
+	})
+}
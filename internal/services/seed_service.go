@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/tracing"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+// NOTE - Postgres รับ parameter ได้ไม่เกิน 65535 ต่อ statement task มี ~10 column ต่อแถว batch ไม่ควรเกินราว 5000
+const DefaultSeedBatchSize = 500
+
+type SeedOptions struct {
+	Users        int
+	TasksPerUser int
+	Seed         uint64
+	BatchSize    int
+	// NOTE - user ที่ generate ทุกคนใช้ password นี้ hash ครั้งเดียวพอ bcrypt ทีละคนช้าเกินไป
+	Password string
+	// NOTE - DueDate/CreatedAt คิดเทียบกับเวลานี้ ให้ overdue/pending มีข้อมูลเสมอไม่ว่าจะ seed เมื่อไหร่
+	Now time.Time
+}
+
+type SeedResult struct {
+	Users      int
+	Tasks      int
+	Completed  int
+	Overdue    int
+	Pending    int
+	FirstEmail string
+}
+
+type SeedServiceInterface interface {
+	Generate(ctx context.Context, opts SeedOptions) (*SeedResult, error)
+}
+
+type SeedService struct {
+	userRepo repositories.UserRepositoryInterface
+	uow      repositories.UnitOfWorkInterface
+	hashUtil utils.HashInterface
+}
+
+func NewSeedService(userRepo repositories.UserRepositoryInterface, uow repositories.UnitOfWorkInterface, hashUtil utils.HashInterface) *SeedService {
+	return &SeedService{userRepo: userRepo, uow: uow, hashUtil: hashUtil}
+}
+
+// NOTE - seed เดียวกันได้ข้อมูลเหมือนเดิมทุกครั้ง (ชื่อ, email, task) ไม่ขึ้นกับ BatchSize
+// ทั้งหมดอยู่ใน transaction เดียว พังกลางทางไม่เหลือข้อมูลครึ่งๆ กลางๆ
+func (s *SeedService) Generate(ctx context.Context, opts SeedOptions) (*SeedResult, error) {
+	ctx, span := tracing.Start(ctx, "SeedService.Generate")
+	defer span.End()
+
+	if opts.Users <= 0 {
+		return nil, errors.New("Users must be greater than 0")
+	}
+	if opts.TasksPerUser < 0 {
+		return nil, errors.New("Tasks per user can't be negative")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultSeedBatchSize
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	hashedPassword, err := hashPassword(s.hashUtil, opts.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeedResult{FirstEmail: newSeedUser(opts.Seed, 0).user.Email}
+	existing, err := s.userRepo.FindByEmail(ctx, result.FirstEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Fail To Check Email : %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Seed %d has already been generated", opts.Seed)
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+		// NOTE - ทำทีละ BatchSize คน ไม่ต้องถือ task ทั้งหมดไว้ใน memory ตอน volume ใหญ่
+		for start := 0; start < opts.Users; start += opts.BatchSize {
+			end := min(start+opts.BatchSize, opts.Users)
+
+			users := make([]models.Users, 0, end-start)
+			generators := make([]*seedGenerator, 0, end-start)
+			for i := start; i < end; i++ {
+				generator := newSeedUser(opts.Seed, i)
+				generator.user.Password = hashedPassword
+				users = append(users, generator.user)
+				generators = append(generators, generator)
+			}
+			if err := repos.Users.CreateUsers(ctx, users, opts.BatchSize); err != nil {
+				return fmt.Errorf("Failed to create seed users: %w", err)
+			}
+
+			tasks := make([]models.Tasks, 0, opts.BatchSize)
+			for i, generator := range generators {
+				for j := 0; j < opts.TasksPerUser; j++ {
+					task := generator.task(opts.Now, users[i].ID)
+					result.count(task, opts.Now)
+					tasks = append(tasks, task)
+
+					if len(tasks) == opts.BatchSize {
+						if err := repos.Tasks.CreateTasks(ctx, tasks, opts.BatchSize); err != nil {
+							return fmt.Errorf("Failed to create seed tasks: %w", err)
+						}
+						tasks = tasks[:0]
+					}
+				}
+			}
+			if len(tasks) > 0 {
+				if err := repos.Tasks.CreateTasks(ctx, tasks, opts.BatchSize); err != nil {
+					return fmt.Errorf("Failed to create seed tasks: %w", err)
+				}
+			}
+
+			result.Users += len(users)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// NOTE - นับแบบเดียวกับ FindTaskOverdue/FindTaskPending ดูแค่ due date ไม่สน completed
+// ตัวเลขที่ seed พิมพ์ออกมาจะได้ตรงกับที่ /task/overdue และ /task/pending ตอบ
+func (r *SeedResult) count(task models.Tasks, now time.Time) {
+	r.Tasks++
+	if task.Completed {
+		r.Completed++
+	}
+	if task.DueDate.Before(now) {
+		r.Overdue++
+	} else {
+		r.Pending++
+	}
+}
+
+var (
+	seedFirstNames = []string{"Ava", "Noah", "Mali", "Krit", "Sofia", "Liam", "Ploy", "Arthit", "Emma", "Lucas", "Nina", "Chen", "Yuki", "Omar", "Isla", "Mateo", "Zara", "Ethan", "Ananya", "Leo"}
+	seedLastNames  = []string{"Smith", "Srisuk", "Tanaka", "Garcia", "Wong", "Johnson", "Chaiyaporn", "Muller", "Rossi", "Kim", "Nguyen", "Brown", "Patel", "Silva", "Jensen", "Cohen"}
+	seedVerbs      = []string{"Review", "Draft", "Update", "Fix", "Plan", "Prepare", "Test", "Refactor", "Schedule", "Document", "Deploy", "Research"}
+	seedObjects    = []string{"quarterly report", "onboarding guide", "login page", "sprint backlog", "release notes", "billing module", "customer survey", "API docs", "team offsite", "budget sheet", "landing page", "database backup"}
+	seedDetails    = []string{"Coordinate with the design team before starting.", "Blocked until the previous milestone is signed off.", "Share a summary in the weekly sync.", "Follow the checklist in the team wiki.", "Keep the scope small and ship early."}
+)
+
+// NOTE - แต่ละ user มี random stream ของตัวเองจาก (seed, index) ข้อมูลของ user ที่ i เลยไม่ขึ้นกับลำดับการ insert
+type seedGenerator struct {
+	rng  *rand.Rand
+	user models.Users
+}
+
+func newSeedUser(seed uint64, index int) *seedGenerator {
+	rng := rand.New(rand.NewPCG(seed, uint64(index)))
+	first := seedFirstNames[rng.IntN(len(seedFirstNames))]
+	last := seedLastNames[rng.IntN(len(seedLastNames))]
+
+	return &seedGenerator{
+		rng: rng,
+		user: models.Users{
+			Name:  first + " " + last,
+			Email: fmt.Sprintf("%s.%s.%d.%d@seed.example.com", strings.ToLower(first), strings.ToLower(last), seed, index),
+			Role:  models.User,
+		},
+	}
+}
+
+// NOTE - สัดส่วนประมาณงานจริง priority ส่วนใหญ่ low, task ที่เสร็จแล้วมักเลย due มาแล้ว
+// และ task ที่ยังไม่เสร็จราว 1 ใน 3 เลย due (overdue) ที่เหลือยังไม่ถึง (pending)
+func (g *seedGenerator) task(now time.Time, userID uint) models.Tasks {
+	r := g.rng
+	day := 24 * time.Hour
+
+	priority := models.Low
+	switch p := r.Float64(); {
+	case p >= 0.85:
+		priority = models.High
+	case p >= 0.5:
+		priority = models.Medium
+	}
+
+	status := models.Active
+	if r.Float64() < 0.15 {
+		status = models.Inactive
+	}
+
+	completed := r.Float64() < 0.35
+	if status == models.Inactive {
+		completed = r.Float64() < 0.7
+	}
+
+	var dueDate time.Time
+	switch {
+	case completed && r.Float64() < 0.8:
+		dueDate = now.Add(-time.Duration(1+r.IntN(60)) * day)
+	case completed:
+		dueDate = now.Add(time.Duration(1+r.IntN(30)) * day)
+	case r.Float64() < 0.35:
+		dueDate = now.Add(-time.Duration(1+r.IntN(21)) * day)
+	default:
+		dueDate = now.Add(time.Duration(r.IntN(45))*day + time.Duration(1+r.IntN(23))*time.Hour)
+	}
+	dueDate = dueDate.Truncate(time.Hour)
+
+	createdAt := dueDate
+	if createdAt.After(now) {
+		createdAt = now
+	}
+	createdAt = createdAt.Add(-time.Duration(1+r.IntN(30*24)) * time.Hour)
+
+	title := seedVerbs[r.IntN(len(seedVerbs))] + " " + seedObjects[r.IntN(len(seedObjects))]
+
+	task := models.Tasks{
+		Title:       title,
+		Description: seedDetails[r.IntN(len(seedDetails))],
+		DueDate:     dueDate,
+		Status:      status,
+		Completed:   completed,
+		Priority:    priority,
+		UserID:      userID,
+	}
+	task.CreatedAt = createdAt
+	task.UpdatedAt = createdAt
+	return task
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type SeedServiceMock struct {
+	mock.Mock
+}
+
+func NewSeedServiceMock() *SeedServiceMock {
+	return &SeedServiceMock{}
+}
+
+func (m *SeedServiceMock) Generate(ctx context.Context, opts SeedOptions) (*SeedResult, error) {
+	args := m.Called(ctx, opts)
+	if result, ok := args.Get(0).(*SeedResult); ok {
+		return result, nil
+	}
+	return nil, args.Error(1)
+}
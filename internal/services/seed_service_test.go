@@ -0,0 +1,140 @@
+package services_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var seedNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newSeedService() (*services.SeedService, *repositories.TaskMemoryRepository, *repositories.UserMemoryRepository) {
+	taskRepo := repositories.NewTaskMemoryRepository()
+	userRepo := repositories.NewUserMemoryRepository()
+	hashUtil := utils.NewHashMock()
+	hashUtil.On("HashPassword", mock.Anything).Return("hashedPassword", nil)
+
+	return services.NewSeedService(userRepo, repositories.NewUnitOfWorkMock(taskRepo, userRepo), hashUtil), taskRepo, userRepo
+}
+
+func seededTasks(t *testing.T, opts services.SeedOptions) []models.Tasks {
+	seedService, taskRepo, userRepo := newSeedService()
+	result, err := seedService.Generate(context.Background(), opts)
+	require.NoError(t, err)
+
+	user, err := userRepo.FindByEmail(context.Background(), result.FirstEmail)
+	require.NoError(t, err)
+	tasks, err := taskRepo.FindTaskAll(context.Background(), user.ID, "")
+	require.NoError(t, err)
+	return tasks
+}
+
+func TestSeedGenerate(t *testing.T) {
+	t.Run("Realistic distribution", func(t *testing.T) {
+		seedService, taskRepo, userRepo := newSeedService()
+
+		result, err := seedService.Generate(context.Background(), services.SeedOptions{Users: 40, TasksPerUser: 25, Seed: 7, BatchSize: 64, Password: "secret123", Now: seedNow})
+
+		require.NoError(t, err)
+		assert.Equal(t, 40, result.Users)
+		assert.Equal(t, 1000, result.Tasks)
+
+		// NOTE - overdue และ pending ต้องมีข้อมูลทั้งคู่ ส่วน completed อยู่ราวๆ 1 ใน 3
+		assert.Greater(t, result.Overdue, 100)
+		assert.Greater(t, result.Pending, 300)
+		assert.InDelta(t, 400, result.Completed, 120)
+
+		counts := map[models.Priority]int{}
+		overdue, completed := 0, 0
+		for id := uint(1); id <= 40; id++ {
+			tasks, _ := taskRepo.FindTaskAll(context.Background(), id, "")
+			assert.Len(t, tasks, 25)
+			for _, task := range tasks {
+				counts[task.Priority]++
+				if task.DueDate.Before(seedNow) {
+					overdue++
+				}
+				if task.Completed {
+					completed++
+				}
+				assert.True(t, task.CreatedAt.Before(seedNow))
+				assert.False(t, task.CreatedAt.After(task.DueDate))
+			}
+		}
+		assert.Greater(t, counts[models.Low], counts[models.Medium])
+		assert.Greater(t, counts[models.Medium], counts[models.High])
+		assert.NotZero(t, counts[models.High])
+
+		// NOTE - overdue/pending แบ่งตาม due date อย่างเดียวเหมือน FindTaskOverdue/FindTaskPending
+		assert.Equal(t, overdue, result.Overdue)
+		assert.Equal(t, result.Tasks, result.Overdue+result.Pending)
+		assert.Equal(t, completed, result.Completed)
+
+		user, _ := userRepo.FindByEmail(context.Background(), result.FirstEmail)
+		assert.Equal(t, "hashedPassword", user.Password)
+	})
+
+	t.Run("Every task passes create task validation", func(t *testing.T) {
+		seedService, taskRepo, userRepo := newSeedService()
+		_, err := seedService.Generate(context.Background(), services.SeedOptions{Users: 10, TasksPerUser: 50, Seed: 7, Password: "secret123", Now: seedNow})
+		require.NoError(t, err)
+
+		// NOTE - ส่งทุก task เข้า CreateTask ตัวจริง validation เปลี่ยนเมื่อไหร่ seed ต้องตามด้วย
+		created := repositories.NewTaskMemoryRepository()
+		taskService := services.NewTaskService(created, userRepo, repositories.NewUnitOfWorkMock(created, userRepo), newMetricsRecorder())
+		for id := uint(1); id <= 10; id++ {
+			user, err := userRepo.FindUserById(context.Background(), strconv.FormatUint(uint64(id), 10))
+			require.NoError(t, err)
+			tasks, _ := taskRepo.FindTaskAll(context.Background(), id, "")
+			for _, task := range tasks {
+				input := models.Tasks{Title: task.Title, Description: task.Description, Priority: task.Priority, DueDate: task.DueDate}
+				assert.NoError(t, taskService.CreateTask(context.Background(), &input, user.Email), task.Title)
+			}
+		}
+	})
+
+	t.Run("Same seed gives same data regardless of batch size", func(t *testing.T) {
+		a := seededTasks(t, services.SeedOptions{Users: 5, TasksPerUser: 10, Seed: 42, BatchSize: 3, Password: "secret123", Now: seedNow})
+		b := seededTasks(t, services.SeedOptions{Users: 5, TasksPerUser: 10, Seed: 42, BatchSize: 500, Password: "secret123", Now: seedNow})
+		c := seededTasks(t, services.SeedOptions{Users: 5, TasksPerUser: 10, Seed: 43, Password: "secret123", Now: seedNow})
+
+		require.Len(t, a, 10)
+		for i := range a {
+			assert.Equal(t, a[i].Title, b[i].Title)
+			assert.Equal(t, a[i].DueDate, b[i].DueDate)
+			assert.Equal(t, a[i].Priority, b[i].Priority)
+			assert.Equal(t, a[i].Completed, b[i].Completed)
+		}
+		assert.NotEqual(t, a, c)
+	})
+
+	t.Run("Seed already generated", func(t *testing.T) {
+		seedService, _, _ := newSeedService()
+		opts := services.SeedOptions{Users: 2, TasksPerUser: 1, Seed: 1, Password: "secret123", Now: seedNow}
+
+		_, err := seedService.Generate(context.Background(), opts)
+		require.NoError(t, err)
+		_, err = seedService.Generate(context.Background(), opts)
+
+		assert.EqualError(t, err, "Seed 1 has already been generated")
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		seedService, _, _ := newSeedService()
+
+		_, err := seedService.Generate(context.Background(), services.SeedOptions{Password: "secret123"})
+		assert.EqualError(t, err, "Users must be greater than 0")
+
+		_, err = seedService.Generate(context.Background(), services.SeedOptions{Users: 1, Password: "123"})
+		assert.EqualError(t, err, "Password must more 6 char ")
+	})
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+// NOTE - เขียน last_seen ลง DB อย่างมากนาทีละครั้งต่อ session
+const lastSeenUpdateInterval = time.Minute
+
+// NOTE - บัญชีถูก admin ปิดไว้ ทุกทาง login จบที่ StartSession เลยเช็คที่นี่ที่เดียว
+var ErrUserDisabled = errors.New("Account has been disabled")
+
+// NOTE - ข้อมูลของ browser/device ที่ login เข้ามา
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type SessionServiceInterface interface {
+	StartSession(ctx context.Context, user *models.Users, client ClientInfo) (string, error)
+	ReissueSession(ctx context.Context, user *models.Users, client ClientInfo) (string, error)
+	Authenticate(ctx context.Context, sessionID string, userID uint, ip string) error
+	GetSessions(ctx context.Context, email string, currentSessionID string) ([]models.Sessions, error)
+	RevokeSession(ctx context.Context, email string, idStr string) error
+	RevokeOtherSessions(ctx context.Context, email string, currentSessionID string) error
+	RevokeUserSessions(ctx context.Context, userID uint) error
+	EndSession(ctx context.Context, sessionID string) error
+}
+
+type SessionService struct {
+	userRepo     repositories.UserRepositoryInterface
+	sessionRepo  repositories.SessionRepositoryInterface
+	jwtUtil      utils.JwtInterface
+	auditService AuditServiceInterface
+}
+
+func NewSessionService(userRepo repositories.UserRepositoryInterface, sessionRepo repositories.SessionRepositoryInterface, jwtUtil utils.JwtInterface, auditService AuditServiceInterface) *SessionService {
+	return &SessionService{userRepo: userRepo, sessionRepo: sessionRepo, jwtUtil: jwtUtil, auditService: auditService}
+}
+
+// NOTE - ทุกทางที่ login สำเร็จต้องออก token ผ่าน function นี้ จะได้มี session ให้ดู/revoke ได้
+func (s *SessionService) StartSession(ctx context.Context, user *models.Users, client ClientInfo) (string, error) {
+	token, err := s.createSession(ctx, user, client)
+	if err != nil {
+		return "", err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:     models.AuditLogin,
+		ActorID:    user.ID,
+		ActorEmail: user.Email,
+		Client:     client,
+	})
+
+	return token, nil
+}
+
+// NOTE - ออก session ใหม่ให้คนที่ login อยู่แล้ว (เช่นหลังเปลี่ยน password) ไม่นับเป็นการ login ใน audit log และ metric
+func (s *SessionService) ReissueSession(ctx context.Context, user *models.Users, client ClientInfo) (string, error) {
+	return s.createSession(ctx, user, client)
+}
+
+func (s *SessionService) createSession(ctx context.Context, user *models.Users, client ClientInfo) (string, error) {
+	if user.DisabledAt != nil {
+		return "", ErrUserDisabled
+	}
+
+	sessionID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate session: %w", err)
+	}
+
+	session := &models.Sessions{
+		UserID:     user.ID,
+		SessionID:  sessionID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: time.Now(),
+	}
+
+	if err := s.sessionRepo.CreateSession(ctx, session); err != nil {
+		return "", fmt.Errorf("Failed to create session: %w", err)
+	}
+
+	token, err := s.jwtUtil.GenerateSessionJWT(user.Email, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate token: %w", err)
+	}
+
+	return token, nil
+}
+
+// NOTE - เรียกทุก request จาก AuthMiddleware
+func (s *SessionService) Authenticate(ctx context.Context, sessionID string, userID uint, ip string) error {
+	session, err := s.sessionRepo.FindActiveBySessionID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return errors.New("Session has been revoked")
+	}
+
+	// NOTE - ไม่ต้องเขียน DB ทุก request ถ้าเพิ่งใช้ไปจาก IP เดิม
+	if time.Since(session.LastSeenAt) > lastSeenUpdateInterval || session.IP != ip {
+		if err := s.sessionRepo.UpdateLastSeen(ctx, session.ID, ip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SessionService) GetSessions(ctx context.Context, email string, currentSessionID string) ([]models.Sessions, error) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
+	}
+
+	sessions, err := s.sessionRepo.FindActiveSessionsByUserId(ctx, user.ID, time.Now().Add(-utils.JWTTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = currentSessionID != "" && sessions[i].SessionID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+func (s *SessionService) RevokeSession(ctx context.Context, email string, idStr string) error {
+	if idStr == "" {
+		return errors.New("Id is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return errors.New("User not found")
+	}
+
+	session, err := s.sessionRepo.FindSessionById(ctx, idStr)
+	if err != nil {
+		return fmt.Errorf("failed to find session by ID: %w", err)
+	}
+
+	if session.UserID != user.ID {
+		return errors.New("you do not have permission to access this session")
+	}
+
+	return s.sessionRepo.RevokeSession(ctx, session.ID)
+}
+
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, email string, currentSessionID string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return errors.New("User not found")
+	}
+
+	return s.sessionRepo.RevokeSessionsByUserId(ctx, user.ID, currentSessionID)
+}
+
+// NOTE - ใช้ตอนเปลี่ยน/reset password ปิดทุก session ของ user
+func (s *SessionService) RevokeUserSessions(ctx context.Context, userID uint) error {
+	return s.sessionRepo.RevokeSessionsByUserId(ctx, userID, "")
+}
+
+// NOTE - logout จาก session ปัจจุบัน
+func (s *SessionService) EndSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	session, err := s.sessionRepo.FindActiveBySessionID(ctx, sessionID)
+	if err != nil || session == nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeSession(ctx, session.ID)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionServiceMock struct {
+	mock.Mock
+}
+
+func NewSessionServiceMock() *SessionServiceMock {
+	return &SessionServiceMock{}
+}
+
+func (m *SessionServiceMock) StartSession(ctx context.Context, user *models.Users, client ClientInfo) (string, error) {
+	args := m.Called(ctx, user, client)
+	return args.String(0), args.Error(1)
+}
+
+func (m *SessionServiceMock) ReissueSession(ctx context.Context, user *models.Users, client ClientInfo) (string, error) {
+	args := m.Called(ctx, user, client)
+	return args.String(0), args.Error(1)
+}
+
+func (m *SessionServiceMock) Authenticate(ctx context.Context, sessionID string, userID uint, ip string) error {
+	args := m.Called(ctx, sessionID, userID, ip)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) GetSessions(ctx context.Context, email string, currentSessionID string) ([]models.Sessions, error) {
+	args := m.Called(ctx, email, currentSessionID)
+	if sessions, ok := args.Get(0).([]models.Sessions); ok {
+		return sessions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionServiceMock) RevokeSession(ctx context.Context, email string, idStr string) error {
+	args := m.Called(ctx, email, idStr)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) RevokeOtherSessions(ctx context.Context, email string, currentSessionID string) error {
+	args := m.Called(ctx, email, currentSessionID)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) RevokeUserSessions(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) EndSession(ctx context.Context, sessionID string) error {
+	args := m.Called(ctx, sessionID)
+	return args.Error(0)
+}
@@ -0,0 +1,217 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newSessionService() (*services.SessionService, *repositories.UserRepositoryMock, *repositories.SessionRepositoryMock, *utils.JwtMock) {
+	userRepo := repositories.NewUserRepositoryMock()
+	sessionRepo := repositories.NewSessionRepositoryMock()
+	jwtUtil := utils.NewJwtMock()
+
+	return services.NewSessionService(userRepo, sessionRepo, jwtUtil, newAuditRecorder()), userRepo, sessionRepo, jwtUtil
+}
+
+func TestStartSession(t *testing.T) {
+	user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+	t.Run("Start session success", func(t *testing.T) {
+		sessionService, _, sessionRepo, jwtUtil := newSessionService()
+
+		var saved *models.Sessions
+		sessionRepo.On("CreateSession", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*models.Sessions)
+		}).Return(nil)
+		jwtUtil.On("GenerateSessionJWT", user.Email, mock.AnythingOfType("string")).Return("jwtToken", nil)
+
+		token, err := sessionService.StartSession(context.Background(), user, testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jwtToken", token)
+		assert.Equal(t, user.ID, saved.UserID)
+		assert.NotEmpty(t, saved.SessionID)
+		assert.Equal(t, testClient.IP, saved.IP)
+		assert.Equal(t, testClient.UserAgent, saved.UserAgent)
+		jwtUtil.AssertCalled(t, "GenerateSessionJWT", user.Email, saved.SessionID)
+	})
+
+	t.Run("Disabled user", func(t *testing.T) {
+		sessionService, _, sessionRepo, jwtUtil := newSessionService()
+		disabledAt := time.Now()
+
+		token, err := sessionService.StartSession(context.Background(), &models.Users{Email: "test@gmail.com", DisabledAt: &disabledAt}, testClient)
+
+		assert.ErrorIs(t, err, services.ErrUserDisabled)
+		assert.Empty(t, token)
+		sessionRepo.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything)
+		jwtUtil.AssertNotCalled(t, "GenerateSessionJWT", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReissueSession(t *testing.T) {
+	user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+	t.Run("Create session without recording a login", func(t *testing.T) {
+		sessionRepo := repositories.NewSessionRepositoryMock()
+		jwtUtil := utils.NewJwtMock()
+		auditService := newAuditRecorder()
+		sessionService := services.NewSessionService(repositories.NewUserRepositoryMock(), sessionRepo, jwtUtil, auditService)
+
+		sessionRepo.On("CreateSession", mock.Anything, mock.Anything).Return(nil)
+		jwtUtil.On("GenerateSessionJWT", user.Email, mock.AnythingOfType("string")).Return("jwtToken", nil)
+
+		token, err := sessionService.ReissueSession(context.Background(), user, testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jwtToken", token)
+		sessionRepo.AssertNumberOfCalls(t, "CreateSession", 1)
+		auditService.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Start session records a login", func(t *testing.T) {
+		sessionRepo := repositories.NewSessionRepositoryMock()
+		jwtUtil := utils.NewJwtMock()
+		auditService := newAuditRecorder()
+		sessionService := services.NewSessionService(repositories.NewUserRepositoryMock(), sessionRepo, jwtUtil, auditService)
+
+		sessionRepo.On("CreateSession", mock.Anything, mock.Anything).Return(nil)
+		jwtUtil.On("GenerateSessionJWT", user.Email, mock.AnythingOfType("string")).Return("jwtToken", nil)
+
+		_, err := sessionService.StartSession(context.Background(), user, testClient)
+
+		assert.NoError(t, err)
+		auditService.AssertCalled(t, "Record", mock.Anything, mock.MatchedBy(func(event services.AuditEvent) bool {
+			return event.Action == models.AuditLogin && event.ActorID == user.ID
+		}))
+	})
+
+	t.Run("Disabled user", func(t *testing.T) {
+		sessionService, _, sessionRepo, _ := newSessionService()
+		disabledAt := time.Now()
+
+		_, err := sessionService.ReissueSession(context.Background(), &models.Users{Email: "test@gmail.com", DisabledAt: &disabledAt}, testClient)
+
+		assert.ErrorIs(t, err, services.ErrUserDisabled)
+		sessionRepo.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthenticateSession(t *testing.T) {
+	t.Run("Revoked session", func(t *testing.T) {
+		sessionService, _, sessionRepo, _ := newSessionService()
+
+		sessionRepo.On("FindActiveBySessionID", mock.Anything, "sid").Return(nil, nil)
+
+		err := sessionService.Authenticate(context.Background(), "sid", 1, "127.0.0.1")
+
+		assert.EqualError(t, err, "Session has been revoked")
+	})
+
+	t.Run("Session of other user", func(t *testing.T) {
+		sessionService, _, sessionRepo, _ := newSessionService()
+
+		sessionRepo.On("FindActiveBySessionID", mock.Anything, "sid").Return(&models.Sessions{UserID: 2, LastSeenAt: time.Now()}, nil)
+
+		err := sessionService.Authenticate(context.Background(), "sid", 1, "127.0.0.1")
+
+		assert.EqualError(t, err, "Session has been revoked")
+	})
+
+	t.Run("Skip last seen update when recently used", func(t *testing.T) {
+		sessionService, _, sessionRepo, _ := newSessionService()
+
+		sessionRepo.On("FindActiveBySessionID", mock.Anything, "sid").Return(&models.Sessions{UserID: 1, IP: "127.0.0.1", LastSeenAt: time.Now()}, nil)
+
+		err := sessionService.Authenticate(context.Background(), "sid", 1, "127.0.0.1")
+
+		assert.NoError(t, err)
+		sessionRepo.AssertNotCalled(t, "UpdateLastSeen", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Update last seen when ip changed", func(t *testing.T) {
+		sessionService, _, sessionRepo, _ := newSessionService()
+
+		sessionRepo.On("FindActiveBySessionID", mock.Anything, "sid").Return(&models.Sessions{UserID: 1, IP: "127.0.0.1", LastSeenAt: time.Now(), Model: gorm.Model{ID: 5}}, nil)
+		sessionRepo.On("UpdateLastSeen", mock.Anything, uint(5), "10.0.0.1").Return(nil)
+
+		err := sessionService.Authenticate(context.Background(), "sid", 1, "10.0.0.1")
+
+		assert.NoError(t, err)
+		sessionRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetSessions(t *testing.T) {
+	user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+	t.Run("Mark current session", func(t *testing.T) {
+		sessionService, userRepo, sessionRepo, _ := newSessionService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		sessionRepo.On("FindActiveSessionsByUserId", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return([]models.Sessions{
+			{SessionID: "a"},
+			{SessionID: "b"},
+		}, nil)
+
+		sessions, err := sessionService.GetSessions(context.Background(), user.Email, "b")
+
+		assert.NoError(t, err)
+		assert.False(t, sessions[0].Current)
+		assert.True(t, sessions[1].Current)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+	t.Run("Revoke session success", func(t *testing.T) {
+		sessionService, userRepo, sessionRepo, _ := newSessionService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		sessionRepo.On("FindSessionById", mock.Anything, "3").Return(&models.Sessions{UserID: 1, Model: gorm.Model{ID: 3}}, nil)
+		sessionRepo.On("RevokeSession", mock.Anything, uint(3)).Return(nil)
+
+		err := sessionService.RevokeSession(context.Background(), user.Email, "3")
+
+		assert.NoError(t, err)
+		sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Session of other user", func(t *testing.T) {
+		sessionService, userRepo, sessionRepo, _ := newSessionService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		sessionRepo.On("FindSessionById", mock.Anything, "3").Return(&models.Sessions{UserID: 2, Model: gorm.Model{ID: 3}}, nil)
+
+		err := sessionService.RevokeSession(context.Background(), user.Email, "3")
+
+		assert.EqualError(t, err, "you do not have permission to access this session")
+		sessionRepo.AssertNotCalled(t, "RevokeSession", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	user := &models.Users{Email: "test@gmail.com", Model: gorm.Model{ID: 1}}
+
+	t.Run("Keep current session", func(t *testing.T) {
+		sessionService, userRepo, sessionRepo, _ := newSessionService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		sessionRepo.On("RevokeSessionsByUserId", mock.Anything, user.ID, "current").Return(nil)
+
+		err := sessionService.RevokeOtherSessions(context.Background(), user.Email, "current")
+
+		assert.NoError(t, err)
+		sessionRepo.AssertExpectations(t)
+	})
+}
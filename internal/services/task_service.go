@@ -1,96 +1,95 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
+	"github.com/Beluga-Whale/management-api/internal/metrics"
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/Beluga-Whale/management-api/internal/repositories"
-	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/Beluga-Whale/management-api/internal/tracing"
 )
 
 type TaskServiceInterface interface {
-	CreateTask(task *models.Tasks, emailCookie string) error
-	GetAllTask(emailCookie string ,priority string) ([]models.Tasks,error)
-	FindTaskById(idSrt string, emailCookie string) (*models.Tasks, error)
-	UpdateTaskById(idStr string, emailCookie string, updatedTaskValue *models.Tasks) error 
-	DeleteTaskById(idStr string,emailCookie string,) error 
-	GetCompleteTask(emailCookie string ,priority string) ([]models.Tasks,error)
-	GetPendingTask(emailCookie string ,priority string) ([]models.Tasks,error)
-	GetOverdueTask(emailCookie string ,priority string) ([]models.Tasks,error)
+	CreateTask(ctx context.Context, task *models.Tasks, userEmail string) error
+	GetAllTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error)
+	FindTaskById(ctx context.Context, idSrt string, userEmail string) (*models.Tasks, error)
+	UpdateTaskById(ctx context.Context, idStr string, userEmail string, updatedTaskValue *models.Tasks) error 
+	DeleteTaskById(ctx context.Context, idStr string,userEmail string,) error 
+	GetCompleteTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error)
+	GetPendingTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error)
+	GetOverdueTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error)
+	ReassignTask(ctx context.Context, idStr string, toEmail string) (*models.Tasks, error)
+	ReassignUserTasks(ctx context.Context, fromEmail string, toEmail string) ([]models.Tasks, error)
 }
 
 type TaskService struct {
 	taskRepo repositories.TaskRepositoryInterface
 	userRepo repositories.UserRepositoryInterface
-	jwtUtil utils.JwtInterface
+	uow repositories.UnitOfWorkInterface
+	metrics metrics.RecorderInterface
 }
 
-func NewTaskService(taskRepo repositories.TaskRepositoryInterface, userRepo repositories.UserRepositoryInterface,jwtUtil utils.JwtInterface) *TaskService {
-	return &TaskService{taskRepo: taskRepo, userRepo:userRepo, jwtUtil: jwtUtil}
+func NewTaskService(taskRepo repositories.TaskRepositoryInterface, userRepo repositories.UserRepositoryInterface,uow repositories.UnitOfWorkInterface,metrics metrics.RecorderInterface) *TaskService {
+	return &TaskService{taskRepo: taskRepo, userRepo:userRepo, uow: uow, metrics: metrics}
 }
 
-func (s *TaskService)  CreateTask(task *models.Tasks, emailCookie string) error {
+func (s *TaskService)  CreateTask(ctx context.Context, task *models.Tasks, userEmail string) error {
+	ctx, span := tracing.Start(ctx, "TaskService.CreateTask")
+	defer span.End()
+
 	if task.Title =="" || task.Description == ""{
 		return errors.New("Title and Description is required")
 	}
 
-	claims, err := s.jwtUtil.ParseJWT(emailCookie)
-
-	if err != nil {
-		return errors.New("Error JWT : "+err.Error())
-	}
-
-	user, err := s.userRepo.FindByEmail( claims)
+	// NOTE - userEmail มาจาก AuthMiddleware (cookie, Bearer JWT หรือ personal access token)
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
 
 	if err != nil {
 		return errors.New("Not found user :"+err.Error())
 	}
+	if user == nil {
+		return errors.New("User not found")
+	}
 
 	task.UserID = user.ID
 
-	if err :=s.taskRepo.CreateTask(task); err != nil {
+	if err :=s.taskRepo.CreateTask(ctx, task); err != nil {
 		return err
 	}
+	s.metrics.TaskCreated()
 	return nil
 }
 
-func (s *TaskService) GetAllTask(emailCookie string ,priority string) ([]models.Tasks,error) {
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
-
-	if err != nil{
-		return nil,fmt.Errorf("Fail To Check Email : %w",err)
-	}
+func (s *TaskService) GetAllTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetAllTask")
+	defer span.End()
 
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
+	// NOTE - หา User จาก Email ที่ AuthMiddleware ใส่ไว้ เพื่อเอา UserID 
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
 
-	if err != nil {
+	if err != nil || user == nil {
 		return nil, errors.New("User not found")
 	}
 
-	return s.taskRepo.FindTaskAll(user.ID, priority)
+	return s.taskRepo.FindTaskAll(ctx, user.ID, priority)
 }
 
-func (s *TaskService) FindTaskById(idSrt string, emailCookie string) (*models.Tasks, error) {
-
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
+func (s *TaskService) FindTaskById(ctx context.Context, idSrt string, userEmail string) (*models.Tasks, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.FindTaskById")
+	defer span.End()
 
-	if err != nil{
-		return nil,fmt.Errorf("Fail To Check Email : %w",err)
-	}
 
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
+	// NOTE - หา User จาก Email ที่ AuthMiddleware ใส่ไว้ เพื่อเอา UserID 
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
 
-	if err != nil {
+	if err != nil || user == nil {
 		return nil, errors.New("User not found")
 	}
 
 	// NOTE -หา Task By ID
-	task,err:= s.taskRepo.FindTaskById(idSrt)
+	task,err:= s.taskRepo.FindTaskById(ctx, idSrt)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to find task by ID: %w", err)
@@ -106,136 +105,217 @@ func (s *TaskService) FindTaskById(idSrt string, emailCookie string) (*models.Ta
 }
 
 
-func (s*TaskService) UpdateTaskById(idStr string, emailCookie string, updatedTaskValue *models.Tasks) error {
+func (s*TaskService) UpdateTaskById(ctx context.Context, idStr string, userEmail string, updatedTaskValue *models.Tasks) error {
+	ctx, span := tracing.Start(ctx, "TaskService.UpdateTaskById")
+	defer span.End()
+
 	// NOTE - Check idStr
 	if idStr == "" {
 		return errors.New("Id is required")
 	}
 
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
-
-	if err != nil{
-		return fmt.Errorf("Fail To Check Email : %w",err)
-	}
-
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
-
-	if err != nil {
-		return  errors.New("User not found")
-	}
-
-
-	// NOTE -หา Task By ID
-	task,err:= s.taskRepo.FindTaskById(idStr)
-
+	// NOTE - เช็คเจ้าของกับแก้ task อยู่ใน transaction เดียวกัน update ครึ่งๆ กลางๆ จะถูก rollback
+	var task *models.Tasks
+	err := s.uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+		// NOTE - หา User จาก Email เพื่อเอา UserID 
+		user, err := repos.Users.FindByEmail(ctx, userEmail)
+
+		if err != nil || user == nil {
+			return  errors.New("User not found")
+		}
+
+		// NOTE -หา Task By ID
+		task,err = repos.Tasks.FindTaskById(ctx, idStr)
+
+		if err != nil {
+			return  fmt.Errorf("failed to find task by ID: %w", err)
+		}
+
+		// NOTE - มาเช็คว่าผู้ใช้เป็นเจ้าของ Task ไหม
+		if task.UserID != user.ID {
+			return  errors.New("you do not have permission to access this task")
+		}
+		if	err :=repos.Tasks.UpdateTaskById(ctx, updatedTaskValue,task.ID); err != nil {
+			return fmt.Errorf("Error : %w",err)
+		}
+		return nil
+	})
 	if err != nil {
-		return  fmt.Errorf("failed to find task by ID: %w", err)
+		return err
 	}
 
-	// NOTE - มาเช็คว่าผู้ใช้เป็นเจ้าของ Task ไหม
-	if task.UserID != user.ID {
-		return  errors.New("you do not have permission to access this task")
-	}
-	if	err :=s.taskRepo.UpdateTaskById(updatedTaskValue,task.ID); err != nil {
-		return fmt.Errorf("Error : %w",err)
+	// NOTE - นับเฉพาะตอนเปลี่ยนจากยังไม่เสร็จเป็นเสร็จ แก้ task ที่เสร็จแล้วซ้ำไม่นับ
+	if !task.Completed && updatedTaskValue.Completed {
+		s.metrics.TaskCompleted()
 	}
 	
 	return nil
 
 }
 
-func (s*TaskService) DeleteTaskById(idStr string,emailCookie string,) error {
+func (s*TaskService) DeleteTaskById(ctx context.Context, idStr string,userEmail string,) error {
+	ctx, span := tracing.Start(ctx, "TaskService.DeleteTaskById")
+	defer span.End()
+
 	// NOTE - Check idStr
 	if idStr == "" {
 		return errors.New("Id is required")
 	}
 
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
-
-	if err != nil{
-		return fmt.Errorf("Fail To Check Email : %w",err)
-	}
-
 	
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
+	return s.uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+		// NOTE - หา User จาก Email เพื่อเอา UserID 
+		user, err := repos.Users.FindByEmail(ctx, userEmail)
+
+		if err != nil || user == nil {
+			return  errors.New("User not found")
+		}
+
+		// NOTE -หา Task By ID
+		task,err:= repos.Tasks.FindTaskById(ctx, idStr)
+		if err != nil {
+			return  fmt.Errorf("failed to find task by ID: %w", err)
+		}
+
+		// NOTE - มาเช็คว่าผู้ใช้เป็นเจ้าของ Task ไหม
+		if task.UserID != user.ID {
+			return  errors.New("you do not have permission to access this task")
+		}
+
+		if	err :=repos.Tasks.DeleteTaskById(ctx, task.ID); err != nil {
+			return fmt.Errorf("Error : %w",err)
+		}
+		return nil
+	})
+}
 
-	if err != nil {
-		return  errors.New("User not found")
-	}
+func (s *TaskService) GetCompleteTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetCompleteTask")
+	defer span.End()
 
-	
-	// NOTE -หา Task By ID
-	task,err:= s.taskRepo.FindTaskById(idStr)
-	if err != nil {
-		return  fmt.Errorf("failed to find task by ID: %w", err)
-	}
+	// NOTE - หา User จาก Email ที่ AuthMiddleware ใส่ไว้ เพื่อเอา UserID 
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
 
-	// NOTE - มาเช็คว่าผู้ใช้เป็นเจ้าของ Task ไหม
-	if task.UserID != user.ID {
-		return  errors.New("you do not have permission to access this task")
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
 	}
 
-	if	err :=s.taskRepo.DeleteTaskById(task.ID); err != nil {
-		return fmt.Errorf("Error : %w",err)
-	}
-	
-	return nil
+	return s.taskRepo.FindTaskComplete(ctx, user.ID, priority, true)
 }
 
-func (s *TaskService) GetCompleteTask(emailCookie string ,priority string) ([]models.Tasks,error) {
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
+func (s *TaskService) GetPendingTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetPendingTask")
+	defer span.End()
 
-	if err != nil{
-		return nil,fmt.Errorf("Fail To Check Email : %w",err)
+	// NOTE - หา User จาก Email ที่ AuthMiddleware ใส่ไว้ เพื่อเอา UserID 
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
+
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
 	}
 
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
+	return s.taskRepo.FindTaskPending(ctx, user.ID, priority, true)
+}
 
-	if err != nil {
+func (s *TaskService) GetOverdueTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetOverdueTask")
+	defer span.End()
+
+	// NOTE - หา User จาก Email ที่ AuthMiddleware ใส่ไว้ เพื่อเอา UserID 
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
+
+	if err != nil || user == nil {
 		return nil, errors.New("User not found")
 	}
 
-	return s.taskRepo.FindTaskComplete(user.ID, priority, true)
+	return s.taskRepo.FindTaskOverdue(ctx, user.ID, priority, true)
 }
 
-func (s *TaskService) GetPendingTask(emailCookie string ,priority string) ([]models.Tasks,error) {
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
+// NOTE - ย้ายเจ้าของ task ผ่าน admin CLI (เช่นคนออกแล้วส่งงานต่อ) ไม่เช็คสิทธิ์เหมือน UserService.SetUserRole
+func (s *TaskService) ReassignTask(ctx context.Context, idStr string, toEmail string) (*models.Tasks, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.ReassignTask")
+	defer span.End()
 
-	if err != nil{
-		return nil,fmt.Errorf("Fail To Check Email : %w",err)
+	if idStr == "" {
+		return nil, errors.New("Id is required")
 	}
 
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
+	var task *models.Tasks
+	err := s.uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+		to, err := findTaskOwner(ctx, repos.Users, toEmail)
+		if err != nil {
+			return err
+		}
+
+		task, err = repos.Tasks.FindTaskById(ctx, idStr)
+		if err != nil {
+			return fmt.Errorf("failed to find task by ID: %w", err)
+		}
 
+		return reassignTask(ctx, repos.Tasks, task, to.ID)
+	})
 	if err != nil {
-		return nil, errors.New("User not found")
+		return nil, err
 	}
-
-	return s.taskRepo.FindTaskPending(user.ID, priority, true)
+	return task, nil
 }
 
-func (s *TaskService) GetOverdueTask(emailCookie string ,priority string) ([]models.Tasks,error) {
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
-
-	if err != nil{
-		return nil,fmt.Errorf("Fail To Check Email : %w",err)
+// NOTE - ย้ายทุก task ของ fromEmail ใน transaction เดียว พังกลางทางไม่มี task ไหนถูกย้าย
+func (s *TaskService) ReassignUserTasks(ctx context.Context, fromEmail string, toEmail string) ([]models.Tasks, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.ReassignUserTasks")
+	defer span.End()
+
+	var tasks []models.Tasks
+	err := s.uow.Do(ctx, func(ctx context.Context, repos repositories.TxRepositories) error {
+		from, err := findTaskOwner(ctx, repos.Users, fromEmail)
+		if err != nil {
+			return err
+		}
+		to, err := findTaskOwner(ctx, repos.Users, toEmail)
+		if err != nil {
+			return err
+		}
+		if from.ID == to.ID {
+			return errors.New("Source and target user must be different")
+		}
+
+		tasks, err = repos.Tasks.FindTaskAll(ctx, from.ID, "")
+		if err != nil {
+			return err
+		}
+		for i := range tasks {
+			if err := reassignTask(ctx, repos.Tasks, &tasks[i], to.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return tasks, nil
+}
 
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
+func findTaskOwner(ctx context.Context, userRepo repositories.UserRepositoryInterface, email string) (*models.Users, error) {
+	if email == "" {
+		return nil, errors.New("Email is required")
+	}
 
+	user, err := userRepo.FindByEmail(ctx, email)
 	if err != nil {
+		return nil, fmt.Errorf("Fail To Check Email : %w", err)
+	}
+	if user == nil {
 		return nil, errors.New("User not found")
 	}
+	return user, nil
+}
 
-	return s.taskRepo.FindTaskOverdue(user.ID, priority, true)
-}
\ No newline at end of file
+// NOTE - UpdateTaskById ทับ Completed ทุกครั้ง ต้องส่งค่าเดิมไปด้วยไม่งั้น task ที่เสร็จแล้วจะกลับเป็นยังไม่เสร็จ
+func reassignTask(ctx context.Context, taskRepo repositories.TaskRepositoryInterface, task *models.Tasks, userID uint) error {
+	if err := taskRepo.UpdateTaskById(ctx, &models.Tasks{UserID: userID, Completed: task.Completed}, task.ID); err != nil {
+		return fmt.Errorf("Error : %w", err)
+	}
+	task.UserID = userID
+	return nil
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,13 +15,13 @@ func NewTaskServiceMock() *TaskServiceMock{
 	return &TaskServiceMock{}
 }
 
-func (m *TaskServiceMock) CreateTask(task *models.Tasks, emailCookie string) error {
-	args := m.Called(task,emailCookie)
+func (m *TaskServiceMock) CreateTask(ctx context.Context, task *models.Tasks, userEmail string) error {
+	args := m.Called(ctx, task,userEmail)
 	return args.Error(0)
 }
 
-func (m *TaskServiceMock) GetAllTask(emailCookie string ,priority string) ([]models.Tasks,error) {
-	args := m.Called(emailCookie, priority)
+func (m *TaskServiceMock) GetAllTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error) {
+	args := m.Called(ctx, userEmail, priority)
 
 	if task,ok := args.Get(0).([]models.Tasks); ok{
 		return task,nil
@@ -27,8 +29,8 @@ func (m *TaskServiceMock) GetAllTask(emailCookie string ,priority string) ([]mod
 	return  nil, args.Error(1)
 }
 
-func  (m *TaskServiceMock) FindTaskById(idSrt string, emailCookie string) (*models.Tasks, error) {
-	args := m.Called(idSrt,emailCookie)
+func  (m *TaskServiceMock) FindTaskById(ctx context.Context, idSrt string, userEmail string) (*models.Tasks, error) {
+	args := m.Called(ctx, idSrt,userEmail)
 
 	if task,ok := args.Get(0).(*models.Tasks); ok{
 		return task,nil
@@ -37,28 +39,28 @@ func  (m *TaskServiceMock) FindTaskById(idSrt string, emailCookie string) (*mode
 	return nil,args.Error(1)
 }
 
-func (m *TaskServiceMock) UpdateTaskById(idStr string, emailCookie string, updatedTaskValue *models.Tasks) error  {
-	args := m.Called(idStr,emailCookie,updatedTaskValue)
+func (m *TaskServiceMock) UpdateTaskById(ctx context.Context, idStr string, userEmail string, updatedTaskValue *models.Tasks) error  {
+	args := m.Called(ctx, idStr,userEmail,updatedTaskValue)
 
 	return args.Error(0)
 }
 
-func (m *TaskServiceMock) DeleteTaskById(idStr string,emailCookie string,) error   {
-	args := m.Called(idStr,emailCookie)
+func (m *TaskServiceMock) DeleteTaskById(ctx context.Context, idStr string,userEmail string,) error   {
+	args := m.Called(ctx, idStr,userEmail)
 
 	return args.Error(0)
 }
 
-func (m *TaskServiceMock) GetCompleteTask(emailCookie string ,priority string) ([]models.Tasks,error)   {
-	args := m.Called(emailCookie,priority)
+func (m *TaskServiceMock) GetCompleteTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error)   {
+	args := m.Called(ctx, userEmail,priority)
 	if task,ok :=args.Get(0).([]models.Tasks); ok{
 		return task,nil
 	}
 	return nil,args.Error(1)
 }
 
-func (m *TaskServiceMock) GetPendingTask(emailCookie string ,priority string) ([]models.Tasks,error) {
-	args := m.Called(emailCookie,priority)
+func (m *TaskServiceMock) GetPendingTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error) {
+	args := m.Called(ctx, userEmail,priority)
 
 	if task,ok :=args.Get(0).([]models.Tasks); ok{
 		return task,nil
@@ -66,8 +68,8 @@ func (m *TaskServiceMock) GetPendingTask(emailCookie string ,priority string) ([
 	return nil,args.Error(1)
 }
 
-func (m *TaskServiceMock) GetOverdueTask(emailCookie string ,priority string) ([]models.Tasks,error) {
-	args := m.Called(emailCookie,priority)
+func (m *TaskServiceMock) GetOverdueTask(ctx context.Context, userEmail string ,priority string) ([]models.Tasks,error) {
+	args := m.Called(ctx, userEmail,priority)
 
 	if task,ok :=args.Get(0).([]models.Tasks); ok{
 		return task,nil
@@ -75,4 +77,20 @@ func (m *TaskServiceMock) GetOverdueTask(emailCookie string ,priority string) ([
 	return nil,args.Error(1)
 }
 
+func (m *TaskServiceMock) ReassignTask(ctx context.Context, idStr string, toEmail string) (*models.Tasks, error) {
+	args := m.Called(ctx, idStr, toEmail)
 
+	if task,ok := args.Get(0).(*models.Tasks); ok{
+		return task,nil
+	}
+	return nil,args.Error(1)
+}
+
+func (m *TaskServiceMock) ReassignUserTasks(ctx context.Context, fromEmail string, toEmail string) ([]models.Tasks, error) {
+	args := m.Called(ctx, fromEmail, toEmail)
+
+	if tasks,ok := args.Get(0).([]models.Tasks); ok{
+		return tasks,nil
+	}
+	return nil,args.Error(1)
+}
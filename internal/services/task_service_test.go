@@ -1,20 +1,22 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/Beluga-Whale/management-api/internal/metrics"
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/Beluga-Whale/management-api/internal/repositories"
 	"github.com/Beluga-Whale/management-api/internal/services"
-	"github.com/Beluga-Whale/management-api/internal/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
 func TestCreateTask(t *testing.T){
 	t.Run("CreateTask Success",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		user:= &models.Users{
 			Email: "Test@gmail.com",
 		}
@@ -26,24 +28,24 @@ func TestCreateTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
 
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 
-		taskRepo.On("CreateTask",task).Return(nil)
+		taskRepo.On("CreateTask", mock.Anything, task).Return(nil)
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		recorder := newMetricsRecorder()
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),recorder)
 
-		err :=taskService.CreateTask(task,emailToken)
+		err :=taskService.CreateTask(context.Background(), task,userEmail)
 
 
 		assert.NoError(t,err)
+		recorder.AssertCalled(t, "TaskCreated")
 	})
 
 	t.Run("Title and Description Required",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		task := &models.Tasks{
 			Title: "",
 			Description: "",
@@ -51,38 +53,16 @@ func TestCreateTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err := taskService.CreateTask(task,emailToken)
+		err := taskService.CreateTask(context.Background(), task,userEmail)
 
 		assert.EqualError(t,err,"Title and Description is required")
 	})
 
-	t.Run("Jwt Error",func(t *testing.T) {
-		emailToken := "fakeToken"
-
-		task := &models.Tasks{
-			Title: "Title Test",
-			Description: "Description Test",
-		}
-
-		taskRepo := repositories.NewTaskRepositoryMock()
-		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
-
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("You not access to create task"))
-
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
-
-		err := taskService.CreateTask(task,emailToken)
-
-		assert.EqualError(t,err,"Error JWT : You not access to create task")
-	})
-
 	t.Run("Error FindByEmail",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		user:= &models.Users{
 			Email: "Test@gmail.com",
 		}
@@ -94,20 +74,18 @@ func TestCreateTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("not have your email"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("not have your email"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err := taskService.CreateTask(task,emailToken)
+		err := taskService.CreateTask(context.Background(), task,userEmail)
 
 		assert.EqualError(t,err,"Not found user :not have your email")
 	})
 
 	t.Run("Create Error",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		user:= &models.Users{
 			Email: "Test@gmail.com",
 		}
@@ -119,17 +97,15 @@ func TestCreateTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
 
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 
-		taskRepo.On("CreateTask",task).Return(errors.New("You not create task"))
+		taskRepo.On("CreateTask", mock.Anything, task).Return(errors.New("You not create task"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err :=taskService.CreateTask(task,emailToken)
+		err :=taskService.CreateTask(context.Background(), task,userEmail)
 
 
 		assert.EqualError(t,err,"You not create task")
@@ -138,7 +114,7 @@ func TestCreateTask(t *testing.T){
 
 func TestGetAllTask(t *testing.T){
 	t.Run("Get All Task Success",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		priority := ""
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -152,43 +128,51 @@ func TestGetAllTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskAll",user.ID,priority).Return([]models.Tasks{*task},nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskAll", mock.Anything, user.ID,priority).Return([]models.Tasks{*task},nil)
 		
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		taskAll,err :=taskService.GetAllTask(emailToken,priority)
+		taskAll,err :=taskService.GetAllTask(context.Background(), userEmail,priority)
 
 		assert.NoError(t,err)
 		assert.Equal(t, []models.Tasks{*task}, taskAll)
 		
 		userRepo.AssertExpectations(t)
-		jwtUtil.AssertExpectations(t)
 	})
 
-	t.Run("Fail To Check Email",func(t *testing.T) {
-		emailToken := "fakeToken"
-		priority := ""
+	t.Run("Pass request context to repository",func(t *testing.T) {
+		userEmail := "Test@gmail.com"
+		user:= &models.Users{
+			Email: "Test@gmail.com",
+			Model: gorm.Model{ID: 1},
+		}
+
+		// NOTE - ctx ที่ถูกยกเลิกต้องส่งถึง repository ตัวเดิม query จะได้หยุดตาม request
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		isRequestCtx := mock.MatchedBy(func(c context.Context) bool {
+			return errors.Is(c.Err(), context.Canceled)
+		})
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("check error"))
+		userRepo.On("FindByEmail", isRequestCtx, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskAll", isRequestCtx, user.ID,"").Return(nil,context.Canceled)
 
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		_,err :=taskService.GetAllTask(ctx, userEmail,"")
 
-		_,err := taskService.GetAllTask(emailToken,priority)
-
-		assert.EqualError(t,err,"Fail To Check Email : check error")
+		assert.ErrorIs(t,err,context.Canceled)
+		userRepo.AssertExpectations(t)
+		taskRepo.AssertExpectations(t)
 	})
 
 	t.Run("User Not found",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		priority := ""
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -197,14 +181,12 @@ func TestGetAllTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("Can't to find you user"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("Can't to find you user"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		_,err:=taskService.GetAllTask(emailToken,priority)
+		_,err:=taskService.GetAllTask(context.Background(), userEmail,priority)
 
 		assert.EqualError(t,err,"User not found")
 	})
@@ -213,7 +195,7 @@ func TestGetAllTask(t *testing.T){
 func TestFindTaskById(t *testing.T){
 	t.Run("FindTaskById Success",func(t *testing.T) {
 		idSrt := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -228,44 +210,25 @@ func TestFindTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idSrt).Return(task,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idSrt).Return(task,nil)
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		taskById,err:= taskService.FindTaskById(idSrt,emailToken)
+		taskById,err:= taskService.FindTaskById(context.Background(), idSrt,userEmail)
 
 		assert.NoError(t,err)
 		assert.Equal(t,task,taskById)
 
 		taskRepo.AssertExpectations(t)
 		userRepo.AssertExpectations(t)
-		jwtUtil.AssertExpectations(t)
 				
 	})
 
-	t.Run("Fail To Check Email",func(t *testing.T) {
-		idSrt := "1"
-		emailToken := "fakeToken"
-
-		taskRepo := repositories.NewTaskRepositoryMock()
-		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
-
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("Can't to check your email"))
-
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
-
-		_,err := taskService.FindTaskById(idSrt,emailToken)
-		assert.EqualError(t,err,"Fail To Check Email : Can't to check your email")
-	})
-
 	t.Run("User not found",func(t *testing.T) {
 		idSrt := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -274,21 +237,19 @@ func TestFindTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("User not found"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("User not found"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		_,err := taskService.FindTaskById(idSrt,emailToken)
+		_,err := taskService.FindTaskById(context.Background(), idSrt,userEmail)
 
 		assert.EqualError(t,err,"User not found")
 	})
 
 	t.Run("Failed to find task by",func(t *testing.T) {
 		idSrt := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -297,22 +258,20 @@ func TestFindTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idSrt).Return(nil,errors.New("you can't to access this task"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idSrt).Return(nil,errors.New("you can't to access this task"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		_,err := taskService.FindTaskById(idSrt,emailToken)
+		_,err := taskService.FindTaskById(context.Background(), idSrt,userEmail)
 
 		assert.EqualError(t,err,"failed to find task by ID: you can't to access this task")
 	})
 
 	t.Run("You not have permission to access this task",func(t *testing.T) {
 		idSrt := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -327,28 +286,25 @@ func TestFindTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idSrt).Return(task,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idSrt).Return(task,nil)
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		_,err:= taskService.FindTaskById(idSrt,emailToken)
+		_,err:= taskService.FindTaskById(context.Background(), idSrt,userEmail)
 
 		assert.EqualError(t,err,"you do not have permission to access this task")
 
 		taskRepo.AssertExpectations(t)
 		userRepo.AssertExpectations(t)
-		jwtUtil.AssertExpectations(t)
 	})
 }
 
 func TestUpdateTaskById(t *testing.T){
 	t.Run("UpdateTaskById Success",func(t *testing.T) {
 	idSrt := "1"
-	emailToken := "fakeToken"
+	userEmail := "Test@gmail.com"
 
 	user:= &models.Users{
 		Email: "Test@gmail.com",
@@ -363,49 +319,68 @@ func TestUpdateTaskById(t *testing.T){
 
 	taskRepo := repositories.NewTaskRepositoryMock()
 	userRepo := repositories.NewUserRepositoryMock()
-	jwtUtil := utils.NewJwtMock()
 
-	jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-	userRepo.On("FindByEmail",user.Email).Return(user,nil)
-	taskRepo.On("FindTaskById",idSrt).Return(task,nil)
-	taskRepo.On("UpdateTaskById",task,task.ID).Return(nil)
+	userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+	taskRepo.On("FindTaskById", mock.Anything, idSrt).Return(task,nil)
+	taskRepo.On("UpdateTaskById", mock.Anything, task,task.ID).Return(nil)
 
-	taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+	taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-	err := taskService.UpdateTaskById(idSrt,emailToken,task)
+	err := taskService.UpdateTaskById(context.Background(), idSrt,userEmail,task)
 
 	assert.NoError(t,err)
 
 	userRepo.AssertExpectations(t)
 	taskRepo.AssertExpectations(t)
-	jwtUtil.AssertExpectations(t)
 	})
 
-	t.Run("Id Is required",func(t *testing.T) {
-		idStr := ""
-		emailToken := "fakeToken"
-			
-		task := &models.Tasks{
-			Title: "Title Test",
-			Description: "Description Test",
-			UserID: 1,
-		}
+	t.Run("Count completed task once", func(t *testing.T) {
+		user := &models.Users{Email: "Test@gmail.com", Model: gorm.Model{ID: 1}}
+		pending := &models.Tasks{Title: "Title Test", UserID: 1}
+		done := &models.Tasks{Title: "Title Test", UserID: 1, Completed: true}
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
+		recorder := metrics.NewRecorderMock()
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		taskRepo.On("FindTaskById", mock.Anything, "1").Return(pending, nil).Once()
+		taskRepo.On("FindTaskById", mock.Anything, "1").Return(done, nil).Once()
+		taskRepo.On("UpdateTaskById", mock.Anything, done, uint(0)).Return(nil)
+		recorder.On("TaskCompleted").Return()
 
-		err :=taskService.UpdateTaskById(idStr,emailToken,task)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo), recorder)
 
-		assert.EqualError(t,err,"Id is required")
+		assert.NoError(t, taskService.UpdateTaskById(context.Background(), "1", "Test@gmail.com", done))
+		assert.NoError(t, taskService.UpdateTaskById(context.Background(), "1", "Test@gmail.com", done))
 
+		recorder.AssertNumberOfCalls(t, "TaskCompleted", 1)
 	})
 
-	t.Run("Fail To Check Email",func(t *testing.T) {
-		idStr := "1"
-		emailToken := "fakeToken"
+	t.Run("Commit failed",func(t *testing.T) {
+		user := &models.Users{Email: "Test@gmail.com", Model: gorm.Model{ID: 1}}
+		done := &models.Tasks{Title: "Title Test", UserID: 1, Completed: true}
+
+		taskRepo := repositories.NewTaskRepositoryMock()
+		userRepo := repositories.NewUserRepositoryMock()
+		recorder := metrics.NewRecorderMock()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		taskRepo.On("FindTaskById", mock.Anything, "1").Return(&models.Tasks{Title: "Title Test", UserID: 1}, nil)
+		taskRepo.On("UpdateTaskById", mock.Anything, done, uint(0)).Return(nil)
+
+		uow := &commitFailedUnitOfWork{UnitOfWorkMock: repositories.NewUnitOfWorkMock(taskRepo, userRepo), err: errors.New("commit failed")}
+		taskService := services.NewTaskService(taskRepo,userRepo,uow, recorder)
+
+		err := taskService.UpdateTaskById(context.Background(), "1", "Test@gmail.com", done)
+
+		assert.EqualError(t, err, "commit failed")
+		recorder.AssertNotCalled(t, "TaskCompleted")
+	})
+
+	t.Run("Id Is required",func(t *testing.T) {
+		idStr := ""
+		userEmail := "Test@gmail.com"
 			
 		task := &models.Tasks{
 			Title: "Title Test",
@@ -415,20 +390,18 @@ func TestUpdateTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("Not have your email"))
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		err :=taskService.UpdateTaskById(context.Background(), idStr,userEmail,task)
 
-		err:=taskService.UpdateTaskById(idStr,emailToken,task)
+		assert.EqualError(t,err,"Id is required")
 
-		assert.EqualError(t,err,"Fail To Check Email : Not have your email")
 	})
 
 	t.Run("User not found",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -442,21 +415,19 @@ func TestUpdateTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("User not found"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("User not found"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.UpdateTaskById(idStr,emailToken,task)
+		err:=taskService.UpdateTaskById(context.Background(), idStr,userEmail,task)
 
 		assert.EqualError(t,err,"User not found")
 	})
 
 	t.Run("Failed to find task by ID",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -470,22 +441,20 @@ func TestUpdateTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idStr).Return(nil,errors.New("Can't to find task"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idStr).Return(nil,errors.New("Can't to find task"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.UpdateTaskById(idStr,emailToken,task)
+		err:=taskService.UpdateTaskById(context.Background(), idStr,userEmail,task)
 
 		assert.EqualError(t,err,"failed to find task by ID: Can't to find task")
 	})
 
 	t.Run("Not have permission to access this task",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -499,22 +468,20 @@ func TestUpdateTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idStr).Return(task,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idStr).Return(task,nil)
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.UpdateTaskById(idStr,emailToken,task)
+		err:=taskService.UpdateTaskById(context.Background(), idStr,userEmail,task)
 
 		assert.EqualError(t,err,"you do not have permission to access this task")
 	})
 
 	t.Run("Not have permission to access this task",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -528,16 +495,14 @@ func TestUpdateTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idStr).Return(task,nil)
-		taskRepo.On("UpdateTaskById",task,task.ID).Return(errors.New("Can't to update this task"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idStr).Return(task,nil)
+		taskRepo.On("UpdateTaskById", mock.Anything, task,task.ID).Return(errors.New("Can't to update this task"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.UpdateTaskById(idStr,emailToken,task)
+		err:=taskService.UpdateTaskById(context.Background(), idStr,userEmail,task)
 
 		assert.EqualError(t,err,"Error : Can't to update this task")
 	})
@@ -546,7 +511,7 @@ func TestUpdateTaskById(t *testing.T){
 func TestDeleteTaskById(t *testing.T){
 	t.Run("DeleteTaskById Success",func(t *testing.T) {
 		idSrt := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -561,60 +526,39 @@ func TestDeleteTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idSrt).Return(task,nil)
-		taskRepo.On("DeleteTaskById",task.ID).Return(nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idSrt).Return(task,nil)
+		taskRepo.On("DeleteTaskById", mock.Anything, task.ID).Return(nil)
 		
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err := taskService.DeleteTaskById(idSrt,emailToken)
+		err := taskService.DeleteTaskById(context.Background(), idSrt,userEmail)
 
 		assert.NoError(t,err)
 		userRepo.AssertExpectations(t)
 		taskRepo.AssertExpectations(t)
-		jwtUtil.AssertExpectations(t)
 
 	})
 
 	t.Run("Id Is required",func(t *testing.T) {
 		idStr := ""
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err :=taskService.DeleteTaskById(idStr,emailToken)
+		err :=taskService.DeleteTaskById(context.Background(), idStr,userEmail)
 
 		assert.EqualError(t,err,"Id is required")
 
 	})
 
-	t.Run("Fail To Check Email",func(t *testing.T) {
-		idStr := "1"
-		emailToken := "fakeToken"
-
-		taskRepo := repositories.NewTaskRepositoryMock()
-		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
-
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("Not have your email"))
-
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
-
-		err:=taskService.DeleteTaskById(idStr,emailToken)
-
-		assert.EqualError(t,err,"Fail To Check Email : Not have your email")
-	})
-
 	t.Run("User not found",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -623,21 +567,19 @@ func TestDeleteTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("User not found"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("User not found"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.DeleteTaskById(idStr,emailToken)
+		err:=taskService.DeleteTaskById(context.Background(), idStr,userEmail)
 
 		assert.EqualError(t,err,"User not found")
 	})
 
 	t.Run("Failed to find task by ID",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -646,15 +588,13 @@ func TestDeleteTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idStr).Return(nil,errors.New("Can't to find task"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idStr).Return(nil,errors.New("Can't to find task"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.DeleteTaskById(idStr,emailToken)
+		err:=taskService.DeleteTaskById(context.Background(), idStr,userEmail)
 
 		assert.EqualError(t,err,"failed to find task by ID: Can't to find task")
 	})
@@ -662,7 +602,7 @@ func TestDeleteTaskById(t *testing.T){
 	
 	t.Run("Not have permission to access this task",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -676,22 +616,20 @@ func TestDeleteTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idStr).Return(task,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idStr).Return(task,nil)
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.DeleteTaskById(idStr,emailToken)
+		err:=taskService.DeleteTaskById(context.Background(), idStr,userEmail)
 
 		assert.EqualError(t,err,"you do not have permission to access this task")
 	})
 
 	t.Run("Fail To delete",func(t *testing.T) {
 		idStr := "1"
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 			
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -705,24 +643,52 @@ func TestDeleteTaskById(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskById",idStr).Return(task,nil)
-		taskRepo.On("DeleteTaskById",task.ID).Return(errors.New("You not delete this task"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskById", mock.Anything, idStr).Return(task,nil)
+		taskRepo.On("DeleteTaskById", mock.Anything, task.ID).Return(errors.New("You not delete this task"))
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		err:=taskService.DeleteTaskById(idStr,emailToken)
+		err:=taskService.DeleteTaskById(context.Background(), idStr,userEmail)
 
 		assert.EqualError(t,err,"Error : You not delete this task")
 	})
 }
 
+// NOTE - ใช้ repository ใน memory แทน mock เช็คเจ้าของ task กับ soft delete ของจริง
+func TestTaskOwnershipWithMemoryRepositories(t *testing.T) {
+	ctx := context.Background()
+	taskRepo := repositories.NewTaskMemoryRepository()
+	userRepo := repositories.NewUserMemoryRepository()
+
+	assert.NoError(t, userRepo.CreateUser(ctx, &models.Users{Email: "owner@gmail.com"}))
+	assert.NoError(t, userRepo.CreateUser(ctx, &models.Users{Email: "other@gmail.com"}))
+
+	taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
+	assert.NoError(t, taskService.CreateTask(ctx, &models.Tasks{Title: "Title Test", Description: "Description Test"}, "owner@gmail.com"))
+
+	t.Run("Other user can't delete", func(t *testing.T) {
+		err := taskService.DeleteTaskById(ctx, "1", "other@gmail.com")
+
+		assert.EqualError(t, err, "you do not have permission to access this task")
+		tasks, _ := taskService.GetAllTask(ctx, "owner@gmail.com", "")
+		assert.Len(t, tasks, 1)
+	})
+
+	t.Run("Owner deletes", func(t *testing.T) {
+		assert.NoError(t, taskService.DeleteTaskById(ctx, "1", "owner@gmail.com"))
+
+		tasks, _ := taskService.GetAllTask(ctx, "owner@gmail.com", "")
+		assert.Empty(t, tasks)
+		_, err := taskService.FindTaskById(ctx, "1", "owner@gmail.com")
+		assert.Error(t, err)
+	})
+}
+
 func TestGetCompleteTask(t *testing.T){
 	t.Run("GetCompleteTask Success",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		priority := ""
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -737,40 +703,20 @@ func TestGetCompleteTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskComplete",user.ID,priority,true).Return([]models.Tasks{*task},nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskComplete", mock.Anything, user.ID,priority,true).Return([]models.Tasks{*task},nil)
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		tasks,err :=taskService.GetCompleteTask(emailToken,priority)
+		tasks,err :=taskService.GetCompleteTask(context.Background(), userEmail,priority)
 
 		assert.NoError(t,err)
 		assert.Equal(t,[]models.Tasks{*task},tasks)
 	})
 
-	t.Run("Fail to check email",func(t *testing.T) {
-		emailToken := "fakeToken"
-		priority := ""
-
-		taskRepo := repositories.NewTaskRepositoryMock()
-		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
-
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("Don't have your email"))
-
-
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
-
-		_,err :=taskService.GetCompleteTask(emailToken,priority)
-
-		assert.EqualError(t,err,"Fail To Check Email : Don't have your email")
-	})
-
 	t.Run("User not found",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		priority := ""
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -779,15 +725,13 @@ func TestGetCompleteTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("User not found"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("User not found"))
 
 
-		taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		_,err :=taskService.GetCompleteTask(emailToken,priority)
+		_,err :=taskService.GetCompleteTask(context.Background(), userEmail,priority)
 
 		assert.EqualError(t,err,"User not found")
 	})
@@ -795,7 +739,7 @@ func TestGetCompleteTask(t *testing.T){
 
 func TestGetPendingTask(t *testing.T){
 	t.Run("GetPendingTask Success",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		priority := ""
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -810,15 +754,13 @@ func TestGetPendingTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskPending",user.ID,priority,true).Return([]models.Tasks{*task},nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskPending", mock.Anything, user.ID,priority,true).Return([]models.Tasks{*task},nil)
 
-		taskService:=services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService:=services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		tasks,err := taskService.GetPendingTask(emailToken,priority)
+		tasks,err := taskService.GetPendingTask(context.Background(), userEmail,priority)
 
 		assert.NoError(t,err)
 
@@ -826,27 +768,8 @@ func TestGetPendingTask(t *testing.T){
 
 	})
 
-	t.Run("Fail To Check Email",func(t *testing.T) {
-		emailToken := "fakeToken"
-		priority := ""
-		taskRepo := repositories.NewTaskRepositoryMock()
-		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
-
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("Not have email"))
-
-
-		taskService:=services.NewTaskService(taskRepo,userRepo,jwtUtil)
-
-		_,err := taskService.GetPendingTask(emailToken,priority)
-
-
-		assert.EqualError(t,err,"Fail To Check Email : Not have email")
-
-	})
-
 	t.Run("GetPendingTask Success",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		priority := ""
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -855,15 +778,13 @@ func TestGetPendingTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("User not found"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("User not found"))
 
 
-		taskService:=services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService:=services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		_,err := taskService.GetPendingTask(emailToken,priority)
+		_,err := taskService.GetPendingTask(context.Background(), userEmail,priority)
 
 
 		assert.EqualError(t,err,"User not found")
@@ -873,7 +794,7 @@ func TestGetPendingTask(t *testing.T){
 
 func TestGetOverdueTask(t *testing.T){
 	t.Run("GetOverdueTask Success",func(t *testing.T) {
-		emailToken := "fakeToken"
+		userEmail := "Test@gmail.com"
 		priority := ""
 		user:= &models.Users{
 			Email: "Test@gmail.com",
@@ -888,15 +809,13 @@ func TestGetOverdueTask(t *testing.T){
 
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		taskRepo.On("FindTaskOverdue",user.ID,priority,true).Return([]models.Tasks{*task},nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		taskRepo.On("FindTaskOverdue", mock.Anything, user.ID,priority,true).Return([]models.Tasks{*task},nil)
 
-		taskService:=services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService:=services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		tasks,err := taskService.GetOverdueTask(emailToken,priority)
+		tasks,err := taskService.GetOverdueTask(context.Background(), userEmail,priority)
 
 		assert.NoError(t,err)
 
@@ -904,47 +823,94 @@ func TestGetOverdueTask(t *testing.T){
 
 	})
 
-	t.Run("Fail To Check Email",func(t *testing.T) {
-		emailToken := "fakeToken"
+	t.Run("GetOverdueTask Fail",func(t *testing.T) {
+		userEmail := "Test@gmail.com"
 		priority := ""
+		user:= &models.Users{
+			Email: "Test@gmail.com",
+			Model: gorm.Model{ID: 1},
+		}
+
 		taskRepo := repositories.NewTaskRepositoryMock()
 		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailToken).Return("",errors.New("Not have email"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("User not found"))
 
 
-		taskService:=services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		taskService:=services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
 
-		_,err := taskService.GetOverdueTask(emailToken,priority)
+		_,err := taskService.GetOverdueTask(context.Background(), userEmail,priority)
 
 
-		assert.EqualError(t,err,"Fail To Check Email : Not have email")
+		assert.EqualError(t,err,"User not found")
 
 	})
+}
+// NOTE - fn ทำงานครบแต่ commit ไม่ผ่าน
+type commitFailedUnitOfWork struct {
+	*repositories.UnitOfWorkMock
+	err error
+}
 
-	t.Run("GetOverdueTask Fail",func(t *testing.T) {
-		emailToken := "fakeToken"
-		priority := ""
-		user:= &models.Users{
-			Email: "Test@gmail.com",
-			Model: gorm.Model{ID: 1},
-		}
+func (u *commitFailedUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos repositories.TxRepositories) error) error {
+	if err := u.UnitOfWorkMock.Do(ctx, fn); err != nil {
+		return err
+	}
+	return u.err
+}
 
-		taskRepo := repositories.NewTaskRepositoryMock()
-		userRepo := repositories.NewUserRepositoryMock()
-		jwtUtil := utils.NewJwtMock()
+func TestReassignTasks(t *testing.T) {
+	setup := func() (*services.TaskService, *repositories.TaskMemoryRepository) {
+		ctx := context.Background()
+		taskRepo := repositories.NewTaskMemoryRepository()
+		userRepo := repositories.NewUserMemoryRepository()
+		assert.NoError(t, userRepo.CreateUser(ctx, &models.Users{Email: "old@gmail.com"}))
+		assert.NoError(t, userRepo.CreateUser(ctx, &models.Users{Email: "new@gmail.com"}))
+		assert.NoError(t, taskRepo.CreateTask(ctx, &models.Tasks{Title: "Done", Completed: true, UserID: 1}))
+		assert.NoError(t, taskRepo.CreateTask(ctx, &models.Tasks{Title: "Open", UserID: 1}))
+
+		taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWorkMock(taskRepo,userRepo),newMetricsRecorder())
+		return taskService, taskRepo
+	}
 
-		jwtUtil.On("ParseJWT",emailToken).Return("Test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("User not found"))
+	t.Run("Reassign one task keeps completed",func(t *testing.T) {
+		taskService, taskRepo := setup()
 
+		task, err := taskService.ReassignTask(context.Background(), "1", "new@gmail.com")
 
-		taskService:=services.NewTaskService(taskRepo,userRepo,jwtUtil)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), task.UserID)
+		saved, _ := taskRepo.FindTaskById(context.Background(), "1")
+		assert.Equal(t, uint(2), saved.UserID)
+		assert.True(t, saved.Completed)
+	})
 
-		_,err := taskService.GetOverdueTask(emailToken,priority)
+	t.Run("Reassign every task of a user",func(t *testing.T) {
+		taskService, taskRepo := setup()
 
+		tasks, err := taskService.ReassignUserTasks(context.Background(), "old@gmail.com", "new@gmail.com")
 
-		assert.EqualError(t,err,"User not found")
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 2)
+		remaining, _ := taskRepo.FindTaskAll(context.Background(), 1, "")
+		assert.Empty(t, remaining)
+		moved, _ := taskRepo.FindTaskAll(context.Background(), 2, "")
+		assert.Len(t, moved, 2)
+	})
 
+	t.Run("Same user",func(t *testing.T) {
+		taskService, _ := setup()
+
+		_, err := taskService.ReassignUserTasks(context.Background(), "old@gmail.com", "old@gmail.com")
+
+		assert.EqualError(t, err, "Source and target user must be different")
 	})
-}
\ No newline at end of file
+
+	t.Run("Target not found",func(t *testing.T) {
+		taskService, _ := setup()
+
+		_, err := taskService.ReassignTask(context.Background(), "1", "nobody@gmail.com")
+
+		assert.EqualError(t, err, "User not found")
+	})
+}
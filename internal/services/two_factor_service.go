@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorServiceInterface interface {
+	Enroll(ctx context.Context, email string) (*utils.TOTPKey, error)
+	Verify(ctx context.Context, email string, code string) ([]string, error)
+	Disable(ctx context.Context, email string, code string) error
+	LoginMFA(ctx context.Context, mfaToken string, code string, client ClientInfo) (string, *models.Users, error)
+}
+
+type TwoFactorService struct {
+	userRepo       repositories.UserRepositoryInterface
+	recoveryRepo   repositories.RecoveryCodeRepositoryInterface
+	totpUtil       utils.TOTPInterface
+	jwtUtil        utils.JwtInterface
+	sessionService SessionServiceInterface
+	loginThrottle  LoginThrottleInterface
+	auditService   AuditServiceInterface
+}
+
+func NewTwoFactorService(userRepo repositories.UserRepositoryInterface, recoveryRepo repositories.RecoveryCodeRepositoryInterface, totpUtil utils.TOTPInterface, jwtUtil utils.JwtInterface, sessionService SessionServiceInterface, loginThrottle LoginThrottleInterface, auditService AuditServiceInterface) *TwoFactorService {
+	return &TwoFactorService{userRepo: userRepo, recoveryRepo: recoveryRepo, totpUtil: totpUtil, jwtUtil: jwtUtil, sessionService: sessionService, loginThrottle: loginThrottle, auditService: auditService}
+}
+
+// NOTE - สร้าง secret ใหม่เก็บไว้ก่อน ยังไม่เปิดใช้จนกว่าจะ Verify ผ่าน
+func (s *TwoFactorService) Enroll(ctx context.Context, email string) (*utils.TOTPKey, error) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.New("Two-factor authentication is already enabled")
+	}
+
+	key, err := s.totpUtil.GenerateSecret(user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate secret: %w", err)
+	}
+
+	if err := s.userRepo.UpdateTwoFactor(ctx, user.ID, key.Secret, false); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *TwoFactorService) Verify(ctx context.Context, email string, code string) ([]string, error) {
+	if code == "" {
+		return nil, errors.New("Code is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.New("Two-factor authentication is already enabled")
+	}
+
+	if user.TwoFactorSecret == "" {
+		return nil, errors.New("Two-factor enrollment not started")
+	}
+
+	step, ok := s.totpUtil.Validate(code, user.TwoFactorSecret)
+	if !ok {
+		return nil, errors.New("Invalid code")
+	}
+
+	// NOTE - code ที่ใช้ยืนยันตอนเปิด 2FA เอาไป login ซ้ำไม่ได้
+	if ok, err := s.userRepo.UseTOTPStep(ctx, user.ID, step); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.New("Invalid code")
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		recoveryCode, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to generate recovery code: %w", err)
+		}
+		codes = append(codes, recoveryCode)
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)))
+	}
+
+	if err := s.recoveryRepo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateTwoFactor(ctx, user.ID, user.TwoFactorSecret, true); err != nil {
+		return nil, err
+	}
+
+	// NOTE - recovery code จริงให้ดูได้ครั้งเดียวตอนนี้ ใน DB เก็บแค่ hash
+	return codes, nil
+}
+
+func (s *TwoFactorService) Disable(ctx context.Context, email string, code string) error {
+	if code == "" {
+		return errors.New("Code is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return errors.New("User not found")
+	}
+
+	if !user.TwoFactorEnabled {
+		return errors.New("Two-factor authentication is not enabled")
+	}
+
+	ok, err := s.checkCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("Invalid code")
+	}
+
+	if err := s.userRepo.UpdateTwoFactor(ctx, user.ID, "", false); err != nil {
+		return err
+	}
+
+	return s.recoveryRepo.DeleteByUserId(ctx, user.ID)
+}
+
+func (s *TwoFactorService) LoginMFA(ctx context.Context, mfaToken string, code string, client ClientInfo) (string, *models.Users, error) {
+	if mfaToken == "" || code == "" {
+		return "", nil, errors.New("MFA token and code is required")
+	}
+
+	email, err := s.jwtUtil.ParseMFAToken(mfaToken)
+	if err != nil {
+		return "", nil, errors.New("Invalid or expired MFA token")
+	}
+
+	// NOTE - ใช้ตัวนับเดียวกับ password กันคนที่ได้ password ไปแล้วมาเดา code 6 หลัก
+	if err := s.loginThrottle.Check(ctx, email, client.IP); err != nil {
+		recordLoginFailed(ctx, s.auditService, email, nil, client, "locked")
+		return "", nil, err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return "", nil, errors.New("User not found")
+	}
+
+	if !user.TwoFactorEnabled {
+		return "", nil, errors.New("Two-factor authentication is not enabled")
+	}
+
+	ok, err := s.checkCode(ctx, user, code)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !ok {
+		if err := s.loginThrottle.RegisterFailure(ctx, email, client.IP); err != nil {
+			return "", nil, fmt.Errorf("Failed to record login attempt: %w", err)
+		}
+		recordLoginFailed(ctx, s.auditService, email, user, client, "invalid_mfa_code")
+		return "", nil, errors.New("Invalid code")
+	}
+
+	if err := s.loginThrottle.RegisterSuccess(ctx, email); err != nil {
+		return "", nil, fmt.Errorf("Failed to record login attempt: %w", err)
+	}
+
+	token, err := s.sessionService.StartSession(ctx, user, client)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, user, nil
+}
+
+// NOTE - รับได้ทั้ง TOTP code และ recovery code (recovery code ใช้แล้วหมดไป)
+// TOTP code ใช้ได้ครั้งเดียวเหมือนกัน code ที่ถูกดักไปเอามาใช้ซ้ำในช่วง 30 วินาทีเดียวกันไม่ผ่าน
+func (s *TwoFactorService) checkCode(ctx context.Context, user *models.Users, code string) (bool, error) {
+	if step, ok := s.totpUtil.Validate(code, user.TwoFactorSecret); ok {
+		return s.userRepo.UseTOTPStep(ctx, user.ID, step)
+	}
+
+	return s.recoveryRepo.UseRecoveryCode(ctx, user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+)
+
+type TwoFactorServiceMock struct {
+	mock.Mock
+}
+
+func NewTwoFactorServiceMock() *TwoFactorServiceMock {
+	return &TwoFactorServiceMock{}
+}
+
+func (m *TwoFactorServiceMock) Enroll(ctx context.Context, email string) (*utils.TOTPKey, error) {
+	args := m.Called(ctx, email)
+	if key, ok := args.Get(0).(*utils.TOTPKey); ok {
+		return key, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *TwoFactorServiceMock) Verify(ctx context.Context, email string, code string) ([]string, error) {
+	args := m.Called(ctx, email, code)
+	if codes, ok := args.Get(0).([]string); ok {
+		return codes, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *TwoFactorServiceMock) Disable(ctx context.Context, email string, code string) error {
+	args := m.Called(ctx, email, code)
+	return args.Error(0)
+}
+
+func (m *TwoFactorServiceMock) LoginMFA(ctx context.Context, mfaToken string, code string, client ClientInfo) (string, *models.Users, error) {
+	args := m.Called(ctx, mfaToken, code, client)
+	if user, ok := args.Get(1).(*models.Users); ok {
+		return args.String(0), user, args.Error(2)
+	}
+	return "", nil, args.Error(2)
+}
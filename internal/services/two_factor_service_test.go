@@ -0,0 +1,305 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newTwoFactorService() (*services.TwoFactorService, *repositories.UserRepositoryMock, *repositories.RecoveryCodeRepositoryMock, *utils.TOTPMock, *utils.JwtMock, *services.SessionServiceMock) {
+	userRepo := repositories.NewUserRepositoryMock()
+	recoveryRepo := repositories.NewRecoveryCodeRepositoryMock()
+	totpUtil := utils.NewTOTPMock()
+	jwtUtil := utils.NewJwtMock()
+	sessionService := services.NewSessionServiceMock()
+
+	twoFactorService := services.NewTwoFactorService(userRepo, recoveryRepo, totpUtil, jwtUtil, sessionService, newLoginThrottle(), newAuditRecorder())
+
+	return twoFactorService, userRepo, recoveryRepo, totpUtil, jwtUtil, sessionService
+}
+
+func TestEnrollTwoFactor(t *testing.T) {
+	t.Run("Enroll success", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Model: gorm.Model{ID: 1},
+		}
+		key := &utils.TOTPKey{Secret: "SECRET", URI: "otpauth://totp/BelugaTasks:test@gmail.com?secret=SECRET"}
+
+		twoFactorService, userRepo, _, totpUtil, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("GenerateSecret", user.Email).Return(key, nil)
+		userRepo.On("UpdateTwoFactor", mock.Anything, user.ID, "SECRET", false).Return(nil)
+
+		result, err := twoFactorService.Enroll(context.Background(), user.Email)
+
+		assert.NoError(t, err)
+		assert.Equal(t, key, result)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		user := &models.Users{
+			Email:            "test@gmail.com",
+			TwoFactorEnabled: true,
+		}
+
+		twoFactorService, userRepo, _, _, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+
+		_, err := twoFactorService.Enroll(context.Background(), user.Email)
+
+		assert.EqualError(t, err, "Two-factor authentication is already enabled")
+	})
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	t.Run("Verify success", func(t *testing.T) {
+		user := &models.Users{
+			Email:           "test@gmail.com",
+			TwoFactorSecret: "SECRET",
+			Model:           gorm.Model{ID: 1},
+		}
+
+		twoFactorService, userRepo, recoveryRepo, totpUtil, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "123456", "SECRET").Return(int64(100), true)
+		userRepo.On("UseTOTPStep", mock.Anything, user.ID, int64(100)).Return(true, nil)
+		recoveryRepo.On("ReplaceRecoveryCodes", mock.Anything, user.ID, mock.MatchedBy(func(hashes []string) bool {
+			return len(hashes) == 10
+		})).Return(nil)
+		userRepo.On("UpdateTwoFactor", mock.Anything, user.ID, "SECRET", true).Return(nil)
+
+		codes, err := twoFactorService.Verify(context.Background(), user.Email, "123456")
+
+		assert.NoError(t, err)
+		assert.Len(t, codes, 10)
+		userRepo.AssertExpectations(t)
+		recoveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Enrollment not started", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+		}
+
+		twoFactorService, userRepo, _, _, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+
+		_, err := twoFactorService.Verify(context.Background(), user.Email, "123456")
+
+		assert.EqualError(t, err, "Two-factor enrollment not started")
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		user := &models.Users{
+			Email:           "test@gmail.com",
+			TwoFactorSecret: "SECRET",
+		}
+
+		twoFactorService, userRepo, _, totpUtil, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "000000", "SECRET").Return(int64(0), false)
+
+		_, err := twoFactorService.Verify(context.Background(), user.Email, "000000")
+
+		assert.EqualError(t, err, "Invalid code")
+		userRepo.AssertNotCalled(t, "UpdateTwoFactor", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	t.Run("Disable success", func(t *testing.T) {
+		user := &models.Users{
+			Email:            "test@gmail.com",
+			TwoFactorEnabled: true,
+			TwoFactorSecret:  "SECRET",
+			Model:            gorm.Model{ID: 1},
+		}
+
+		twoFactorService, userRepo, recoveryRepo, totpUtil, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "123456", "SECRET").Return(int64(100), true)
+		userRepo.On("UseTOTPStep", mock.Anything, user.ID, int64(100)).Return(true, nil)
+		userRepo.On("UpdateTwoFactor", mock.Anything, user.ID, "", false).Return(nil)
+		recoveryRepo.On("DeleteByUserId", mock.Anything, user.ID).Return(nil)
+
+		err := twoFactorService.Disable(context.Background(), user.Email, "123456")
+
+		assert.NoError(t, err)
+		userRepo.AssertExpectations(t)
+		recoveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reused TOTP code", func(t *testing.T) {
+		user := &models.Users{
+			Email:            "test@gmail.com",
+			TwoFactorEnabled: true,
+			TwoFactorSecret:  "SECRET",
+			Model:            gorm.Model{ID: 1},
+		}
+
+		twoFactorService, userRepo, _, totpUtil, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "123456", "SECRET").Return(int64(100), true)
+		userRepo.On("UseTOTPStep", mock.Anything, user.ID, int64(100)).Return(false, nil)
+
+		err := twoFactorService.Disable(context.Background(), user.Email, "123456")
+
+		assert.EqualError(t, err, "Invalid code")
+		userRepo.AssertNotCalled(t, "UpdateTwoFactor", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not enabled", func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+		}
+
+		twoFactorService, userRepo, _, _, _, _ := newTwoFactorService()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+
+		err := twoFactorService.Disable(context.Background(), user.Email, "123456")
+
+		assert.EqualError(t, err, "Two-factor authentication is not enabled")
+	})
+}
+
+func TestLoginMFA(t *testing.T) {
+	user := &models.Users{
+		Email:            "test@gmail.com",
+		TwoFactorEnabled: true,
+		TwoFactorSecret:  "SECRET",
+		Model:            gorm.Model{ID: 1},
+	}
+
+	t.Run("Login with TOTP code", func(t *testing.T) {
+		twoFactorService, userRepo, recoveryRepo, totpUtil, jwtUtil, sessionService := newTwoFactorService()
+
+		jwtUtil.On("ParseMFAToken", "mfaToken").Return(user.Email, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "123456", "SECRET").Return(int64(100), true)
+		userRepo.On("UseTOTPStep", mock.Anything, user.ID, int64(100)).Return(true, nil)
+		sessionService.On("StartSession", mock.Anything, user, testClient).Return("token", nil)
+
+		token, returnUser, err := twoFactorService.LoginMFA(context.Background(), "mfaToken", "123456", testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "token", token)
+		assert.Equal(t, user.Email, returnUser.Email)
+		recoveryRepo.AssertNotCalled(t, "UseRecoveryCode", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Login with recovery code", func(t *testing.T) {
+		twoFactorService, userRepo, recoveryRepo, totpUtil, jwtUtil, sessionService := newTwoFactorService()
+
+		jwtUtil.On("ParseMFAToken", "mfaToken").Return(user.Email, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "abcd2345-efgh6712", "SECRET").Return(int64(0), false)
+		recoveryRepo.On("UseRecoveryCode", mock.Anything, user.ID, utils.HashToken("ABCD2345EFGH6712")).Return(true, nil)
+		sessionService.On("StartSession", mock.Anything, user, testClient).Return("token", nil)
+
+		token, _, err := twoFactorService.LoginMFA(context.Background(), "mfaToken", "abcd2345-efgh6712", testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "token", token)
+		recoveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		twoFactorService, userRepo, recoveryRepo, totpUtil, jwtUtil, sessionService := newTwoFactorService()
+
+		jwtUtil.On("ParseMFAToken", "mfaToken").Return(user.Email, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "000000", "SECRET").Return(int64(0), false)
+		recoveryRepo.On("UseRecoveryCode", mock.Anything, user.ID, mock.Anything).Return(false, nil)
+
+		_, _, err := twoFactorService.LoginMFA(context.Background(), "mfaToken", "000000", testClient)
+
+		assert.EqualError(t, err, "Invalid code")
+		sessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reused TOTP code", func(t *testing.T) {
+		twoFactorService, userRepo, recoveryRepo, totpUtil, jwtUtil, sessionService := newTwoFactorService()
+
+		jwtUtil.On("ParseMFAToken", "mfaToken").Return(user.Email, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "123456", "SECRET").Return(int64(100), true)
+		userRepo.On("UseTOTPStep", mock.Anything, user.ID, int64(100)).Return(false, nil)
+
+		_, _, err := twoFactorService.LoginMFA(context.Background(), "mfaToken", "123456", testClient)
+
+		assert.EqualError(t, err, "Invalid code")
+		sessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+		recoveryRepo.AssertNotCalled(t, "UseRecoveryCode", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Wrong codes are audited and throttled", func(t *testing.T) {
+		userRepo := repositories.NewUserRepositoryMock()
+		recoveryRepo := repositories.NewRecoveryCodeRepositoryMock()
+		totpUtil := utils.NewTOTPMock()
+		jwtUtil := utils.NewJwtMock()
+		sessionService := services.NewSessionServiceMock()
+		auditService := newAuditRecorder()
+		twoFactorService := services.NewTwoFactorService(userRepo, recoveryRepo, totpUtil, jwtUtil, sessionService, newLoginThrottle(), auditService)
+
+		jwtUtil.On("ParseMFAToken", "mfaToken").Return(user.Email, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		totpUtil.On("Validate", "000000", "SECRET").Return(int64(0), false)
+		recoveryRepo.On("UseRecoveryCode", mock.Anything, user.ID, mock.Anything).Return(false, nil)
+
+		// NOTE - ผิดครบ 3 ครั้งแรกยังไม่โดนหน่วง ครั้งที่ 4 ต้องรอ
+		for i := 0; i < 3; i++ {
+			_, _, err := twoFactorService.LoginMFA(context.Background(), "mfaToken", "000000", testClient)
+			assert.EqualError(t, err, "Invalid code")
+		}
+
+		_, _, err := twoFactorService.LoginMFA(context.Background(), "mfaToken", "000000", testClient)
+
+		var throttled *services.LoginThrottledError
+		assert.ErrorAs(t, err, &throttled)
+		assert.Greater(t, throttled.RetryAfter, time.Duration(0))
+		recoveryRepo.AssertNumberOfCalls(t, "UseRecoveryCode", 3)
+		auditService.AssertCalled(t, "Record", mock.Anything, services.AuditEvent{
+			Action:     models.AuditLoginFailed,
+			ActorID:    user.ID,
+			ActorEmail: user.Email,
+			Client:     testClient,
+			Metadata:   models.AuditMetadata{"reason": "invalid_mfa_code"},
+		})
+		auditService.AssertCalled(t, "Record", mock.Anything, services.AuditEvent{
+			Action:     models.AuditLoginFailed,
+			ActorEmail: user.Email,
+			Client:     testClient,
+			Metadata:   models.AuditMetadata{"reason": "locked"},
+		})
+		sessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid MFA token", func(t *testing.T) {
+		twoFactorService, _, _, _, jwtUtil, _ := newTwoFactorService()
+
+		jwtUtil.On("ParseMFAToken", "badToken").Return("", errors.New("token is expired"))
+
+		_, _, err := twoFactorService.LoginMFA(context.Background(), "badToken", "123456", testClient)
+
+		assert.EqualError(t, err, "Invalid or expired MFA token")
+	})
+}
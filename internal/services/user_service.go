@@ -1,37 +1,64 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/tracing"
 	"github.com/Beluga-Whale/management-api/internal/utils"
 )
 
+// NOTE - Login ผ่าน password แล้วแต่ต้องยืนยัน 2FA ต่อ token ที่คืนไปคือ MFA challenge token
+var ErrMFARequired = errors.New("Two-factor authentication required")
+
+// NOTE - ใช้ข้อความเดียวกันทั้ง email ไม่มีในระบบและ password ผิด กันการเดา email
+var ErrInvalidCredentials = errors.New("Invalid Email or Password")
+
 type UserServiceInterface interface {
-	RegisterUser(user *models.Users) error
-	Login(user *models.Users) (string,*models.Users,error)
-	GetUserByEmail(email string) (*models.Users, error)
-	UpdateUserById(idStr string, emailCookie string, updatedUserValue *models.Users) (error)
+	RegisterUser(ctx context.Context, user *models.Users) error
+	Login(ctx context.Context, user *models.Users, client ClientInfo) (string,*models.Users,error)
+	Logout(ctx context.Context, token string, client ClientInfo) error
+	UpdateUserRole(ctx context.Context, adminEmail string, idStr string, role models.Role, client ClientInfo) error
+	GetUserByEmail(ctx context.Context, email string) (*models.Users, error)
+	UpdateUserById(ctx context.Context, idStr string, userEmail string, updatedUserValue *models.Users) (error)
+	CreateUser(ctx context.Context, user *models.Users, role models.Role, client ClientInfo) error
+	SetUserRole(ctx context.Context, email string, role models.Role, client ClientInfo) (*models.Users, error)
+	ResetUserPassword(ctx context.Context, email string, password string, client ClientInfo) error
+	DisableUser(ctx context.Context, email string, client ClientInfo) (*models.Users, error)
 }
 
 type UserService struct {
 	userRepo repositories.UserRepositoryInterface
 	hashUtil utils.HashInterface
 	jwtUtil utils.JwtInterface
+	loginThrottle LoginThrottleInterface
+	sessionService SessionServiceInterface
+	auditService AuditServiceInterface
+}
+
+func NewUserService(userRepo repositories.UserRepositoryInterface,hashUtil utils.HashInterface, jwtUtil utils.JwtInterface, loginThrottle LoginThrottleInterface, sessionService SessionServiceInterface, auditService AuditServiceInterface) *UserService {
+	return &UserService{userRepo: userRepo,hashUtil:hashUtil, jwtUtil: jwtUtil, loginThrottle: loginThrottle, sessionService: sessionService, auditService: auditService }
 }
 
-func NewUserService(userRepo repositories.UserRepositoryInterface,hashUtil utils.HashInterface, jwtUtil utils.JwtInterface) *UserService {
-	return &UserService{userRepo: userRepo,hashUtil:hashUtil, jwtUtil: jwtUtil  }
+// NOTE - สมัครเองผ่าน API ได้ role user เสมอ ไม่สน role ที่ส่งมา ตั้ง admin ได้ทาง CLI (CreateUser) เท่านั้น
+func (s *UserService) RegisterUser(ctx context.Context, user *models.Users) error {
+	ctx, span := tracing.Start(ctx, "UserService.RegisterUser")
+	defer span.End()
+
+	user.Role = models.User
+	return s.register(ctx, user)
 }
 
-func (s *UserService) RegisterUser(user *models.Users) error {
+func (s *UserService) register(ctx context.Context, user *models.Users) error {
 	if user.Email == ""{
 		return errors.New("Email is required")
 	}
 	// NOTE - Check email
-	result, err := s.userRepo.FindByEmail(user.Email)
+	result, err := s.userRepo.FindByEmail(ctx, user.Email)
 
 	if err != nil{
 		return fmt.Errorf("Fail To Check Email : %w",err)
@@ -41,68 +68,199 @@ func (s *UserService) RegisterUser(user *models.Users) error {
 	if result != nil {
 		return errors.New("Email has already been used")
 	}
+	hashedPassword, err := hashPassword(s.hashUtil, user.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+
+	return s.userRepo.CreateUser(ctx, user)
+}
+
+// NOTE - ทุกที่ที่ตั้ง password ต้องผ่าน function นี้ ห้าม hash เองที่อื่น
+func hashPassword(hashUtil utils.HashInterface, password string) (string, error) {
 	// NOTE - Check password length
-	if len(user.Password)<6 {
-		return errors.New("Password must more 6 char ")
+	if len(password)<6 {
+		return "", errors.New("Password must more 6 char ")
 	}
 
-	return s.userRepo.CreateUser(user)
+	hashedPassword, err := hashUtil.HashPassword(password)
+	if err != nil {
+		return "", fmt.Errorf("Failed to hash password: %w", err)
+	}
+
+	return hashedPassword, nil
 }
 
-func (s *UserService) Login(user *models.Users) (string,*models.Users,error) {
+func (s *UserService) Login(ctx context.Context, user *models.Users, client ClientInfo) (string,*models.Users,error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	defer span.End()
+
 	if user.Email =="" || user.Password =="" {
 		return "",nil,errors.New("Email or Password is required")
 	} 
+
+	// NOTE - โดนหน่วงหรือล็อกอยู่ ไม่ต้องเช็ค password
+	if err := s.loginThrottle.Check(ctx, user.Email, client.IP); err != nil {
+		recordLoginFailed(ctx, s.auditService, user.Email, nil, client, "locked")
+		return "",nil,err
+	}
 		
 	// NOTE - find User by Email
-	dbUser, err := s.userRepo.FindByEmail(user.Email)
+	dbUser, err := s.userRepo.FindByEmail(ctx, user.Email)
 	if err != nil {
-		return "",nil,errors.New("Email not found")
+		return "",nil,fmt.Errorf("Fail To Check Email : %w",err)
 	}
 
+	// NOTE - dbUser เป็น nil ก็ต้องเรียก ให้ compare กับ dummy hash ใช้เวลาเท่ากับ email ที่มีจริง
 	if !s.hashUtil.CheckPassword(dbUser ,user.Password) {
-		return "",nil,errors.New("Invalid Email or Password")
+		if err := s.loginThrottle.RegisterFailure(ctx, user.Email, client.IP); err != nil {
+			return "",nil,fmt.Errorf("Failed to record login attempt: %w", err)
+		}
+		recordLoginFailed(ctx, s.auditService, user.Email, dbUser, client, "invalid_credentials")
+		return "",nil,ErrInvalidCredentials
+	}
+
+	if err := s.loginThrottle.RegisterSuccess(ctx, user.Email); err != nil {
+		return "",nil,fmt.Errorf("Failed to record login attempt: %w", err)
 	}
 
-	token, err := s.jwtUtil.GenerateJWT(dbUser.Email)
+	// NOTE - เปิด 2FA ไว้ ยังไม่ออก JWT จริง ให้ไปยืนยันที่ /user/login/mfa ก่อน
+	if dbUser.TwoFactorEnabled {
+		mfaToken, err := s.jwtUtil.GenerateMFAToken(dbUser.Email)
+		if err != nil {
+			return "",nil,fmt.Errorf("Failed to generate token: %w", err)
+		}
+		return mfaToken,dbUser,ErrMFARequired
+	}
+
+	token, err := s.sessionService.StartSession(ctx, dbUser, client)
 	
 	if err != nil{
-		return "",nil,fmt.Errorf("Failed to generate token: %w", err)
+		return "",nil,err
 	}
 
 	return token,dbUser,nil
 
 }
 
-func (s *UserService) GetUserByEmail(email string) (*models.Users, error) {
+// NOTE - ใช้ร่วมกับ LoginMFA ทุกขั้นของการ login ที่พลาดลง audit log และ metric แบบเดียวกัน
+func recordLoginFailed(ctx context.Context, auditService AuditServiceInterface, email string, dbUser *models.Users, client ClientInfo, reason string) {
+	event := AuditEvent{
+		Action:     models.AuditLoginFailed,
+		ActorEmail: email,
+		Client:     client,
+		Metadata:   models.AuditMetadata{"reason": reason},
+	}
+	if dbUser != nil {
+		event.ActorID = dbUser.ID
+	}
+	auditService.Record(ctx, event)
+}
+
+// NOTE - token เสียหรือหมดอายุแล้วก็ไม่มี session ให้ปิด ถือว่า logout สำเร็จ
+func (s *UserService) Logout(ctx context.Context, token string, client ClientInfo) error {
+	ctx, span := tracing.Start(ctx, "UserService.Logout")
+	defer span.End()
+
+	if token == "" {
+		return nil
+	}
+
+	claims, err := s.jwtUtil.ParseJWTClaims(token)
+	if err != nil {
+		return nil
+	}
+
+	if err := s.sessionService.EndSession(ctx, claims.SessionID); err != nil {
+		return err
+	}
 
+	s.auditService.Record(ctx, AuditEvent{
+		Action:     models.AuditLogout,
+		ActorEmail: claims.Email,
+		Client:     client,
+	})
 
-	return s.userRepo.FindByEmail(email)
+	return nil
 }
 
-func (s *UserService) UpdateUserById(idStr string, emailCookie string, updatedUserValue *models.Users) (error) {
-	// NOTE - Check idStr
+// NOTE - เปลี่ยน role ได้แค่ผ่าน admin route เท่านั้น ห้ามเปลี่ยน role ตัวเองกันไม่เหลือ admin
+func (s *UserService) UpdateUserRole(ctx context.Context, adminEmail string, idStr string, role models.Role, client ClientInfo) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserRole")
+	defer span.End()
+
 	if idStr == "" {
 		return errors.New("Id is required")
 	}
 
-	// NOTE - Decode Jwt in cookie เพื่อดึง Eamil
-	email,err :=s.jwtUtil.ParseJWT(emailCookie)
-
-	if err != nil{
-		return fmt.Errorf("Fail To Check Email : %w",err)
+	if role != models.Admin && role != models.User {
+		return errors.New("Invalid role")
 	}
 
-	// NOTE - หา User จาก Email เพื่อเอา UserID 
-	user, err := s.userRepo.FindByEmail(email)
+	admin, err := s.userRepo.FindByEmail(ctx, adminEmail)
+	if err != nil || admin == nil {
+		return errors.New("User not found")
+	}
 
+	target, err := s.userRepo.FindUserById(ctx, idStr)
 	if err != nil {
+		return fmt.Errorf("failed to find user by ID: %w", err)
+	}
+
+	if target.ID == admin.ID {
+		return errors.New("You can not change your own role")
+	}
+
+	if target.Role == role {
+		return nil
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, target.ID, role); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:     models.AuditRoleChange,
+		ActorID:    admin.ID,
+		ActorEmail: admin.Email,
+		TargetID:   target.ID,
+		Client:     client,
+		Metadata:   models.AuditMetadata{"from": string(target.Role), "to": string(role)},
+	})
+
+	return nil
+}
+
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models.Users, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByEmail")
+	defer span.End()
+
+
+
+	return s.userRepo.FindByEmail(ctx, email)
+}
+
+func (s *UserService) UpdateUserById(ctx context.Context, idStr string, userEmail string, updatedUserValue *models.Users) (error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserById")
+	defer span.End()
+
+	// NOTE - Check idStr
+	if idStr == "" {
+		return errors.New("Id is required")
+	}
+
+	// NOTE - หา User จาก Email ที่ AuthMiddleware ใส่ไว้ เพื่อเอา UserID 
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
+
+	if err != nil || user == nil {
 		return  errors.New("User not found")
 	}
 
 
 	// NOTE -หา Task By ID
-	userID,err:= s.userRepo.FindUserById(idStr)
+	userID,err:= s.userRepo.FindUserById(ctx, idStr)
 
 	if err != nil {
 		return  fmt.Errorf("failed to find task by ID: %w", err)
@@ -111,11 +269,151 @@ func (s *UserService) UpdateUserById(idStr string, emailCookie string, updatedUs
 		return errors.New("you do not have permission to access this task")
 	}
 
-	if	err :=s.userRepo.UpdateUserById(updatedUserValue,userID.ID); err != nil {
+	// NOTE - ห้ามเปลี่ยน password ผ่าน route นี้ ต้องใช้ /user/password/change
+	updatedUserValue.Password = ""
+	updatedUserValue.TwoFactorEnabled = false
+	// NOTE - role เปลี่ยนได้แค่ผ่าน /admin/users/:id/role
+	updatedUserValue.Role = ""
+
+	if	err :=s.userRepo.UpdateUserById(ctx, updatedUserValue,userID.ID); err != nil {
 		return fmt.Errorf("Error : %w",err)
 	}
 	
 	return nil
 
 
-}
\ No newline at end of file
+}
+
+// NOTE - function ด้านล่างเป็นงานของ operator ผ่าน admin CLI ไม่มี user ที่ login อยู่เป็นคนทำ
+// คนที่เข้าถึง CLI ได้ก็เข้าถึง DB ได้อยู่แล้ว เลยไม่เช็คสิทธิ์ แต่ยังลง audit log ทุกครั้ง
+
+func (s *UserService) CreateUser(ctx context.Context, user *models.Users, role models.Role, client ClientInfo) error {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	if role != models.Admin && role != models.User {
+		return errors.New("Invalid role")
+	}
+	user.Role = role
+
+	if err := s.register(ctx, user); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:   models.AuditUserCreate,
+		TargetID: user.ID,
+		Client:   client,
+		Metadata: models.AuditMetadata{"role": string(role)},
+	})
+
+	return nil
+}
+
+// NOTE - ต่างจาก UpdateUserRole ตรงที่ไม่มี admin คนทำ เลยตั้ง admin คนแรกของระบบได้
+func (s *UserService) SetUserRole(ctx context.Context, email string, role models.Role, client ClientInfo) (*models.Users, error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetUserRole")
+	defer span.End()
+
+	if role != models.Admin && role != models.User {
+		return nil, errors.New("Invalid role")
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, user.ID, role); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:   models.AuditRoleChange,
+		TargetID: user.ID,
+		Client:   client,
+		Metadata: models.AuditMetadata{"from": string(user.Role), "to": string(role)},
+	})
+
+	user.Role = role
+	return user, nil
+}
+
+func (s *UserService) ResetUserPassword(ctx context.Context, email string, password string, client ClientInfo) error {
+	ctx, span := tracing.Start(ctx, "UserService.ResetUserPassword")
+	defer span.End()
+
+	hashedPassword, err := hashPassword(s.hashUtil, password)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:   models.AuditPasswordReset,
+		TargetID: user.ID,
+		Client:   client,
+	})
+
+	return s.sessionService.RevokeUserSessions(ctx, user.ID)
+}
+
+// NOTE - ปิดแล้ว login ทางไหนก็ไม่ได้ (StartSession) และ token ที่ออกไปแล้วใช้ไม่ได้ (AuthMiddleware)
+func (s *UserService) DisableUser(ctx context.Context, email string, client ClientInfo) (*models.Users, error) {
+	ctx, span := tracing.Start(ctx, "UserService.DisableUser")
+	defer span.End()
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DisabledAt != nil {
+		return user, nil
+	}
+
+	disabledAt := time.Now()
+	if err := s.userRepo.UpdateDisabled(ctx, user.ID, &disabledAt); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, AuditEvent{
+		Action:   models.AuditUserDisable,
+		TargetID: user.ID,
+		Client:   client,
+	})
+
+	if err := s.sessionService.RevokeUserSessions(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	user.DisabledAt = &disabledAt
+	return user, nil
+}
+
+func (s *UserService) findUserByEmail(ctx context.Context, email string) (*models.Users, error) {
+	if email == "" {
+		return nil, errors.New("Email is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("Fail To Check Email : %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("User not found")
+	}
+	return user, nil
+}
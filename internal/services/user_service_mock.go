@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,29 +15,64 @@ func NewUserServiceMock() *UserServiceMock {
 	return &UserServiceMock{}
 }
 
-func (m *UserServiceMock) RegisterUser(user *models.Users) error {
-	args :=m.Called(user)
+func (m *UserServiceMock) RegisterUser(ctx context.Context, user *models.Users) error {
+	args :=m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *UserServiceMock) Login(user *models.Users) (string,*models.Users,error) {
-	args :=m.Called(user)
+func (m *UserServiceMock) Login(ctx context.Context, user *models.Users, client ClientInfo) (string,*models.Users,error) {
+	args :=m.Called(ctx, user, client)
 	if task,ok := args.Get(1).(*models.Users) ; ok {
-		return args.String(0),task,nil
+		return args.String(0),task,args.Error(2)
 	}
 	return "",nil,args.Error(2)
 }
 
-func (m *UserServiceMock) GetUserByEmail(email string) (*models.Users, error) {
-	args :=m.Called(email)
+func (m *UserServiceMock) Logout(ctx context.Context, token string, client ClientInfo) error {
+	args :=m.Called(ctx, token, client)
+	return args.Error(0)
+}
+
+func (m *UserServiceMock) UpdateUserRole(ctx context.Context, adminEmail string, idStr string, role models.Role, client ClientInfo) error {
+	args :=m.Called(ctx, adminEmail, idStr, role, client)
+	return args.Error(0)
+}
+
+func (m *UserServiceMock) GetUserByEmail(ctx context.Context, email string) (*models.Users, error) {
+	args :=m.Called(ctx, email)
+	if user,ok := args.Get(0).(*models.Users);ok{
+		return user,nil
+	}
+	return nil,args.Error(1)
+}
+
+func (m *UserServiceMock) UpdateUserById(ctx context.Context, idStr string, userEmail string, updatedUserValue *models.Users) (error) {
+	args :=m.Called(ctx, idStr,userEmail,updatedUserValue)
+	return args.Error(0)
+}
+
+func (m *UserServiceMock) CreateUser(ctx context.Context, user *models.Users, role models.Role, client ClientInfo) error {
+	args :=m.Called(ctx, user, role, client)
+	return args.Error(0)
+}
+
+func (m *UserServiceMock) SetUserRole(ctx context.Context, email string, role models.Role, client ClientInfo) (*models.Users, error) {
+	args :=m.Called(ctx, email, role, client)
 	if user,ok := args.Get(0).(*models.Users);ok{
 		return user,nil
 	}
 	return nil,args.Error(1)
 }
 
-func (m *UserServiceMock) UpdateUserById(idStr string, emailCookie string, updatedUserValue *models.Users) (error) {
-	args :=m.Called(idStr,emailCookie,updatedUserValue)
+func (m *UserServiceMock) ResetUserPassword(ctx context.Context, email string, password string, client ClientInfo) error {
+	args :=m.Called(ctx, email, password, client)
 	return args.Error(0)
 }
 
+func (m *UserServiceMock) DisableUser(ctx context.Context, email string, client ClientInfo) (*models.Users, error) {
+	args :=m.Called(ctx, email, client)
+	if user,ok := args.Get(0).(*models.Users);ok{
+		return user,nil
+	}
+	return nil,args.Error(1)
+}
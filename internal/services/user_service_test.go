@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -13,6 +14,12 @@ import (
 	"gorm.io/gorm"
 )
 
+var testClient = services.ClientInfo{IP: "127.0.0.1", UserAgent: "Mozilla/5.0"}
+
+func newLoginThrottle() *services.LoginThrottle {
+	return services.NewLoginThrottle(repositories.NewLoginAttemptMemoryRepository())
+}
+
 func TestRegisterUser(t *testing.T) {
 	t.Run("Register success",func(t *testing.T) {
 		user := &models.Users{
@@ -23,22 +30,47 @@ func TestRegisterUser(t *testing.T) {
 		userRepo := repositories.NewUserRepositoryMock()
 
 		// NOTE - ส่ง email ไปเช็คว่าซ้ำกันในระบบไหม
-		userRepo.On("FindByEmail",user.Email).Return(nil,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,nil)
 
 		// NOTE - สมัครต่อได้
-		userRepo.On("CreateUser",mock.Anything).Return(nil)
+		userRepo.On("CreateUser", mock.Anything, mock.Anything).Return(nil)
 		
 		hashUtil := utils.NewHashMock()
+		hashUtil.On("HashPassword",user.Password).Return("hashedPassword",nil)
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err :=userService.RegisterUser(user)
+		err :=userService.RegisterUser(context.Background(), user)
 
 		assert.NoError(t, err)
+		assert.Equal(t, "hashedPassword", user.Password)
+		hashUtil.AssertExpectations(t)
 
 		// NOTE - เช็คว่ามีการ call function ที่เราเรียกจริงไหม
 		userRepo.AssertExpectations(t)
 	})
+	t.Run("Register always creates a plain user",func(t *testing.T) {
+		user := &models.Users{
+			Email: "Test@gmail.com",
+			Password: "Testasdfsdf",
+			Name: "tester",
+			Role: models.Admin,
+		}
+		userRepo := repositories.NewUserRepositoryMock()
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,nil)
+		userRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *models.Users) bool {
+			return u.Role == models.User
+		})).Return(nil)
+
+		hashUtil := utils.NewHashMock()
+		hashUtil.On("HashPassword",user.Password).Return("hashedPassword",nil)
+		userService := services.NewUserService(userRepo,hashUtil,utils.NewJwtMock(),newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
+
+		err :=userService.RegisterUser(context.Background(), user)
+
+		assert.NoError(t, err)
+		userRepo.AssertExpectations(t)
+	})
 	t.Run("Required Email",func(t *testing.T) {
 		user := &models.Users{
 			Email: "",
@@ -48,9 +80,9 @@ func TestRegisterUser(t *testing.T) {
 		userRepo := repositories.NewUserRepositoryMock()
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 	
-		err :=userService.RegisterUser(user)
+		err :=userService.RegisterUser(context.Background(), user)
 		assert.EqualError(t,err,"Email is required")
 	})
 
@@ -62,13 +94,13 @@ func TestRegisterUser(t *testing.T) {
 		}
 		userRepo := repositories.NewUserRepositoryMock()
 
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("Fail To Check Email"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("Fail To Check Email"))
 
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err := userService.RegisterUser(user)
+		err := userService.RegisterUser(context.Background(), user)
 
 		assert.EqualError(t,err,"Fail To Check Email : Fail To Check Email")
 	})
@@ -81,13 +113,13 @@ func TestRegisterUser(t *testing.T) {
 		}
 		userRepo := repositories.NewUserRepositoryMock()
 
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err := userService.RegisterUser(user)
+		err := userService.RegisterUser(context.Background(), user)
 
 		assert.EqualError(t,err,"Email has already been used")
 		// NOTE - เช็คว่ามีการ call function ที่เราเรียกจริงไหม
@@ -103,13 +135,13 @@ func TestRegisterUser(t *testing.T) {
 		userRepo := repositories.NewUserRepositoryMock()
 
 		// NOTE - ส่ง email ไปเช็คว่าซ้ำกันในระบบไหม
-		userRepo.On("FindByEmail",user.Email).Return(nil,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,nil)
 
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err :=userService.RegisterUser(user)
+		err :=userService.RegisterUser(context.Background(), user)
 
 		assert.EqualError(t, err,"Password must more 6 char ")
 
@@ -129,20 +161,46 @@ func TestLogin(t *testing.T){
 		userRepo := repositories.NewUserRepositoryMock()
 		hashUtil := utils.NewHashMock()
 
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 		hashUtil.On("CheckPassword",user,user.Password).Return(true)
 		
 		jwtUtil := utils.NewJwtMock()
-		jwtUtil.On("GenerateJWT",user.Email).Return("token",nil)
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		sessionService := services.NewSessionServiceMock()
+		sessionService.On("StartSession", mock.Anything, user,testClient).Return("token",nil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),sessionService,newAuditRecorder())
 
-		token,returnUser,err := userService.Login(user)
+		token,returnUser,err := userService.Login(context.Background(), user,testClient)
 
 		assert.NoError(t,err)
 		assert.NotEmpty(t,token)
 		assert.Equal(t, user.Email, returnUser.Email)
 	})
 
+	t.Run("Login MFA required",func(t *testing.T){
+		user := &models.Users{
+			Email: "login@gmail.com",
+			Password: "password",
+			TwoFactorEnabled: true,
+		}
+
+		userRepo := repositories.NewUserRepositoryMock()
+		hashUtil := utils.NewHashMock()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		hashUtil.On("CheckPassword",user,user.Password).Return(true)
+
+		jwtUtil := utils.NewJwtMock()
+		jwtUtil.On("GenerateMFAToken",user.Email).Return("mfaToken",nil)
+		sessionService := services.NewSessionServiceMock()
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),sessionService,newAuditRecorder())
+
+		token,_,err := userService.Login(context.Background(), user,testClient)
+
+		assert.ErrorIs(t,err,services.ErrMFARequired)
+		assert.Equal(t,"mfaToken",token)
+		sessionService.AssertNotCalled(t,"StartSession",mock.Anything,mock.Anything)
+	})
+
 	t.Run("Login password or email is required",func(t *testing.T) {
 		user := &models.Users{
 			Email: "",
@@ -152,13 +210,13 @@ func TestLogin(t *testing.T){
 		userRepo := repositories.NewUserRepositoryMock()
 		hashUtil := utils.NewHashMock()
 		
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 		hashUtil.On("CheckPassword",user,user.Password).Return(false)
 
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		_,_,err :=userService.Login(user)
+		_,_,err :=userService.Login(context.Background(), user,testClient)
 
 		assert.EqualError(t,err,"Email or Password is required")
 
@@ -173,14 +231,64 @@ func TestLogin(t *testing.T){
 		userRepo := repositories.NewUserRepositoryMock()
 		hashUtil := utils.NewHashMock()
 
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("Error fail"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,nil)
+		// NOTE - ยังต้อง compare (dummy hash) แม้ไม่เจอ user
+		hashUtil.On("CheckPassword",(*models.Users)(nil),user.Password).Return(false)
+		
+		jwtUtil := utils.NewJwtMock()
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
+
+		_,_,err := userService.Login(context.Background(), user,testClient)
+
+		assert.EqualError(t,err,"Invalid Email or Password")
+		hashUtil.AssertExpectations(t)
+	})
+
+	t.Run("Login Fail to check email",func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@",
+			Password: "1234551",
+		}
+
+		userRepo := repositories.NewUserRepositoryMock()
+		hashUtil := utils.NewHashMock()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("Error fail"))
 		
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		_,_,err := userService.Login(user)
+		_,_,err := userService.Login(context.Background(), user,testClient)
 
-		assert.EqualError(t,err,"Email not found")
+		assert.EqualError(t,err,"Fail To Check Email : Error fail")
+	})
+
+	t.Run("Login locked after too many failures",func(t *testing.T) {
+		user := &models.Users{
+			Email: "login@gmail.com",
+			Password: "wrong",
+		}
+
+		userRepo := repositories.NewUserRepositoryMock()
+		hashUtil := utils.NewHashMock()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		hashUtil.On("CheckPassword",user,user.Password).Return(false)
+
+		jwtUtil := utils.NewJwtMock()
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
+
+		for i := 0; i < 3; i++ {
+			_,_,err := userService.Login(context.Background(), user,testClient)
+			assert.ErrorIs(t,err,services.ErrInvalidCredentials)
+		}
+
+		// NOTE - ครั้งที่ 4 โดนหน่วงก่อนถึงการเช็ค password
+		_,_,err := userService.Login(context.Background(), user,testClient)
+
+		var throttled *services.LoginThrottledError
+		assert.ErrorAs(t,err,&throttled)
+		hashUtil.AssertNumberOfCalls(t,"CheckPassword",3)
 	})
 
 	t.Run("Login Invalid Email or Password",func(t *testing.T) {
@@ -192,13 +300,13 @@ func TestLogin(t *testing.T){
 		userRepo := repositories.NewUserRepositoryMock()
 		hashUtil := utils.NewHashMock()
 
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 		hashUtil.On("CheckPassword",user,user.Password).Return(false)
 
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		_,_,err := userService.Login(user)
+		_,_,err := userService.Login(context.Background(), user,testClient)
 
 		assert.EqualError(t,err,"Invalid Email or Password")
 		userRepo.AssertExpectations(t)
@@ -213,14 +321,15 @@ func TestLogin(t *testing.T){
 		userRepo := repositories.NewUserRepositoryMock()
 		hashUtil := utils.NewHashMock()
 
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 		hashUtil.On("CheckPassword",user,user.Password).Return(true)
 		
 		jwtUtil := utils.NewJwtMock()
-		jwtUtil.On("GenerateJWT",user.Email).Return("",errors.New("Failed to generate JWT"))
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		sessionService := services.NewSessionServiceMock()
+		sessionService.On("StartSession", mock.Anything, user,testClient).Return("",errors.New("Failed to generate token: Failed to generate JWT"))
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),sessionService,newAuditRecorder())
 
-		_,_,err := userService.Login(user)
+		_,_,err := userService.Login(context.Background(), user,testClient)
 
 		assert.EqualError(t,err,"Failed to generate token: Failed to generate JWT")
 	})
@@ -237,11 +346,11 @@ func TestGetUserByEmail(t *testing.T){
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
 
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
 
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		user,err := userService.GetUserByEmail(user.Email)
+		user,err := userService.GetUserByEmail(context.Background(), user.Email)
 
 		assert.NoError(t,err)
 		assert.NotEmpty(t,user)
@@ -254,7 +363,7 @@ func TestGetUserByEmail(t *testing.T){
 func TestUpdateUserById(t *testing.T){
 	t.Run("Update Success",func(t *testing.T){
 		idUser := "1"
-		emailToken := "fakeToken"
+		userEmail := "test@gmail.com"
 		user := &models.Users{
 			Bio: "Test Updated Bio",
 			Email:"test@gmail.com",
@@ -265,23 +374,21 @@ func TestUpdateUserById(t *testing.T){
 		hashUtil := utils.NewHashMock()
 		jwt := utils.NewJwtMock()
 
-		jwt.On("ParseJWT",emailToken).Return("test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		userRepo.On("FindUserById", idUser).Return(user,nil)
-		userRepo.On("UpdateUserById",user,user.ID).Return(nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		userRepo.On("FindUserById", mock.Anything, idUser).Return(user,nil)
+		userRepo.On("UpdateUserById", mock.Anything, user,user.ID).Return(nil)
 
-		userService := services.NewUserService(userRepo,hashUtil,jwt)
+		userService := services.NewUserService(userRepo,hashUtil,jwt,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err := userService.UpdateUserById(idUser,emailToken,user)
+		err := userService.UpdateUserById(context.Background(), idUser,userEmail,user)
 
 		assert.NoError(t,err)
 		userRepo.AssertExpectations(t)
-		jwt.AssertExpectations(t)
 	})
 
 	t.Run("Id is required",func(t *testing.T) {
 		idStr := ""
-		emailCookie:="fakeEmail"
+		userEmail:="fakeEmail"
 		user := &models.Users{
 
 			Bio: "tset",
@@ -290,37 +397,16 @@ func TestUpdateUserById(t *testing.T){
 		userRepo := repositories.NewUserRepositoryMock()
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err :=userService.UpdateUserById(idStr,emailCookie,user)
+		err :=userService.UpdateUserById(context.Background(), idStr,userEmail,user)
 		
 		assert.EqualError(t,err,"Id is required")
 	})
 
-	t.Run("Fail To Check Email",func(t *testing.T) {
-		idStr := "1"
-		emailCookie:="fakeEmail"
-		user := &models.Users{
-
-			Bio: "tset",
-		}
-
-		userRepo := repositories.NewUserRepositoryMock()
-		hashUtil := utils.NewHashMock()
-		jwtUtil := utils.NewJwtMock()
-
-		jwtUtil.On("ParseJWT",emailCookie).Return("",errors.New("Can not find you email"))
-
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
-
-		err :=userService.UpdateUserById(idStr,emailCookie,user)
-		
-		assert.EqualError(t,err,"Fail To Check Email : Can not find you email")
-	})
-
 	t.Run("User not found",func(t *testing.T) {
 		idStr := "1"
-		emailCookie:="fakeEmail"
+		userEmail:="test@gmail.com"
 		user := &models.Users{
 			Email: "test@gmail.com",
 			Bio: "tset",
@@ -330,19 +416,18 @@ func TestUpdateUserById(t *testing.T){
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT",emailCookie).Return("test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(nil,errors.New("User not found"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(nil,errors.New("User not found"))
 
-		userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
+		userService := services.NewUserService(userRepo,hashUtil,jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err :=userService.UpdateUserById(idStr,emailCookie,user)
+		err :=userService.UpdateUserById(context.Background(), idStr,userEmail,user)
 		
 		assert.EqualError(t,err,"User not found")
 	})
 
 	t.Run("Failed to find task", func(t *testing.T) {
 		idStr := "1"
-		emailCookie := "fakeEmail"
+		userEmail := "test@gmail.com"
 		user := &models.Users{
 			Email: "test@gmail.com",
 			Bio:   "test",
@@ -352,25 +437,23 @@ func TestUpdateUserById(t *testing.T){
 		hashUtil := utils.NewHashMock()
 		jwtUtil := utils.NewJwtMock()
 
-		jwtUtil.On("ParseJWT", emailCookie).Return("test@gmail.com", nil)
 
-		userRepo.On("FindByEmail", user.Email).Return(user, nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
 
-		userRepo.On("FindUserById", idStr).Return(nil, errors.New("Can not"))
+		userRepo.On("FindUserById", mock.Anything, idStr).Return(nil, errors.New("Can not"))
 
-		userService := services.NewUserService(userRepo, hashUtil, jwtUtil)
+		userService := services.NewUserService(userRepo, hashUtil, jwtUtil,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err := userService.UpdateUserById(idStr, emailCookie, user)
+		err := userService.UpdateUserById(context.Background(), idStr, userEmail, user)
 
 		assert.EqualError(t, err, "failed to find task by ID: Can not")
 
-		jwtUtil.AssertExpectations(t)
 		userRepo.AssertExpectations(t)
 	})
 
 	t.Run("Not have permission to acces task",func(t *testing.T) {
 		idUser := "1"
-		emailToken := "fakeToken"
+		userEmail := "test@gmail.com"
 		user := &models.Users{
 			Bio: "Test Updated Bio",
 			Email:"test@gmail.com",
@@ -386,20 +469,19 @@ func TestUpdateUserById(t *testing.T){
 		hashUtil := utils.NewHashMock()
 		jwt := utils.NewJwtMock()
 
-		jwt.On("ParseJWT",emailToken).Return("test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		userRepo.On("FindUserById", idUser).Return(otherUser,nil)
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		userRepo.On("FindUserById", mock.Anything, idUser).Return(otherUser,nil)
 
-		userService := services.NewUserService(userRepo,hashUtil,jwt)
+		userService := services.NewUserService(userRepo,hashUtil,jwt,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err := userService.UpdateUserById(idUser,emailToken,user)
+		err := userService.UpdateUserById(context.Background(), idUser,userEmail,user)
 
 		assert.EqualError(t,err,"you do not have permission to access this task")
 	})
 
 	t.Run("Error update user",func(t *testing.T) {
 		idUser := "1"
-		emailToken := "fakeToken"
+		userEmail := "test@gmail.com"
 		user := &models.Users{
 			Bio: "Test Updated Bio",
 			Email:"test@gmail.com",
@@ -410,15 +492,264 @@ func TestUpdateUserById(t *testing.T){
 		hashUtil := utils.NewHashMock()
 		jwt := utils.NewJwtMock()
 
-		jwt.On("ParseJWT",emailToken).Return("test@gmail.com",nil)
-		userRepo.On("FindByEmail",user.Email).Return(user,nil)
-		userRepo.On("FindUserById", idUser).Return(user,nil)
-		userRepo.On("UpdateUserById",user,user.ID).Return(errors.New("Error to update"))
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user,nil)
+		userRepo.On("FindUserById", mock.Anything, idUser).Return(user,nil)
+		userRepo.On("UpdateUserById", mock.Anything, user,user.ID).Return(errors.New("Error to update"))
 
-		userService := services.NewUserService(userRepo,hashUtil,jwt)
+		userService := services.NewUserService(userRepo,hashUtil,jwt,newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
 
-		err := userService.UpdateUserById(idUser,emailToken,user)
+		err := userService.UpdateUserById(context.Background(), idUser,userEmail,user)
 
 		assert.EqualError(t,err,"Error : Error to update")
 	})
 }
+
+func TestLoginAudit(t *testing.T) {
+	t.Run("Record failed login",func(t *testing.T) {
+		user := &models.Users{
+			Email: "test@gmail.com",
+			Password: "wrongPassword",
+		}
+		dbUser := &models.Users{Email: user.Email, Model: gorm.Model{ID: 1}}
+
+		userRepo := repositories.NewUserRepositoryMock()
+		hashUtil := utils.NewHashMock()
+		auditService := services.NewAuditServiceMock()
+
+		userRepo.On("FindByEmail", mock.Anything, user.Email).Return(dbUser,nil)
+		hashUtil.On("CheckPassword",dbUser,user.Password).Return(false)
+		auditService.On("Record", mock.Anything, services.AuditEvent{
+			Action: models.AuditLoginFailed,
+			ActorID: 1,
+			ActorEmail: user.Email,
+			Client: testClient,
+			Metadata: models.AuditMetadata{"reason":"invalid_credentials"},
+		}).Return()
+
+		userService := services.NewUserService(userRepo,hashUtil,utils.NewJwtMock(),newLoginThrottle(),services.NewSessionServiceMock(),auditService)
+
+		_,_,err := userService.Login(context.Background(), user,testClient)
+
+		assert.ErrorIs(t,err,services.ErrInvalidCredentials)
+		auditService.AssertExpectations(t)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	t.Run("Logout end session",func(t *testing.T) {
+		jwt := utils.NewJwtMock()
+		sessionService := services.NewSessionServiceMock()
+		auditService := services.NewAuditServiceMock()
+
+		jwt.On("ParseJWTClaims","jwtToken").Return(&utils.JWTClaims{Email: "test@gmail.com", SessionID: "sid"},nil)
+		sessionService.On("EndSession", mock.Anything, "sid").Return(nil)
+		auditService.On("Record", mock.Anything, services.AuditEvent{Action: models.AuditLogout, ActorEmail: "test@gmail.com", Client: testClient}).Return()
+
+		userService := services.NewUserService(repositories.NewUserRepositoryMock(),utils.NewHashMock(),jwt,newLoginThrottle(),sessionService,auditService)
+
+		err := userService.Logout(context.Background(), "jwtToken",testClient)
+
+		assert.NoError(t,err)
+		sessionService.AssertExpectations(t)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("Logout with invalid token",func(t *testing.T) {
+		jwt := utils.NewJwtMock()
+		auditService := services.NewAuditServiceMock()
+
+		jwt.On("ParseJWTClaims","expired").Return(nil,errors.New("token is expired"))
+
+		userService := services.NewUserService(repositories.NewUserRepositoryMock(),utils.NewHashMock(),jwt,newLoginThrottle(),services.NewSessionServiceMock(),auditService)
+
+		err := userService.Logout(context.Background(), "expired",testClient)
+
+		assert.NoError(t,err)
+		auditService.AssertNotCalled(t,"Record",mock.Anything)
+	})
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	admin := &models.Users{Email: "admin@gmail.com", Role: models.Admin, Model: gorm.Model{ID: 1}}
+
+	t.Run("Update role success",func(t *testing.T) {
+		target := &models.Users{Email: "test@gmail.com", Role: models.User, Model: gorm.Model{ID: 2}}
+
+		userRepo := repositories.NewUserRepositoryMock()
+		auditService := services.NewAuditServiceMock()
+
+		userRepo.On("FindByEmail", mock.Anything, admin.Email).Return(admin,nil)
+		userRepo.On("FindUserById", mock.Anything, "2").Return(target,nil)
+		userRepo.On("UpdateRole", mock.Anything, target.ID,models.Admin).Return(nil)
+		auditService.On("Record", mock.Anything, services.AuditEvent{
+			Action: models.AuditRoleChange,
+			ActorID: admin.ID,
+			ActorEmail: admin.Email,
+			TargetID: target.ID,
+			Client: testClient,
+			Metadata: models.AuditMetadata{"from":"user","to":"admin"},
+		}).Return()
+
+		userService := services.NewUserService(userRepo,utils.NewHashMock(),utils.NewJwtMock(),newLoginThrottle(),services.NewSessionServiceMock(),auditService)
+
+		err := userService.UpdateUserRole(context.Background(), admin.Email,"2",models.Admin,testClient)
+
+		assert.NoError(t,err)
+		userRepo.AssertExpectations(t)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid role",func(t *testing.T) {
+		userService := services.NewUserService(repositories.NewUserRepositoryMock(),utils.NewHashMock(),utils.NewJwtMock(),newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
+
+		err := userService.UpdateUserRole(context.Background(), admin.Email,"2",models.Role("root"),testClient)
+
+		assert.EqualError(t,err,"Invalid role")
+	})
+
+	t.Run("Change own role",func(t *testing.T) {
+		userRepo := repositories.NewUserRepositoryMock()
+
+		userRepo.On("FindByEmail", mock.Anything, admin.Email).Return(admin,nil)
+		userRepo.On("FindUserById", mock.Anything, "1").Return(admin,nil)
+
+		userService := services.NewUserService(userRepo,utils.NewHashMock(),utils.NewJwtMock(),newLoginThrottle(),services.NewSessionServiceMock(),newAuditRecorder())
+
+		err := userService.UpdateUserRole(context.Background(), admin.Email,"1",models.User,testClient)
+
+		assert.EqualError(t,err,"You can not change your own role")
+		userRepo.AssertNotCalled(t,"UpdateRole",mock.Anything,mock.Anything)
+	})
+}
+
+func newCLIUserService(userRepo repositories.UserRepositoryInterface, sessionService services.SessionServiceInterface, auditService services.AuditServiceInterface) *services.UserService {
+	hashUtil := utils.NewHashMock()
+	hashUtil.On("HashPassword", mock.Anything).Return("hashedPassword", nil)
+	return services.NewUserService(userRepo,hashUtil,utils.NewJwtMock(),newLoginThrottle(),sessionService,auditService)
+}
+
+func TestCreateUser(t *testing.T) {
+	t.Run("Create admin",func(t *testing.T) {
+		userRepo := repositories.NewUserMemoryRepository()
+		auditService := services.NewAuditServiceMock()
+		auditService.On("Record", mock.Anything, services.AuditEvent{
+			Action: models.AuditUserCreate,
+			TargetID: 1,
+			Client: testClient,
+			Metadata: models.AuditMetadata{"role":"admin"},
+		}).Return()
+
+		userService := newCLIUserService(userRepo,services.NewSessionServiceMock(),auditService)
+
+		// NOTE - role ที่ติดมากับ user ถูกทับด้วย role ที่ส่งมา
+		user := &models.Users{Email: "admin@gmail.com", Password: "secret123", Role: models.User}
+		err := userService.CreateUser(context.Background(), user, models.Admin, testClient)
+
+		assert.NoError(t,err)
+		saved, _ := userRepo.FindByEmail(context.Background(), "admin@gmail.com")
+		assert.Equal(t,models.Admin,saved.Role)
+		assert.Equal(t,"hashedPassword",saved.Password)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid role",func(t *testing.T) {
+		userRepo := repositories.NewUserMemoryRepository()
+		userService := newCLIUserService(userRepo,services.NewSessionServiceMock(),newAuditRecorder())
+
+		err := userService.CreateUser(context.Background(), &models.Users{Email: "admin@gmail.com", Password: "secret123"}, "owner", testClient)
+
+		assert.EqualError(t,err,"Invalid role")
+	})
+}
+
+func TestSetUserRole(t *testing.T) {
+	t.Run("Promote user",func(t *testing.T) {
+		userRepo := repositories.NewUserMemoryRepository()
+		assert.NoError(t,userRepo.CreateUser(context.Background(), &models.Users{Email: "user@gmail.com"}))
+		auditService := services.NewAuditServiceMock()
+		auditService.On("Record", mock.Anything, services.AuditEvent{
+			Action: models.AuditRoleChange,
+			TargetID: 1,
+			Client: testClient,
+			Metadata: models.AuditMetadata{"from":"user","to":"admin"},
+		}).Return()
+
+		userService := newCLIUserService(userRepo,services.NewSessionServiceMock(),auditService)
+
+		user, err := userService.SetUserRole(context.Background(), "user@gmail.com", models.Admin, testClient)
+
+		assert.NoError(t,err)
+		assert.Equal(t,models.Admin,user.Role)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("Same role is no-op",func(t *testing.T) {
+		userRepo := repositories.NewUserMemoryRepository()
+		assert.NoError(t,userRepo.CreateUser(context.Background(), &models.Users{Email: "user@gmail.com"}))
+		auditService := services.NewAuditServiceMock()
+
+		userService := newCLIUserService(userRepo,services.NewSessionServiceMock(),auditService)
+
+		_, err := userService.SetUserRole(context.Background(), "user@gmail.com", models.User, testClient)
+
+		assert.NoError(t,err)
+		auditService.AssertNotCalled(t,"Record",mock.Anything,mock.Anything)
+	})
+
+	t.Run("User not found",func(t *testing.T) {
+		userService := newCLIUserService(repositories.NewUserMemoryRepository(),services.NewSessionServiceMock(),newAuditRecorder())
+
+		_, err := userService.SetUserRole(context.Background(), "nobody@gmail.com", models.Admin, testClient)
+
+		assert.EqualError(t,err,"User not found")
+	})
+}
+
+func TestResetUserPassword(t *testing.T) {
+	t.Run("Reset and revoke sessions",func(t *testing.T) {
+		userRepo := repositories.NewUserMemoryRepository()
+		assert.NoError(t,userRepo.CreateUser(context.Background(), &models.Users{Email: "user@gmail.com", Password: "old"}))
+		sessionService := services.NewSessionServiceMock()
+		sessionService.On("RevokeUserSessions", mock.Anything, uint(1)).Return(nil)
+
+		userService := newCLIUserService(userRepo,sessionService,newAuditRecorder())
+
+		err := userService.ResetUserPassword(context.Background(), "user@gmail.com", "newpass123", testClient)
+
+		assert.NoError(t,err)
+		saved, _ := userRepo.FindByEmail(context.Background(), "user@gmail.com")
+		assert.Equal(t,"hashedPassword",saved.Password)
+		assert.NotNil(t,saved.PasswordChangedAt)
+		sessionService.AssertExpectations(t)
+	})
+
+	t.Run("Password too short",func(t *testing.T) {
+		userService := newCLIUserService(repositories.NewUserMemoryRepository(),services.NewSessionServiceMock(),newAuditRecorder())
+
+		err := userService.ResetUserPassword(context.Background(), "user@gmail.com", "123", testClient)
+
+		assert.EqualError(t,err,"Password must more 6 char ")
+	})
+}
+
+func TestDisableUser(t *testing.T) {
+	userRepo := repositories.NewUserMemoryRepository()
+	assert.NoError(t,userRepo.CreateUser(context.Background(), &models.Users{Email: "user@gmail.com"}))
+	sessionService := services.NewSessionServiceMock()
+	sessionService.On("RevokeUserSessions", mock.Anything, uint(1)).Return(nil)
+	auditService := services.NewAuditServiceMock()
+	auditService.On("Record", mock.Anything, services.AuditEvent{Action: models.AuditUserDisable, TargetID: 1, Client: testClient}).Return()
+
+	userService := newCLIUserService(userRepo,sessionService,auditService)
+
+	user, err := userService.DisableUser(context.Background(), "user@gmail.com", testClient)
+	assert.NoError(t,err)
+	assert.NotNil(t,user.DisabledAt)
+
+	// NOTE - สั่งซ้ำไม่ลง audit ซ้ำ
+	_, err = userService.DisableUser(context.Background(), "user@gmail.com", testClient)
+	assert.NoError(t,err)
+
+	auditService.AssertNumberOfCalls(t,"Record",1)
+	sessionService.AssertNumberOfCalls(t,"RevokeUserSessions",1)
+}
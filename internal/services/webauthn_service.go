@@ -0,0 +1,384 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	webAuthnCeremonyRegistration = "registration"
+	webAuthnCeremonyLogin        = "login"
+	webAuthnSessionTTL           = 5 * time.Minute
+)
+
+type WebAuthnServiceInterface interface {
+	BeginRegistration(ctx context.Context, email string) (string, *protocol.CredentialCreation, error)
+	FinishRegistration(ctx context.Context, email string, sessionKey string, name string, response []byte) (*models.WebAuthnCredentials, error)
+	BeginLogin(ctx context.Context) (string, *protocol.CredentialAssertion, error)
+	FinishLogin(ctx context.Context, sessionKey string, response []byte, client ClientInfo) (string, *models.Users, error)
+	GetCredentials(ctx context.Context, email string) ([]models.WebAuthnCredentials, error)
+	RenameCredential(ctx context.Context, email string, idStr string, name string) error
+	DeleteCredential(ctx context.Context, email string, idStr string) error
+}
+
+type WebAuthnService struct {
+	userRepo       repositories.UserRepositoryInterface
+	webAuthnRepo   repositories.WebAuthnRepositoryInterface
+	webAuthn       *webauthn.WebAuthn
+	sessionService SessionServiceInterface
+}
+
+func NewWebAuthnService(userRepo repositories.UserRepositoryInterface, webAuthnRepo repositories.WebAuthnRepositoryInterface, webAuthn *webauthn.WebAuthn, sessionService SessionServiceInterface) *WebAuthnService {
+	return &WebAuthnService{userRepo: userRepo, webAuthnRepo: webAuthnRepo, webAuthn: webAuthn, sessionService: sessionService}
+}
+
+// NOTE - adapter ให้ models.Users ใช้กับ library webauthn ได้
+type webAuthnUser struct {
+	user        *models.Users
+	credentials []models.WebAuthnCredentials
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return webAuthnUserHandle(u.user.ID)
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	if u.user.Name != "" {
+		return u.user.Name
+	}
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		credentials = append(credentials, toWebAuthnCredential(c))
+	}
+	return credentials
+}
+
+func (u *webAuthnUser) findCredential(credentialID []byte) *models.WebAuthnCredentials {
+	for i := range u.credentials {
+		if bytes.Equal(u.credentials[i].CredentialID, credentialID) {
+			return &u.credentials[i]
+		}
+	}
+	return nil
+}
+
+// NOTE - user handle คือ user ID แบบ 8 byte ไม่มีข้อมูลส่วนตัว
+func webAuthnUserHandle(userID uint) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
+
+func toWebAuthnCredential(c models.WebAuthnCredentials) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	if c.Transports != "" {
+		for _, t := range strings.Split(c.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+	}
+
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:       c.AAGUID,
+			SignCount:    c.SignCount,
+			CloneWarning: c.CloneWarning,
+		},
+	}
+}
+
+func (s *WebAuthnService) loadUser(ctx context.Context, email string) (*webAuthnUser, error) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
+	}
+
+	credentials, err := s.webAuthnRepo.FindCredentialsByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find credentials: %w", err)
+	}
+
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+func (s *WebAuthnService) saveSession(ctx context.Context, userID uint, ceremony string, data *webauthn.SessionData) (string, error) {
+	sessionKey, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate session: %w", err)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode session: %w", err)
+	}
+
+	session := &models.WebAuthnSessions{
+		SessionKey: sessionKey,
+		UserID:     userID,
+		Ceremony:   ceremony,
+		Data:       string(raw),
+		ExpiresAt:  time.Now().Add(webAuthnSessionTTL),
+	}
+
+	if err := s.webAuthnRepo.CreateSession(ctx, session); err != nil {
+		return "", fmt.Errorf("Failed to create session: %w", err)
+	}
+
+	return sessionKey, nil
+}
+
+func (s *WebAuthnService) loadSession(ctx context.Context, sessionKey string, ceremony string) (*models.WebAuthnSessions, *webauthn.SessionData, error) {
+	if sessionKey == "" {
+		return nil, nil, errors.New("Session is required")
+	}
+
+	session, err := s.webAuthnRepo.ConsumeSession(ctx, sessionKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to find session: %w", err)
+	}
+
+	if session == nil || session.Ceremony != ceremony {
+		return nil, nil, errors.New("Invalid or expired session")
+	}
+
+	var data webauthn.SessionData
+	if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+		return nil, nil, fmt.Errorf("Failed to decode session: %w", err)
+	}
+
+	return session, &data, nil
+}
+
+func (s *WebAuthnService) BeginRegistration(ctx context.Context, email string) (string, *protocol.CredentialCreation, error) {
+	user, err := s.loadUser(ctx, email)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// NOTE - กันลงทะเบียน authenticator ตัวเดิมซ้ำ
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, c := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	creation, data, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		// NOTE - login เป็น discoverable อย่างเดียว credential ที่ไม่ใช่ resident key จะใช้ login ไม่ได้
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to begin registration: %w", err)
+	}
+
+	sessionKey, err := s.saveSession(ctx, user.user.ID, webAuthnCeremonyRegistration, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sessionKey, creation, nil
+}
+
+func (s *WebAuthnService) FinishRegistration(ctx context.Context, email string, sessionKey string, name string, response []byte) (*models.WebAuthnCredentials, error) {
+	user, err := s.loadUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	session, data, err := s.loadSession(ctx, sessionKey, webAuthnCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != user.user.ID {
+		return nil, errors.New("Invalid or expired session")
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid credential: %w", err)
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *data, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to verify credential: %w", err)
+	}
+
+	if name == "" {
+		name = "Passkey"
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+
+	record := &models.WebAuthnCredentials{
+		UserID:          user.user.ID,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+
+	if err := s.webAuthnRepo.CreateCredential(ctx, record); err != nil {
+		return nil, fmt.Errorf("Failed to save credential: %w", err)
+	}
+
+	return record, nil
+}
+
+// NOTE - discoverable login (passkey) อย่างเดียว ไม่รับ email เพราะถ้าส่ง allowCredentials กลับให้เฉพาะ email ที่มี passkey
+// response จะบอกได้ว่า email ไหนสมัครไว้ authenticator เป็นคนเลือก credential แล้วส่ง user handle กลับมาเอง
+func (s *WebAuthnService) BeginLogin(ctx context.Context) (string, *protocol.CredentialAssertion, error) {
+	assertion, data, err := s.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to begin login: %w", err)
+	}
+
+	sessionKey, err := s.saveSession(ctx, 0, webAuthnCeremonyLogin, data)
+	if err != nil {
+		return "", nil, err
+	}
+	return sessionKey, assertion, nil
+}
+
+func (s *WebAuthnService) FinishLogin(ctx context.Context, sessionKey string, response []byte, client ClientInfo) (string, *models.Users, error) {
+	_, data, err := s.loadSession(ctx, sessionKey, webAuthnCeremonyLogin)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return "", nil, fmt.Errorf("Invalid credential: %w", err)
+	}
+
+	var user *webAuthnUser
+	credential, err := s.webAuthn.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		if len(userHandle) != 8 {
+			return nil, errors.New("Invalid user handle")
+		}
+
+		found, err := s.userRepo.FindUserById(ctx, strconv.FormatUint(binary.BigEndian.Uint64(userHandle), 10))
+		if err != nil {
+			return nil, err
+		}
+
+		user, err = s.loadUser(ctx, found.Email)
+		return user, err
+	}, *data, parsed)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to verify credential: %w", err)
+	}
+
+	record := user.findCredential(credential.ID)
+	if record == nil {
+		return "", nil, errors.New("Credential not found")
+	}
+
+	if err := s.webAuthnRepo.UpdateCredentialUsage(ctx, record.ID, credential.Authenticator.SignCount, credential.Authenticator.CloneWarning, credential.Flags.BackupState); err != nil {
+		return "", nil, err
+	}
+
+	// NOTE - sign count ไม่เพิ่มแปลว่า authenticator อาจถูก clone ไม่ให้ login
+	if credential.Authenticator.CloneWarning {
+		return "", nil, errors.New("Credential may be cloned, please sign in another way")
+	}
+
+	token, err := s.sessionService.StartSession(ctx, user.user, client)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, user.user, nil
+}
+
+func (s *WebAuthnService) GetCredentials(ctx context.Context, email string) ([]models.WebAuthnCredentials, error) {
+	user, err := s.loadUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.credentials, nil
+}
+
+func (s *WebAuthnService) findOwnCredential(ctx context.Context, email string, idStr string) (*models.WebAuthnCredentials, error) {
+	if idStr == "" {
+		return nil, errors.New("Id is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, errors.New("User not found")
+	}
+
+	credential, err := s.webAuthnRepo.FindCredentialById(ctx, idStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find credential by ID: %w", err)
+	}
+
+	if credential.UserID != user.ID {
+		return nil, errors.New("you do not have permission to access this credential")
+	}
+
+	return credential, nil
+}
+
+func (s *WebAuthnService) RenameCredential(ctx context.Context, email string, idStr string, name string) error {
+	if name == "" {
+		return errors.New("Name is required")
+	}
+
+	credential, err := s.findOwnCredential(ctx, email, idStr)
+	if err != nil {
+		return err
+	}
+
+	return s.webAuthnRepo.RenameCredential(ctx, credential.ID, name)
+}
+
+func (s *WebAuthnService) DeleteCredential(ctx context.Context, email string, idStr string) error {
+	credential, err := s.findOwnCredential(ctx, email, idStr)
+	if err != nil {
+		return err
+	}
+
+	return s.webAuthnRepo.DeleteCredentialById(ctx, credential.ID)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebAuthnServiceMock struct {
+	mock.Mock
+}
+
+func NewWebAuthnServiceMock() *WebAuthnServiceMock {
+	return &WebAuthnServiceMock{}
+}
+
+func (m *WebAuthnServiceMock) BeginRegistration(ctx context.Context, email string) (string, *protocol.CredentialCreation, error) {
+	args := m.Called(ctx, email)
+	if creation, ok := args.Get(1).(*protocol.CredentialCreation); ok {
+		return args.String(0), creation, args.Error(2)
+	}
+	return "", nil, args.Error(2)
+}
+
+func (m *WebAuthnServiceMock) FinishRegistration(ctx context.Context, email string, sessionKey string, name string, response []byte) (*models.WebAuthnCredentials, error) {
+	args := m.Called(ctx, email, sessionKey, name, response)
+	if credential, ok := args.Get(0).(*models.WebAuthnCredentials); ok {
+		return credential, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebAuthnServiceMock) BeginLogin(ctx context.Context) (string, *protocol.CredentialAssertion, error) {
+	args := m.Called(ctx)
+	if assertion, ok := args.Get(1).(*protocol.CredentialAssertion); ok {
+		return args.String(0), assertion, args.Error(2)
+	}
+	return "", nil, args.Error(2)
+}
+
+func (m *WebAuthnServiceMock) FinishLogin(ctx context.Context, sessionKey string, response []byte, client ClientInfo) (string, *models.Users, error) {
+	args := m.Called(ctx, sessionKey, response, client)
+	if user, ok := args.Get(1).(*models.Users); ok {
+		return args.String(0), user, args.Error(2)
+	}
+	return "", nil, args.Error(2)
+}
+
+func (m *WebAuthnServiceMock) GetCredentials(ctx context.Context, email string) ([]models.WebAuthnCredentials, error) {
+	args := m.Called(ctx, email)
+	if credentials, ok := args.Get(0).([]models.WebAuthnCredentials); ok {
+		return credentials, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebAuthnServiceMock) RenameCredential(ctx context.Context, email string, idStr string, name string) error {
+	args := m.Called(ctx, email, idStr, name)
+	return args.Error(0)
+}
+
+func (m *WebAuthnServiceMock) DeleteCredential(ctx context.Context, email string, idStr string) error {
+	args := m.Called(ctx, email, idStr)
+	return args.Error(0)
+}
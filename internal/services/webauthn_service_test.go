@@ -0,0 +1,361 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const (
+	testRPID     = "localhost"
+	testRPOrigin = "http://localhost:3000"
+)
+
+// NOTE - authenticator จำลองด้วย ECDSA P-256 ไม่ต้องมี hardware จริง
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softAuthenticator{key: key, credentialID: credentialID, origin: testRPOrigin}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge []byte) []byte {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": b64(challenge),
+		"origin":    a.origin,
+	})
+	require.NoError(t, err)
+	return clientData
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	counter := make([]byte, 4)
+	binary.BigEndian.PutUint32(counter, a.signCount)
+	data = append(data, counter...)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) create(t *testing.T, creation *protocol.CredentialCreation) []byte {
+	a.userHandle = creation.Response.User.ID.(protocol.URLEncodedBase64)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1,
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	attested := make([]byte, 16) // NOTE - AAGUID เป็น 0 ทั้งหมด
+	idLength := make([]byte, 2)
+	binary.BigEndian.PutUint16(idLength, uint16(len(a.credentialID)))
+	attested = append(attested, idLength...)
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	// NOTE - flags UP | UV | AT
+	authData := a.authData(creation.Response.RelyingParty.ID, 0x45, attested)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	response, err := json.Marshal(map[string]interface{}{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(a.clientData(t, "webauthn.create", creation.Response.Challenge)),
+			"attestationObject": b64(attestationObject),
+		},
+	})
+	require.NoError(t, err)
+	return response
+}
+
+func (a *softAuthenticator) get(t *testing.T, assertion *protocol.CredentialAssertion) []byte {
+	a.signCount++
+
+	// NOTE - flags UP | UV
+	authData := a.authData(assertion.Response.RelyingPartyID, 0x05, nil)
+	clientData := a.clientData(t, "webauthn.get", assertion.Response.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	response, err := json.Marshal(map[string]interface{}{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(clientData),
+			"authenticatorData": b64(authData),
+			"signature":         b64(signature),
+			"userHandle":        b64(a.userHandle),
+		},
+	})
+	require.NoError(t, err)
+	return response
+}
+
+type webAuthnFixture struct {
+	service        *services.WebAuthnService
+	userRepo       *repositories.UserRepositoryMock
+	webAuthnRepo   *repositories.WebAuthnRepositoryMock
+	sessionService *services.SessionServiceMock
+	user           *models.Users
+	sessions       map[string]*models.WebAuthnSessions
+}
+
+func newWebAuthnFixture(t *testing.T) *webAuthnFixture {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "BelugaTasks",
+		RPOrigins:     []string{testRPOrigin},
+	})
+	require.NoError(t, err)
+
+	f := &webAuthnFixture{
+		userRepo:       repositories.NewUserRepositoryMock(),
+		webAuthnRepo:   repositories.NewWebAuthnRepositoryMock(),
+		sessionService: services.NewSessionServiceMock(),
+		user:           &models.Users{Email: "passkey@gmail.com", Name: "Passkey User", Model: gorm.Model{ID: 7}},
+		sessions:       map[string]*models.WebAuthnSessions{},
+	}
+	f.service = services.NewWebAuthnService(f.userRepo, f.webAuthnRepo, webAuthn, f.sessionService)
+
+	f.userRepo.On("FindByEmail", mock.Anything, f.user.Email).Return(f.user, nil)
+	f.userRepo.On("FindUserById", mock.Anything, "7").Return(f.user, nil)
+	f.webAuthnRepo.On("CreateSession", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		session := args.Get(1).(*models.WebAuthnSessions)
+		f.sessions[session.SessionKey] = session
+	}).Return(nil)
+
+	return f
+}
+
+// NOTE - ให้ ConsumeSession คืน session ที่ service บันทึกไว้ตอน begin ได้ครั้งเดียว
+func (f *webAuthnFixture) expectConsume(sessionKey string) {
+	f.webAuthnRepo.On("ConsumeSession", mock.Anything, sessionKey).Return(f.sessions[sessionKey], nil).Once()
+}
+
+func (f *webAuthnFixture) register(t *testing.T, authenticator *softAuthenticator) *models.WebAuthnCredentials {
+	f.webAuthnRepo.On("FindCredentialsByUserId", mock.Anything, f.user.ID).Return([]models.WebAuthnCredentials{}, nil).Twice()
+
+	sessionKey, creation, err := f.service.BeginRegistration(context.Background(), f.user.Email)
+	require.NoError(t, err)
+
+	var saved *models.WebAuthnCredentials
+	f.webAuthnRepo.On("CreateCredential", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*models.WebAuthnCredentials)
+		saved.ID = 1
+	}).Return(nil).Once()
+	f.expectConsume(sessionKey)
+
+	credential, err := f.service.FinishRegistration(context.Background(), f.user.Email, sessionKey, "My laptop", authenticator.create(t, creation))
+	require.NoError(t, err)
+	require.Equal(t, saved, credential)
+
+	f.webAuthnRepo.On("FindCredentialsByUserId", mock.Anything, f.user.ID).Return([]models.WebAuthnCredentials{*saved}, nil)
+	return saved
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	t.Run("Register passkey success", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+		authenticator := newSoftAuthenticator(t)
+
+		credential := f.register(t, authenticator)
+
+		assert.Equal(t, f.user.ID, credential.UserID)
+		assert.Equal(t, "My laptop", credential.Name)
+		assert.Equal(t, authenticator.credentialID, credential.CredentialID)
+		assert.Equal(t, "none", credential.AttestationType)
+		assert.Equal(t, uint32(0), credential.SignCount)
+	})
+
+	t.Run("Require a discoverable credential", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+		f.webAuthnRepo.On("FindCredentialsByUserId", mock.Anything, f.user.ID).Return([]models.WebAuthnCredentials{}, nil)
+
+		_, creation, err := f.service.BeginRegistration(context.Background(), f.user.Email)
+
+		require.NoError(t, err)
+		assert.Equal(t, protocol.ResidentKeyRequirementRequired, creation.Response.AuthenticatorSelection.ResidentKey)
+	})
+
+	t.Run("Reject response from other origin", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+		authenticator := newSoftAuthenticator(t)
+		authenticator.origin = "https://evil.example.com"
+
+		f.webAuthnRepo.On("FindCredentialsByUserId", mock.Anything, f.user.ID).Return([]models.WebAuthnCredentials{}, nil)
+
+		sessionKey, creation, err := f.service.BeginRegistration(context.Background(), f.user.Email)
+		require.NoError(t, err)
+		f.expectConsume(sessionKey)
+
+		_, err = f.service.FinishRegistration(context.Background(), f.user.Email, sessionKey, "Evil", authenticator.create(t, creation))
+
+		assert.ErrorContains(t, err, "Failed to verify credential")
+		f.webAuthnRepo.AssertNotCalled(t, "CreateCredential", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid session", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+
+		f.webAuthnRepo.On("FindCredentialsByUserId", mock.Anything, f.user.ID).Return([]models.WebAuthnCredentials{}, nil)
+		f.webAuthnRepo.On("ConsumeSession", mock.Anything, "unknown").Return(nil, nil)
+
+		_, err := f.service.FinishRegistration(context.Background(), f.user.Email, "unknown", "", []byte(`{}`))
+
+		assert.EqualError(t, err, "Invalid or expired session")
+	})
+}
+
+func TestWebAuthnLogin(t *testing.T) {
+	t.Run("Login success", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+		authenticator := newSoftAuthenticator(t)
+		credential := f.register(t, authenticator)
+
+		sessionKey, assertion, err := f.service.BeginLogin(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, assertion.Response.AllowedCredentials)
+		f.expectConsume(sessionKey)
+
+		f.webAuthnRepo.On("UpdateCredentialUsage", mock.Anything, credential.ID, uint32(1), false, false).Return(nil)
+		f.sessionService.On("StartSession", mock.Anything, mock.AnythingOfType("*models.Users"), testClient).Return("token", nil)
+
+		token, _, err := f.service.FinishLogin(context.Background(), sessionKey, authenticator.get(t, assertion), testClient)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "token", token)
+	})
+
+	t.Run("Reject cloned authenticator", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+		authenticator := newSoftAuthenticator(t)
+		credential := f.register(t, authenticator)
+
+		// NOTE - DB บันทึก sign count ไว้สูงกว่าที่ authenticator ส่งมา
+		credential.SignCount = 10
+		f.webAuthnRepo.ExpectedCalls = nil
+		f.webAuthnRepo.On("CreateSession", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			session := args.Get(1).(*models.WebAuthnSessions)
+			f.sessions[session.SessionKey] = session
+		}).Return(nil)
+		f.webAuthnRepo.On("FindCredentialsByUserId", mock.Anything, f.user.ID).Return([]models.WebAuthnCredentials{*credential}, nil)
+
+		sessionKey, assertion, err := f.service.BeginLogin(context.Background())
+		require.NoError(t, err)
+		f.expectConsume(sessionKey)
+
+		f.webAuthnRepo.On("UpdateCredentialUsage", mock.Anything, credential.ID, uint32(10), true, false).Return(nil)
+
+		_, _, err = f.service.FinishLogin(context.Background(), sessionKey, authenticator.get(t, assertion), testClient)
+
+		assert.EqualError(t, err, "Credential may be cloned, please sign in another way")
+		f.sessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reject signature from other key", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+		authenticator := newSoftAuthenticator(t)
+		f.register(t, authenticator)
+
+		sessionKey, assertion, err := f.service.BeginLogin(context.Background())
+		require.NoError(t, err)
+		f.expectConsume(sessionKey)
+
+		// NOTE - ใช้ credential ID เดิมแต่ key อื่น
+		other := newSoftAuthenticator(t)
+		other.credentialID = authenticator.credentialID
+		other.userHandle = authenticator.userHandle
+
+		_, _, err = f.service.FinishLogin(context.Background(), sessionKey, other.get(t, assertion), testClient)
+
+		assert.ErrorContains(t, err, "Failed to verify credential")
+		f.webAuthnRepo.AssertNotCalled(t, "UpdateCredentialUsage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWebAuthnCredentialManagement(t *testing.T) {
+	t.Run("Delete own credential", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+
+		f.webAuthnRepo.On("FindCredentialById", mock.Anything, "1").Return(&models.WebAuthnCredentials{UserID: f.user.ID, Model: gorm.Model{ID: 1}}, nil)
+		f.webAuthnRepo.On("DeleteCredentialById", mock.Anything, uint(1)).Return(nil)
+
+		err := f.service.DeleteCredential(context.Background(), f.user.Email, "1")
+
+		assert.NoError(t, err)
+		f.webAuthnRepo.AssertCalled(t, "DeleteCredentialById", mock.Anything, uint(1))
+	})
+
+	t.Run("Cannot delete other user credential", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+
+		f.webAuthnRepo.On("FindCredentialById", mock.Anything, "1").Return(&models.WebAuthnCredentials{UserID: 99, Model: gorm.Model{ID: 1}}, nil)
+
+		err := f.service.DeleteCredential(context.Background(), f.user.Email, "1")
+
+		assert.EqualError(t, err, "you do not have permission to access this credential")
+		f.webAuthnRepo.AssertNotCalled(t, "DeleteCredentialById", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rename requires name", func(t *testing.T) {
+		f := newWebAuthnFixture(t)
+
+		err := f.service.RenameCredential(context.Background(), f.user.Email, "1", "")
+
+		assert.EqualError(t, err, "Name is required")
+	})
+}
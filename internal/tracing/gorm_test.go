@@ -0,0 +1,95 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/models"
+	"github.com/Beluga-Whale/management-api/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTracingDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&models.Users{}))
+	require.NoError(t, db.Use(tracing.NewGormPlugin()))
+	return db
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]string {
+	attrs := map[attribute.Key]string{}
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value.Emit()
+	}
+	return attrs
+}
+
+func TestGormPlugin(t *testing.T) {
+	t.Run("Query span is a child of the caller span", func(t *testing.T) {
+		db := newTracingDB(t)
+		exporter := newSpanRecorder(t)
+
+		ctx, parent := tracing.Start(context.Background(), "UserRepository.CreateUser")
+		require.NoError(t, db.WithContext(ctx).Create(&models.Users{Email: "secret@example.com", Name: "A", Password: "hash-must-not-leak"}).Error)
+		parent.End()
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		query := spans[0]
+		assert.Equal(t, "gorm.create", query.Name)
+		assert.Equal(t, trace.SpanKindClient, query.SpanKind)
+		assert.Equal(t, parent.SpanContext().SpanID(), query.Parent.SpanID())
+		assert.Equal(t, codes.Unset, query.Status.Code)
+
+		attrs := spanAttributes(query)
+		assert.Equal(t, "sqlite", attrs["db.system"])
+		assert.Equal(t, "create", attrs["db.operation.name"])
+		assert.Equal(t, "users", attrs["db.collection.name"])
+		assert.Contains(t, attrs["db.query.text"], "INSERT INTO `users`")
+		assert.NotContains(t, attrs["db.query.text"], "hash-must-not-leak")
+		assert.NotContains(t, attrs["db.query.text"], "secret@example.com")
+	})
+
+	t.Run("Not found is not an error", func(t *testing.T) {
+		db := newTracingDB(t)
+		exporter := newSpanRecorder(t)
+
+		err := db.WithContext(context.Background()).First(&models.Users{}, 42).Error
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "gorm.query", spans[0].Name)
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	})
+
+	t.Run("Record database errors", func(t *testing.T) {
+		db := newTracingDB(t)
+		exporter := newSpanRecorder(t)
+
+		err := db.WithContext(context.Background()).Exec("UPDATE missing_table SET name = ?", "A").Error
+
+		require.Error(t, err)
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "gorm.raw", spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		require.Len(t, spans[0].Events, 1)
+		assert.Equal(t, "exception", spans[0].Events[0].Name)
+	})
+}
@@ -0,0 +1,63 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NOTE - ตั้ง global provider ให้ส่ง span เข้า memory ทันที (WithSyncer) แล้วคืนของเดิมตอนจบ test
+func newSpanRecorder(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("None still propagates trace context", func(t *testing.T) {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+		shutdown, err := tracing.Setup(context.Background(), tracing.Options{Exporter: "none", ServiceName: "belugatasks", SampleRatio: 1})
+
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+		assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+	})
+
+	t.Run("Unsupported exporter", func(t *testing.T) {
+		shutdown, err := tracing.Setup(context.Background(), tracing.Options{Exporter: "zipkin"})
+
+		assert.Nil(t, shutdown)
+		assert.EqualError(t, err, "Unsupported tracing exporter: zipkin")
+	})
+}
+
+func TestStart(t *testing.T) {
+	exporter := newSpanRecorder(t)
+
+	ctx, parent := tracing.Start(context.Background(), "TaskService.CreateTask")
+	_, child := tracing.Start(ctx, "TaskRepository.CreateTask")
+	child.End()
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "TaskRepository.CreateTask", spans[0].Name)
+	assert.Equal(t, "TaskService.CreateTask", spans[1].Name)
+	assert.Equal(t, spans[1].SpanContext.TraceID(), spans[0].SpanContext.TraceID())
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+}
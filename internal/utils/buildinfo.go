@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+type BuildInfo struct {
+	GitSHA    string `json:"git_sha"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// NOTE - gitSHA/buildTime มาจาก -ldflags ตอน build ถ้าไม่ได้ใส่ (go run/go build เฉยๆ) ใช้ข้อมูล VCS ที่ Go ฝังไว้แทน
+func NewBuildInfo(gitSHA string, buildTime string) BuildInfo {
+	info := BuildInfo{GitSHA: gitSHA, BuildTime: buildTime, GoVersion: runtime.Version()}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.GitSHA == "":
+				info.GitSHA = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	if info.GitSHA == "" {
+		info.GitSHA = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
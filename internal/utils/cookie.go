@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NOTE - attribute ของ cookie ที่ต่างกันตาม environment เช่น dev บน http://localhost ใช้ Secure/Domain ของ production ไม่ได้
+type CookieConfig struct {
+	Name     string        `yaml:"name"`
+	Domain   string        `yaml:"domain"`
+	MaxAge   time.Duration `yaml:"max_age"`
+	SameSite string        `yaml:"same_site"`
+	Secure   bool          `yaml:"secure"`
+}
+
+func (cfg CookieConfig) Validate() error {
+	if cfg.Name == "" {
+		return errors.New("Cookie name is required")
+	}
+	if cfg.MaxAge <= 0 {
+		return errors.New("Cookie max age must be positive")
+	}
+
+	switch strings.ToLower(cfg.SameSite) {
+	case fiber.CookieSameSiteLaxMode, fiber.CookieSameSiteStrictMode:
+	case fiber.CookieSameSiteNoneMode:
+		// NOTE - browser ทิ้ง cookie SameSite=None ที่ไม่ได้ Secure
+		if !cfg.Secure {
+			return errors.New("Cookie SameSite=None requires Secure")
+		}
+	default:
+		return errors.New("Cookie SameSite must be Lax, Strict or None")
+	}
+
+	return nil
+}
+
+// NOTE - cookie อื่นที่ต้องส่งคู่กับ auth cookie (csrf, device) ใช้ domain/secure/samesite ชุดเดียวกัน
+func (cfg CookieConfig) New(name string, value string, maxAge time.Duration) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cfg.Domain,
+		Expires:  time.Now().Add(maxAge),
+		HTTPOnly: true,
+		Secure:   cfg.Secure,
+		SameSite: strings.ToLower(cfg.SameSite),
+	}
+}
+
+// NOTE - browser จะลบ cookie ก็ต่อเมื่อ name/domain/path ตรงกับตอนตั้ง เลยต้องใช้ attribute ชุดเดิม
+func (cfg CookieConfig) Expire(name string) *fiber.Cookie {
+	cookie := cfg.New(name, "", 0)
+	cookie.Expires = time.Unix(0, 0)
+	return cookie
+}
+
+func (cfg CookieConfig) AuthCookie(token string) *fiber.Cookie {
+	return cfg.New(cfg.Name, token, cfg.MaxAge)
+}
+
+func (cfg CookieConfig) ClearAuthCookie() *fiber.Cookie {
+	return cfg.Expire(cfg.Name)
+}
+
+func (cfg CookieConfig) AuthToken(c *fiber.Ctx) string {
+	return c.Cookies(cfg.Name)
+}
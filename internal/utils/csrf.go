@@ -0,0 +1,22 @@
+package utils
+
+import "crypto/subtle"
+
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+
+	csrfTokenSize = 32
+)
+
+func GenerateCSRFToken() (string, error) {
+	return GenerateRandomToken(csrfTokenSize)
+}
+
+// NOTE - double-submit: header ต้องตรงกับ cookie เว็บอื่นส่ง cookie มาได้แต่อ่านค่าไปใส่ header ไม่ได้
+func ValidCSRFToken(cookieToken string, headerToken string) bool {
+	if len(cookieToken) != csrfTokenSize*2 || headerToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookieToken), []byte(headerToken)) == 1
+}
@@ -1,25 +1,52 @@
 package utils
 
 import (
+	"sync"
+
 	"github.com/Beluga-Whale/management-api/internal/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type HashInterface interface {
+	HashPassword(password string) (string, error)
 	CheckPassword(user *models.Users, password string) bool
 }
 
 type Hash struct{}
 
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// NOTE - hash ทิ้งไว้ compare ตอนไม่เจอ user ให้ใช้เวลาเท่ากับ compare จริง
+func getDummyHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
 func NewHash() *Hash{
+	getDummyHash()
 	return &Hash{}
 }
 
+func (h *Hash) HashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashedPassword), nil
+}
+
 func (h *Hash) CheckPassword(user *models.Users, password string) bool {
 	if user == nil {
+		bcrypt.CompareHashAndPassword(getDummyHash(), []byte(password))
 		return false
 	}
 
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	return err == nil
-}
\ No newline at end of file
+}
@@ -13,6 +13,11 @@ func NewHashMock() *HashMock {
 	return &HashMock{}
 }
 
+func (m *HashMock) HashPassword(password string) (string, error) {
+	args :=m.Called(password)
+	return args.String(0),args.Error(1)
+}
+
 func (m *HashMock) CheckPassword(user *models.Users, password string) bool {
 	args :=m.Called(user,password)
 	return args.Bool(0)
@@ -2,58 +2,196 @@ package utils
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type JwtInterface interface {
-	GenerateJWT(email string) (string, error)
+	GenerateSessionJWT(email string, sessionID string) (string, error)
 	ParseJWT(tokenString string) (string, error)
+	ParseJWTClaims(tokenString string) (*JWTClaims, error)
+	GenerateMFAToken(email string) (string, error)
+	ParseMFAToken(tokenString string) (string, error)
+	GenerateMagicLinkToken(email string, linkID string, fingerprintHash string) (string, error)
+	ParseMagicLinkToken(tokenString string) (*JWTClaims, error)
 }
 
+const (
+	mfaPurpose = "mfa"
+	magicLinkPurpose = "magic_link"
+
+	// NOTE - อายุ token login ใช้คำนวณว่า key เก่าต้องเก็บไว้ verify นานแค่ไหน
+	JWTTTL = 24 * time.Hour
+	mfaTTL = 5 * time.Minute
+	MagicLinkTTL = 15 * time.Minute
+)
+
 type JWTClaims struct {
 	Email string `json:"email"`
+	Purpose string `json:"purpose,omitempty"` // NOTE - ว่างคือ token สำหรับ login ปกติ
+	Fingerprint string `json:"fph,omitempty"` // NOTE - hash ของ device ที่ขอ magic link
+	SessionID string `json:"sid,omitempty"` // NOTE - ว่างคือ token ที่ไม่ผูกกับ session
 	jwt.RegisteredClaims
 }
 
-func NewJwt() *JWTClaims{
-	return &JWTClaims{}
+type Jwt struct {
+	keyring  KeyringInterface
+	issuer   string
+	audience string
 }
 
-func (c *JWTClaims) GenerateJWT(email string) (string, error) {
-	secretKey := []byte(os.Getenv("JWT_SECRET"))
+func NewJwt(keyring KeyringInterface, issuer string, audience string) *Jwt {
+	return &Jwt{keyring: keyring, issuer: issuer, audience: audience}
+}
 
+// NOTE - token login ที่ผูกกับแถวใน sessions revoke ได้รายเครื่อง
+func (j *Jwt) GenerateSessionJWT(email string, sessionID string) (string, error) {
 	claims :=JWTClaims{
 		Email: email,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(JWTTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return j.sign(claims)
+}
+
+func (j *Jwt) ParseJWT(tokenString string) (string, error) {
+	claims, err := j.ParseJWTClaims(tokenString)
+
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Email,nil
+}
+
+func (j *Jwt) ParseJWTClaims(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parse(tokenString)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE - MFA challenge token ห้ามใช้แทน token login
+	if claims.Purpose != "" {
+		return nil, errors.New("Invalid token purpose")
+	}
+
+	return claims, nil
+}
+
+// NOTE - token อายุสั้นไว้ยืนยัน 2FA หลังจากเช็ค password ผ่านแล้ว
+func (j *Jwt) GenerateMFAToken(email string) (string, error) {
+	claims :=JWTClaims{
+		Email: email,
+		Purpose: mfaPurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return j.sign(claims)
+}
+
+func (j *Jwt) ParseMFAToken(tokenString string) (string, error) {
+	claims, err := j.parse(tokenString)
+
+	if err != nil {
+		return "", err
+	}
+
+	if claims.Purpose != mfaPurpose {
+		return "", errors.New("Invalid token purpose")
+	}
+
+	return claims.Email, nil
+}
+
+// NOTE - link ใน email เซ็นไว้กันปลอม jti (linkID) ใช้เช็คว่าใช้ไปแล้วหรือยัง
+func (j *Jwt) GenerateMagicLinkToken(email string, linkID string, fingerprintHash string) (string, error) {
+	claims := JWTClaims{
+		Email:       email,
+		Purpose:     magicLinkPurpose,
+		Fingerprint: fingerprintHash,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        linkID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(MagicLinkTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secretKey)
+	return j.sign(claims)
+}
+
+func (j *Jwt) ParseMagicLinkToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parse(tokenString)
 
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != magicLinkPurpose || claims.ID == "" {
+		return nil, errors.New("Invalid token purpose")
+	}
+
+	return claims, nil
+}
+
+// NOTE - ใส่ kid ใน header ให้ฝั่ง verify เลือก public key จาก JWKS ได้ถูกตัว
+func (j *Jwt) sign(claims JWTClaims) (string, error) {
+	kid, method, key := j.keyring.SigningKey()
+
+	claims.Issuer = j.issuer
+	claims.Audience = jwt.ClaimStrings{j.audience}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
 }
 
-func (c *JWTClaims) ParseJWT(tokenString string) (string, error) {
+func (j *Jwt) parse(tokenString string) (*JWTClaims, error) {
 	// NOTE - นำ token มาเช็คว่าเป็นอันเดียวกันไหม
 	token, err := jwt.ParseWithClaims(tokenString,&JWTClaims{}, func(token *jwt.Token)  (interface{},error){
-		return []byte(os.Getenv("JWT_SECRET")),nil
-	})
+		kid, _ := token.Header["kid"].(string)
 
-	if err !=nil || !token.Valid {
-		return "" , err
+		method, key, err := j.keyring.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTE - กัน algorithm confusion เช่นเอา public key ไปใช้เป็น HMAC secret
+		if token.Method.Alg() != method.Alg() {
+			return nil, errors.New("Unexpected signing method")
+		}
+
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(j.issuer),
+		jwt.WithAudience(j.audience),
+		jwt.WithExpirationRequired(),
+	)
+
+	if err !=nil {
+		return nil , err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("Invalid token")
 	}
 
 	// NOTE - ดึงข้อมูลจาก claim
 	claims, ok := token.Claims.(*JWTClaims); 
 
 	if !ok {
-		return "",errors.New("Invalid token claims")
+		return nil,errors.New("Invalid token claims")
 	}
 
-	return claims.Email,nil
+	return claims,nil
 
 }
\ No newline at end of file
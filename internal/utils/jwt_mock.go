@@ -12,13 +12,44 @@ func NewJwtMock() *JwtMock {
 	return &JwtMock{}
 }
 
-func (m *JwtMock) GenerateJWT(email string) (string, error) {
-	args := m.Called(email)
-	return args.String(0),args.Error(1)
+func (m *JwtMock) GenerateSessionJWT(email string, sessionID string) (string, error) {
+	args := m.Called(email, sessionID)
+	return args.String(0), args.Error(1)
 }
 
-
 func (m *JwtMock) ParseJWT(tokenString string) (string, error) {
 	args := m.Called(tokenString)
 	return args.String(0),args.Error(1)
 }
+
+
+func (m *JwtMock) ParseJWTClaims(tokenString string) (*JWTClaims, error) {
+	args := m.Called(tokenString)
+	if claims, ok := args.Get(0).(*JWTClaims); ok {
+		return claims, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *JwtMock) GenerateMFAToken(email string) (string, error) {
+	args := m.Called(email)
+	return args.String(0), args.Error(1)
+}
+
+func (m *JwtMock) ParseMFAToken(tokenString string) (string, error) {
+	args := m.Called(tokenString)
+	return args.String(0), args.Error(1)
+}
+
+func (m *JwtMock) GenerateMagicLinkToken(email string, linkID string, fingerprintHash string) (string, error) {
+	args := m.Called(email, linkID, fingerprintHash)
+	return args.String(0), args.Error(1)
+}
+
+func (m *JwtMock) ParseMagicLinkToken(tokenString string) (*JWTClaims, error) {
+	args := m.Called(tokenString)
+	if claims, ok := args.Get(0).(*JWTClaims); ok {
+		return claims, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
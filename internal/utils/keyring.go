@@ -0,0 +1,322 @@
+package utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+type KeyringInterface interface {
+	SigningKey() (string, jwt.SigningMethod, crypto.Signer)
+	VerificationKey(kid string) (jwt.SigningMethod, crypto.PublicKey, error)
+	Rotate() error
+	JWKS() JWKSet
+}
+
+// NOTE - public key ในรูปแบบ JWK (RFC 7517) ให้ service อื่นเอาไป verify token
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type keyringKey struct {
+	kid       string
+	signer    crypto.Signer
+	createdAt time.Time
+	retiredAt *time.Time
+}
+
+type Keyring struct {
+	mu        sync.RWMutex
+	alg       string
+	method    jwt.SigningMethod
+	current   *keyringKey
+	next      *keyringKey
+	keys      map[string]*keyringKey
+	retention time.Duration
+}
+
+// NOTE - privateKeyPEM ใส่ได้หลาย key ต่อกัน (PEM หลาย block) key แรกใช้เซ็น ที่เหลือใช้ verify และ publish ใน JWKS อย่างเดียว
+// ทุก replica โหลดชุดเดียวกันเลยได้ kid ตรงกัน rotate โดยเพิ่ม key ใหม่ไว้ท้ายก่อน แล้วค่อยย้ายขึ้นมาเป็น key แรก
+// ว่างได้ตอน dev จะ generate key ใหม่ให้ (token เก่าจะใช้ไม่ได้หลัง restart)
+// retention คือเวลาที่ key เก่ายังใช้ verify ได้หลังถูก Rotate ออก ควร >= อายุ token
+func NewKeyring(alg string, privateKeyPEM []byte, retention time.Duration) (*Keyring, error) {
+	var method jwt.SigningMethod
+	switch alg {
+	case AlgRS256:
+		method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("Unsupported JWT algorithm: %s", alg)
+	}
+
+	k := &Keyring{
+		alg:       alg,
+		method:    method,
+		keys:      map[string]*keyringKey{},
+		retention: retention,
+	}
+
+	var signers []crypto.Signer
+	if len(bytes.TrimSpace(privateKeyPEM)) > 0 {
+		var err error
+		signers, err = parsePrivateKeys(alg, privateKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		signer, err := generateKey(alg)
+		if err != nil {
+			return nil, err
+		}
+		signers = []crypto.Signer{signer}
+	}
+
+	for _, signer := range signers {
+		kid, err := keyID(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := k.keys[kid]; ok {
+			return nil, fmt.Errorf("Duplicate signing key: %s", kid)
+		}
+
+		key := &keyringKey{kid: kid, signer: signer, createdAt: time.Now()}
+		k.keys[kid] = key
+		if k.current == nil {
+			k.current = key
+		}
+	}
+
+	return k, nil
+}
+
+func generateKey(alg string) (crypto.Signer, error) {
+	if alg == AlgEdDSA {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	}
+	return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+}
+
+func parsePrivateKeys(alg string, data []byte) ([]crypto.Signer, error) {
+	var signers []crypto.Signer
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		signer, err := parsePrivateKey(alg, block)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+
+	if len(signers) == 0 || len(bytes.TrimSpace(data)) > 0 {
+		return nil, errors.New("Invalid private key PEM")
+	}
+
+	return signers, nil
+}
+
+func parsePrivateKey(alg string, block *pem.Block) (crypto.Signer, error) {
+	var key interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid private key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if alg == AlgRS256 {
+			return key, nil
+		}
+	case ed25519.PrivateKey:
+		if alg == AlgEdDSA {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Private key does not match algorithm %s", alg)
+}
+
+// NOTE - kid คำนวณจาก public key เลยได้ค่าเดิมทุกครั้งที่โหลด key เดิม
+func keyID(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode public key: %w", err)
+	}
+
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
+
+// NOTE - key ที่เพิ่มเข้ามาจะรอเป็น next (publish ใน JWKS แต่ยังไม่ใช้เซ็น) ส่วน next เดิมขึ้นมาเป็น current
+// verifier ที่ cache JWKS ไว้จะรู้จัก key ก่อนที่มันจะถูกใช้เซ็นจริง
+func (k *Keyring) add(signer crypto.Signer) error {
+	kid, err := keyID(signer.Public())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	key := &keyringKey{kid: kid, signer: signer, createdAt: now}
+	k.keys[kid] = key
+
+	if k.next != nil {
+		k.current.retiredAt = &now
+		k.current = k.next
+	}
+	k.next = key
+
+	// NOTE - ลบ key ที่เลยช่วง retention ไปแล้ว token ที่เซ็นด้วย key นั้นหมดอายุหมดแล้ว
+	for id, old := range k.keys {
+		if old.retiredAt != nil && now.Sub(*old.retiredAt) > k.retention {
+			delete(k.keys, id)
+		}
+	}
+
+	return nil
+}
+
+func (k *Keyring) SigningKey() (string, jwt.SigningMethod, crypto.Signer) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.current.kid, k.method, k.current.signer
+}
+
+func (k *Keyring) VerificationKey(kid string) (jwt.SigningMethod, crypto.PublicKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	if !ok || (key.retiredAt != nil && time.Since(*key.retiredAt) > k.retention) {
+		return nil, nil, errors.New("Unknown signing key")
+	}
+
+	return k.method, key.signer.Public(), nil
+}
+
+// NOTE - key ที่ generate อยู่ใน memory ของ process นี้เท่านั้น replica อื่นไม่รู้จักและหายไปตอน restart
+// ใช้ได้แค่ตอนไม่ได้ตั้ง key ไว้ใน config (instance เดียว) ที่เหลือให้ rotate ผ่าน JWT_PRIVATE_KEY
+func (k *Keyring) Rotate() error {
+	signer, err := generateKey(k.alg)
+	if err != nil {
+		return fmt.Errorf("Failed to generate signing key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.add(signer)
+}
+
+// NOTE - rotate key ตาม interval จนกว่าจะเรียก stop report ถูกเรียกทุกรอบ (err เป็น nil ถ้า rotate สำเร็จ)
+func (k *Keyring) StartRotation(interval time.Duration, report func(error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := k.Rotate(); report != nil {
+					report(err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (k *Keyring) JWKS() JWKSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]*keyringKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		if key.retiredAt != nil && time.Since(*key.retiredAt) > k.retention {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	// NOTE - key ที่ใช้เซ็นอยู่ขึ้นก่อน ที่เหลือเรียงใหม่ไปเก่า
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == k.current || keys[j] == k.current {
+			return keys[i] == k.current
+		}
+		if !keys[i].createdAt.Equal(keys[j].createdAt) {
+			return keys[i].createdAt.After(keys[j].createdAt)
+		}
+		return keys[i].kid < keys[j].kid
+	})
+
+	set := JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: k.alg}
+
+		switch public := key.signer.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -0,0 +1,209 @@
+package utils_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ed25519PEM(t *testing.T) []byte {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func kids(set utils.JWKSet) []string {
+	ids := make([]string, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		ids = append(ids, key.Kid)
+	}
+	return ids
+}
+
+func TestNewKeyring(t *testing.T) {
+	t.Run("Replicas with the same keys agree on kid", func(t *testing.T) {
+		keys := append(ed25519PEM(t), ed25519PEM(t)...)
+
+		first, err := utils.NewKeyring(utils.AlgEdDSA, keys, time.Hour)
+		require.NoError(t, err)
+		second, err := utils.NewKeyring(utils.AlgEdDSA, keys, time.Hour)
+		require.NoError(t, err)
+
+		firstKid, _, _ := first.SigningKey()
+		secondKid, _, _ := second.SigningKey()
+		assert.Equal(t, firstKid, secondKid)
+		assert.Equal(t, kids(first.JWKS()), kids(second.JWKS()))
+	})
+
+	t.Run("First key signs and the rest only verify", func(t *testing.T) {
+		current := ed25519PEM(t)
+		previous := ed25519PEM(t)
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, append(current, previous...), time.Hour)
+		require.NoError(t, err)
+		previousOnly, err := utils.NewKeyring(utils.AlgEdDSA, previous, time.Hour)
+		require.NoError(t, err)
+
+		signingKid, _, _ := keyring.SigningKey()
+		previousKid, _, _ := previousOnly.SigningKey()
+
+		set := keyring.JWKS()
+		assert.Len(t, set.Keys, 2)
+		assert.Equal(t, signingKid, set.Keys[0].Kid)
+		assert.NotEqual(t, previousKid, signingKid)
+
+		_, public, err := keyring.VerificationKey(previousKid)
+		assert.NoError(t, err)
+		_, _, previousSigner := previousOnly.SigningKey()
+		assert.Equal(t, previousSigner.Public(), public)
+	})
+
+	t.Run("Token from an old replica still verifies after the key moves down", func(t *testing.T) {
+		oldKey := ed25519PEM(t)
+		before, err := utils.NewKeyring(utils.AlgEdDSA, oldKey, time.Hour)
+		require.NoError(t, err)
+		after, err := utils.NewKeyring(utils.AlgEdDSA, append(ed25519PEM(t), oldKey...), time.Hour)
+		require.NoError(t, err)
+
+		token, err := utils.NewJwt(before, "belugatasks", "belugatasks-api").GenerateSessionJWT("test@gmail.com", "")
+		require.NoError(t, err)
+
+		email, err := utils.NewJwt(after, "belugatasks", "belugatasks-api").ParseJWT(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "test@gmail.com", email)
+	})
+
+	t.Run("RSA PKCS1 key", func(t *testing.T) {
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+
+		keyring, err := utils.NewKeyring(utils.AlgRS256, data, time.Hour)
+
+		assert.NoError(t, err)
+		_, _, signer := keyring.SigningKey()
+		assert.Equal(t, &private.PublicKey, signer.Public())
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		key := ed25519PEM(t)
+		tests := []struct {
+			name string
+			alg  string
+			data []byte
+			err  string
+		}{
+			{name: "Unsupported algorithm", alg: "HS256", err: "Unsupported JWT algorithm: HS256"},
+			{name: "Not PEM", alg: utils.AlgEdDSA, data: []byte("not a key"), err: "Invalid private key PEM"},
+			{name: "Trailing garbage", alg: utils.AlgEdDSA, data: append(ed25519PEM(t), []byte("garbage")...), err: "Invalid private key PEM"},
+			{name: "Wrong algorithm", alg: utils.AlgRS256, data: key, err: "Private key does not match algorithm RS256"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := utils.NewKeyring(tt.alg, tt.data, time.Hour)
+				assert.EqualError(t, err, tt.err)
+			})
+		}
+
+		_, err := utils.NewKeyring(utils.AlgEdDSA, append(key, key...), time.Hour)
+		assert.ErrorContains(t, err, "Duplicate signing key")
+	})
+}
+
+func TestKeyringRotate(t *testing.T) {
+	t.Run("New key is published before it signs", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Hour)
+		require.NoError(t, err)
+		firstKid, _, _ := keyring.SigningKey()
+
+		require.NoError(t, keyring.Rotate())
+		kid, _, _ := keyring.SigningKey()
+		set := keyring.JWKS()
+
+		assert.Equal(t, firstKid, kid)
+		assert.Len(t, set.Keys, 2)
+		assert.Equal(t, firstKid, set.Keys[0].Kid)
+		nextKid := set.Keys[1].Kid
+
+		require.NoError(t, keyring.Rotate())
+		kid, _, _ = keyring.SigningKey()
+
+		assert.Equal(t, nextKid, kid)
+		assert.Len(t, keyring.JWKS().Keys, 3)
+		_, _, err = keyring.VerificationKey(firstKid)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Retired key is dropped after retention", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Millisecond)
+		require.NoError(t, err)
+		firstKid, _, _ := keyring.SigningKey()
+
+		require.NoError(t, keyring.Rotate())
+		require.NoError(t, keyring.Rotate())
+		time.Sleep(5 * time.Millisecond)
+
+		_, _, err = keyring.VerificationKey(firstKid)
+		assert.EqualError(t, err, "Unknown signing key")
+		assert.NotContains(t, kids(keyring.JWKS()), firstKid)
+		assert.Len(t, keyring.JWKS().Keys, 2)
+	})
+
+	t.Run("Configured keys are never dropped before rotation", func(t *testing.T) {
+		keyring, err := utils.NewKeyring(utils.AlgEdDSA, append(ed25519PEM(t), ed25519PEM(t)...), time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		for _, kid := range kids(keyring.JWKS()) {
+			_, _, err := keyring.VerificationKey(kid)
+			assert.NoError(t, err)
+		}
+		assert.Len(t, keyring.JWKS().Keys, 2)
+	})
+}
+
+func TestKeyringVerificationKey(t *testing.T) {
+	keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Hour)
+	require.NoError(t, err)
+	kid, _, signer := keyring.SigningKey()
+
+	t.Run("Known kid", func(t *testing.T) {
+		method, public, err := keyring.VerificationKey(kid)
+
+		assert.NoError(t, err)
+		assert.Equal(t, utils.AlgEdDSA, method.Alg())
+		assert.Equal(t, signer.Public(), public)
+	})
+
+	t.Run("Unknown kid", func(t *testing.T) {
+		_, _, err := keyring.VerificationKey("unknown")
+
+		assert.EqualError(t, err, "Unknown signing key")
+	})
+}
+
+func TestKeyringStartRotation(t *testing.T) {
+	keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, time.Hour)
+	require.NoError(t, err)
+
+	rotated := make(chan error, 10)
+	stop := keyring.StartRotation(time.Millisecond, func(err error) { rotated <- err })
+
+	assert.NoError(t, <-rotated)
+	assert.NoError(t, <-rotated)
+	stop()
+	stop()
+
+	assert.GreaterOrEqual(t, len(keyring.JWKS().Keys), 3)
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LifecycleInterface interface {
+	Ready() bool
+	CheckWorkers() error
+	FailedWorkers() []string
+}
+
+type lifecycleHook struct {
+	name string
+	stop func() error
+}
+
+type workerStatus struct {
+	interval time.Duration
+	lastRun  time.Time
+	err      error
+}
+
+// NOTE - คุมการ start/stop ของ server ทั้งตัว ปิดทีละขั้นตอนตอน deploy ไม่ให้ request ที่ค้างอยู่ถูกตัด
+type Lifecycle struct {
+	ready   atomic.Bool
+	mu      sync.Mutex
+	hooks   []lifecycleHook
+	workers map[string]*workerStatus
+	timeout time.Duration
+	delay   time.Duration
+}
+
+// NOTE - delay คือเวลาที่ยังรับ request ต่อหลัง readiness เป็น false ให้ load balancer เห็นก่อนว่าไม่ควรส่งมาแล้ว
+// timeout คือเวลาสูงสุดที่รอ request ที่ค้างอยู่ให้เสร็จ
+func NewLifecycle(timeout time.Duration, delay time.Duration) *Lifecycle {
+	return &Lifecycle{timeout: timeout, delay: delay, workers: map[string]*workerStatus{}}
+}
+
+func (l *Lifecycle) Ready() bool {
+	return l.ready.Load()
+}
+
+// NOTE - ลงทะเบียน background worker ที่ทำงานทุก interval คืน func ให้ worker รายงานผลหลังทำงานแต่ละรอบ
+func (l *Lifecycle) Worker(name string, interval time.Duration) (report func(error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := &workerStatus{interval: interval, lastRun: time.Now()}
+	l.workers[name] = status
+
+	return func(err error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		status.lastRun = time.Now()
+		status.err = err
+	}
+}
+
+// NOTE - worker ที่เงียบไปเกิน 2 รอบ (goroutine ค้าง/ตาย) ถือว่าไม่ healthy
+// รอบที่พังแต่ยังรายงานตามเวลาไม่นับ พังครั้งเดียว (DB กระตุก) ไม่ควรถูกถอดออกจาก load balancer
+func (l *Lifecycle) CheckWorkers() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, name := range l.workerNames() {
+		status := l.workers[name]
+		if since := time.Since(status.lastRun); since > 2*status.interval {
+			errs = append(errs, fmt.Errorf("%s has not run for %s", name, since.Round(time.Second)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NOTE - ชื่อ worker ที่รอบล่าสุดพัง ใช้แสดงเป็นข้อมูลเฉยๆ รายละเอียด error worker log ไว้เองแล้ว
+func (l *Lifecycle) FailedWorkers() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var failed []string
+	for _, name := range l.workerNames() {
+		if l.workers[name].err != nil {
+			failed = append(failed, name)
+		}
+	}
+	return failed
+}
+
+func (l *Lifecycle) workerNames() []string {
+	names := make([]string, 0, len(l.workers))
+	for name := range l.workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NOTE - hook ถูกเรียกย้อนลำดับที่ลงทะเบียน ของที่สร้างก่อน (เช่น DB) จะถูกปิดทีหลังสุด
+// แต่ละ hook มีเวลาไม่เกิน timeout ตัวที่ค้างจะถูกข้ามไป ไม่ให้ตัวเดียวทำให้ process ปิดไม่ลง
+func (l *Lifecycle) OnStop(name string, stop func() error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, lifecycleHook{name: name, stop: stop})
+}
+
+// NOTE - listen จนกว่าจะได้ SIGINT/SIGTERM หรือ listen ไม่สำเร็จ แล้วค่อยปิดทุกอย่าง
+func (l *Lifecycle) Run(app *fiber.App, addr string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		l.ready.Store(true)
+		return nil
+	})
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(addr)
+	}()
+
+	var errs []error
+	select {
+	case err := <-listenErr:
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed to start server: %w", err))
+		}
+	case <-ctx.Done():
+		// NOTE - กด Ctrl+C ซ้ำให้ตายทันทีแทนที่จะรอ drain
+		cancel()
+		slog.Info("Shutting down server...")
+		l.ready.Store(false)
+		time.Sleep(l.delay)
+
+		if err := app.ShutdownWithTimeout(l.timeout); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to shut down server: %w", err))
+		}
+		if err := <-listenErr; err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	l.ready.Store(false)
+	errs = append(errs, l.stop()...)
+	return errors.Join(errs...)
+}
+
+func (l *Lifecycle) stop() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for i := len(l.hooks) - 1; i >= 0; i-- {
+		if err := l.stopHook(l.hooks[i]); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to stop %s: %w", l.hooks[i].name, err))
+		}
+	}
+	l.hooks = nil
+	return errs
+}
+
+func (l *Lifecycle) stopHook(hook lifecycleHook) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.stop()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(l.timeout):
+		return fmt.Errorf("timed out after %s", l.timeout)
+	}
+}
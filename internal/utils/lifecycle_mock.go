@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type LifecycleMock struct {
+	mock.Mock
+}
+
+func NewLifecycleMock() *LifecycleMock {
+	return &LifecycleMock{}
+}
+
+func (m *LifecycleMock) Ready() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *LifecycleMock) CheckWorkers() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *LifecycleMock) FailedWorkers() []string {
+	args := m.Called()
+	if failed, ok := args.Get(0).([]string); ok {
+		return failed
+	}
+	return nil
+}
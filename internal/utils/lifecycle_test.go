@@ -0,0 +1,188 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stopRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *stopRecorder) hook(name string, err error) func() error {
+	return func() error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.order = append(r.order, name)
+		return err
+	}
+}
+
+func (r *stopRecorder) stopped() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.order...)
+}
+
+// NOTE - จอง port ไว้ก่อนให้ app.Listen พัง Run จะออกทันทีโดยไม่ต้องรอ signal
+func runWithListenFailure(t *testing.T, lifecycle *utils.Lifecycle) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	return lifecycle.Run(fiber.New(fiber.Config{DisableStartupMessage: true}), ln.Addr().String())
+}
+
+func TestLifecycleRun(t *testing.T) {
+	t.Run("Listen failure still stops hooks in reverse order", func(t *testing.T) {
+		lifecycle := utils.NewLifecycle(time.Second, 0)
+		recorder := &stopRecorder{}
+		lifecycle.OnStop("database", recorder.hook("database", nil))
+		lifecycle.OnStop("tracing", recorder.hook("tracing", nil))
+		lifecycle.OnStop("rate limit cleanup", recorder.hook("rate limit cleanup", nil))
+
+		err := runWithListenFailure(t, lifecycle)
+
+		assert.ErrorContains(t, err, "Failed to start server")
+		assert.Equal(t, []string{"rate limit cleanup", "tracing", "database"}, recorder.stopped())
+		assert.False(t, lifecycle.Ready())
+	})
+
+	t.Run("Hook errors are joined and later hooks still run", func(t *testing.T) {
+		lifecycle := utils.NewLifecycle(time.Second, 0)
+		recorder := &stopRecorder{}
+		lifecycle.OnStop("database", recorder.hook("database", errors.New("connection busy")))
+		lifecycle.OnStop("tracing", recorder.hook("tracing", errors.New("exporter down")))
+
+		err := runWithListenFailure(t, lifecycle)
+
+		assert.ErrorContains(t, err, "Failed to stop tracing: exporter down")
+		assert.ErrorContains(t, err, "Failed to stop database: connection busy")
+		assert.Equal(t, []string{"tracing", "database"}, recorder.stopped())
+	})
+
+	t.Run("Hook that hangs times out", func(t *testing.T) {
+		lifecycle := utils.NewLifecycle(50*time.Millisecond, 0)
+		recorder := &stopRecorder{}
+		block := make(chan struct{})
+		defer close(block)
+		lifecycle.OnStop("database", recorder.hook("database", nil))
+		lifecycle.OnStop("tracing", func() error {
+			<-block
+			return nil
+		})
+
+		start := time.Now()
+		err := runWithListenFailure(t, lifecycle)
+
+		assert.ErrorContains(t, err, "Failed to stop tracing: timed out after 50ms")
+		assert.Equal(t, []string{"database"}, recorder.stopped())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("SIGTERM drains in-flight requests before stopping hooks", func(t *testing.T) {
+		lifecycle := utils.NewLifecycle(5*time.Second, 100*time.Millisecond)
+		recorder := &stopRecorder{}
+		lifecycle.OnStop("database", recorder.hook("database", nil))
+
+		started := make(chan struct{})
+		app := fiber.New(fiber.Config{DisableStartupMessage: true})
+		app.Get("/slow", func(c *fiber.Ctx) error {
+			close(started)
+			time.Sleep(300 * time.Millisecond)
+			recorder.hook("request", nil)()
+			return c.SendString("done")
+		})
+		addr := make(chan string, 1)
+		app.Hooks().OnListen(func(data fiber.ListenData) error {
+			addr <- net.JoinHostPort(data.Host, data.Port)
+			return nil
+		})
+
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- lifecycle.Run(app, "127.0.0.1:0")
+		}()
+		url := fmt.Sprintf("http://%s/slow", <-addr)
+		require.Eventually(t, lifecycle.Ready, time.Second, 10*time.Millisecond)
+
+		body := make(chan string, 1)
+		go func() {
+			res, err := http.Get(url)
+			if err != nil {
+				body <- err.Error()
+				return
+			}
+			defer res.Body.Close()
+			b, _ := io.ReadAll(res.Body)
+			body <- string(b)
+		}()
+		<-started
+
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+		assert.Eventually(t, func() bool { return !lifecycle.Ready() }, time.Second, 5*time.Millisecond)
+
+		select {
+		case err := <-runErr:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after SIGTERM")
+		}
+		assert.Equal(t, "done", <-body)
+		assert.Equal(t, []string{"request", "database"}, recorder.stopped())
+	})
+}
+
+func TestLifecycleWorkers(t *testing.T) {
+	t.Run("Healthy without workers", func(t *testing.T) {
+		assert.NoError(t, utils.NewLifecycle(time.Second, 0).CheckWorkers())
+	})
+
+	t.Run("Failed run stays healthy and is reported", func(t *testing.T) {
+		lifecycle := utils.NewLifecycle(time.Second, 0)
+		report := lifecycle.Worker("rate limit cleanup", time.Minute)
+
+		assert.Empty(t, lifecycle.FailedWorkers())
+
+		report(errors.New("database is down"))
+		assert.NoError(t, lifecycle.CheckWorkers())
+		assert.Equal(t, []string{"rate limit cleanup"}, lifecycle.FailedWorkers())
+
+		report(nil)
+		assert.NoError(t, lifecycle.CheckWorkers())
+		assert.Empty(t, lifecycle.FailedWorkers())
+	})
+
+	t.Run("Worker that stops reporting is unhealthy", func(t *testing.T) {
+		lifecycle := utils.NewLifecycle(time.Second, 0)
+		report := lifecycle.Worker("jwt key rotation", 10*time.Millisecond)
+
+		time.Sleep(30 * time.Millisecond)
+		assert.ErrorContains(t, lifecycle.CheckWorkers(), "jwt key rotation has not run for")
+
+		report(nil)
+		assert.NoError(t, lifecycle.CheckWorkers())
+	})
+
+	t.Run("Report every failed worker sorted by name", func(t *testing.T) {
+		lifecycle := utils.NewLifecycle(time.Second, 0)
+		lifecycle.Worker("rate limit cleanup", time.Minute)(errors.New("b"))
+		lifecycle.Worker("jwt key rotation", time.Minute)(errors.New("a"))
+		lifecycle.Worker("audit export", time.Minute)(nil)
+
+		assert.Equal(t, []string{"jwt key rotation", "rate limit cleanup"}, lifecycle.FailedWorkers())
+	})
+}
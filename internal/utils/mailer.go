@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/smtp"
+)
+
+type MailerInterface interface {
+	Send(to string, subject string, body string) error
+}
+
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string) *SMTPMailer {
+	return &SMTPMailer{host: host, port: port, username: username, password: password, from: from}
+}
+
+func (m *SMTPMailer) Send(to string, subject string, body string) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		m.from, to, subject, body)
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	return smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{to}, []byte(msg))
+}
+
+// NOTE - Mailer สำหรับ local dev เขียน email ออก stdout แทนการส่งจริง
+type LogMailer struct {
+	out io.Writer
+}
+
+func NewLogMailer(out io.Writer) *LogMailer {
+	return &LogMailer{out: out}
+}
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	_, err := fmt.Fprintf(m.out, "📧 To: %s\nSubject: %s\n\n%s\n", to, subject, body)
+	return err
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type MailerMock struct {
+	mock.Mock
+}
+
+func NewMailerMock() *MailerMock {
+	return &MailerMock{}
+}
+
+func (m *MailerMock) Send(to string, subject string, body string) error {
+	args := m.Called(to, subject, body)
+	return args.Error(0)
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Nonce         string
+}
+
+type OIDCProviderInterface interface {
+	AuthCodeURL(state string, nonce string, codeVerifier string) string
+	Exchange(ctx context.Context, code string, codeVerifier string) (*OIDCIdentity, error)
+}
+
+type OIDCProvider struct {
+	config   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NOTE - อ่าน endpoint และ JWKS จาก discovery document ของ issuer (/.well-known/openid-configuration)
+func NewOIDCProvider(ctx context.Context, issuer string, clientID string, clientSecret string, redirectURL string) (*OIDCProvider, error) {
+	provider, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to discover OIDC provider: %w", err)
+	}
+
+	return &OIDCProvider{
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  redirectURL,
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: clientID}),
+	}, nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(state string, nonce string, codeVerifier string) string {
+	return p.config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier))
+}
+
+// NOTE - แลก code เป็น token แล้ว verify ID token (signature, issuer, audience, expiry) ส่วน nonce ให้ผู้เรียกเช็คเอง
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, codeVerifier string) (*OIDCIdentity, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("Provider did not return an ID token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ID token: %w", err)
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("Invalid ID token claims: %w", err)
+	}
+
+	return &OIDCIdentity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Nonce:         idToken.Nonce,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NOTE - token bucket จุได้ Requests token เติมกลับเต็มถังภายใน Per
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+type TokenBucket struct {
+	Tokens     float64
+	RefilledAt time.Time
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // NOTE - เวลาจนกว่าถังจะเต็ม
+	RetryAfter time.Duration // NOTE - เวลาจนกว่าจะได้ token ถัดไป (มีค่าเมื่อไม่ผ่าน)
+}
+
+// NOTE - รูปแบบ "<requests>/<duration>" เช่น "300/1m"
+func ParseRateLimit(value string) (RateLimit, error) {
+	requests, per, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("Invalid rate limit %q, expected <requests>/<duration>", value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("Invalid rate limit requests %q", requests)
+	}
+
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("Invalid rate limit duration %q", per)
+	}
+
+	return RateLimit{Requests: n, Per: d}, nil
+}
+
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%d;w=%d", l.Requests, int(l.Per.Seconds()))
+}
+
+// NOTE - เติม token ตามเวลาที่ผ่านไปแล้วหยิบ 1 ตัว store ต้องเรียกแบบ atomic ต่อ key
+func (l RateLimit) Take(bucket *TokenBucket, now time.Time) RateLimitResult {
+	capacity := float64(l.Requests)
+	rate := capacity / l.Per.Seconds()
+
+	if bucket.RefilledAt.IsZero() {
+		bucket.Tokens = capacity
+	} else if elapsed := now.Sub(bucket.RefilledAt).Seconds(); elapsed > 0 {
+		bucket.Tokens = math.Min(capacity, bucket.Tokens+elapsed*rate)
+	}
+	bucket.RefilledAt = now
+
+	result := RateLimitResult{Limit: l.Requests}
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.Tokens) / rate)
+	}
+
+	result.Remaining = int(bucket.Tokens)
+	result.Reset = secondsToDuration((capacity - bucket.Tokens) / rate)
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
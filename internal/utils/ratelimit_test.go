@@ -0,0 +1,115 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  utils.RateLimit
+		err   string
+	}{
+		{value: "300/1m", want: utils.RateLimit{Requests: 300, Per: time.Minute}},
+		{value: " 20/30s ", want: utils.RateLimit{Requests: 20, Per: 30 * time.Second}},
+		{value: "300", err: `Invalid rate limit "300", expected <requests>/<duration>`},
+		{value: "abc/1m", err: `Invalid rate limit requests "abc"`},
+		{value: "0/1m", err: `Invalid rate limit requests "0"`},
+		{value: "10/forever", err: `Invalid rate limit duration "forever"`},
+		{value: "10/-1m", err: `Invalid rate limit duration "-1m"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := utils.ParseRateLimit(tt.value)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRateLimitString(t *testing.T) {
+	assert.Equal(t, "300;w=60", utils.RateLimit{Requests: 300, Per: time.Minute}.String())
+}
+
+func TestRateLimitTake(t *testing.T) {
+	// NOTE - 10 request ต่อ 10 วินาที เติม 1 token ต่อวินาที
+	limit := utils.RateLimit{Requests: 10, Per: 10 * time.Second}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("New bucket starts full", func(t *testing.T) {
+		bucket := &utils.TokenBucket{}
+
+		result := limit.Take(bucket, start)
+
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 10, result.Limit)
+		assert.Equal(t, 9, result.Remaining)
+		assert.Equal(t, time.Second, result.Reset)
+		assert.Zero(t, result.RetryAfter)
+		assert.Equal(t, start, bucket.RefilledAt)
+	})
+
+	t.Run("Burst up to the limit then deny", func(t *testing.T) {
+		bucket := &utils.TokenBucket{}
+		for i := 0; i < 10; i++ {
+			assert.True(t, limit.Take(bucket, start).Allowed)
+		}
+
+		result := limit.Take(bucket, start)
+
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 10*time.Second, result.Reset)
+	})
+
+	t.Run("Refill by elapsed time", func(t *testing.T) {
+		bucket := &utils.TokenBucket{Tokens: 0, RefilledAt: start}
+
+		result := limit.Take(bucket, start.Add(2500*time.Millisecond))
+
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+		assert.InDelta(t, 1.5, bucket.Tokens, 1e-9)
+		assert.Equal(t, 8500*time.Millisecond, result.Reset)
+	})
+
+	t.Run("Partial token waits for the rest", func(t *testing.T) {
+		bucket := &utils.TokenBucket{Tokens: 0, RefilledAt: start}
+
+		result := limit.Take(bucket, start.Add(250*time.Millisecond))
+
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 750*time.Millisecond, result.RetryAfter)
+		assert.InDelta(t, 0.25, bucket.Tokens, 1e-9)
+	})
+
+	t.Run("Refill never goes above capacity", func(t *testing.T) {
+		bucket := &utils.TokenBucket{Tokens: 5, RefilledAt: start}
+
+		result := limit.Take(bucket, start.Add(time.Hour))
+
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 9, result.Remaining)
+		assert.InDelta(t, 9, bucket.Tokens, 1e-9)
+	})
+
+	t.Run("Clock going backwards does not refill", func(t *testing.T) {
+		bucket := &utils.TokenBucket{Tokens: 0, RefilledAt: start}
+
+		result := limit.Take(bucket, start.Add(-time.Minute))
+
+		assert.False(t, result.Allowed)
+		assert.InDelta(t, 0, bucket.Tokens, 1e-9)
+	})
+}
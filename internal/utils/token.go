@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// NOTE - สร้าง token แบบสุ่มสำหรับส่งให้ผู้ใช้ (เช่น link reset password)
+func GenerateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// NOTE - เก็บแค่ hash ของ token ลง DB ไม่เก็บ token จริง
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NOTE - recovery code รูปแบบ XXXXXXXX-XXXXXXXX (base32) ให้ผู้ใช้จดเก็บไว้
+func GenerateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	return code[:8] + "-" + code[8:], nil
+}
+
+// NOTE - ตัด - กับช่องว่างออกและทำเป็นตัวใหญ่ ก่อนเอาไป hash
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
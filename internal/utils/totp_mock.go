@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type TOTPMock struct {
+	mock.Mock
+}
+
+func NewTOTPMock() *TOTPMock {
+	return &TOTPMock{}
+}
+
+func (m *TOTPMock) GenerateSecret(email string) (*TOTPKey, error) {
+	args := m.Called(email)
+	if key, ok := args.Get(0).(*TOTPKey); ok {
+		return key, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *TOTPMock) Validate(code string, secret string) (int64, bool) {
+	args := m.Called(code, secret)
+	return args.Get(0).(int64), args.Bool(1)
+}
@@ -0,0 +1,47 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPValidate(t *testing.T) {
+	key, err := utils.NewTOTP().GenerateSecret("test@gmail.com")
+	require.NoError(t, err)
+	now := time.Now()
+
+	t.Run("Current code returns its time step", func(t *testing.T) {
+		code, err := totp.GenerateCode(key.Secret, now)
+		require.NoError(t, err)
+
+		step, ok := utils.NewTOTP().Validate(code, key.Secret)
+
+		assert.True(t, ok)
+		// NOTE - ข้ามรอบ 30 วินาทีระหว่าง test ได้ เลยยอมให้ต่างกัน 1
+		assert.InDelta(t, now.Unix()/30, step, 1)
+	})
+
+	t.Run("Previous code is accepted with an earlier step", func(t *testing.T) {
+		code, err := totp.GenerateCode(key.Secret, now.Add(-30*time.Second))
+		require.NoError(t, err)
+
+		step, ok := utils.NewTOTP().Validate(code, key.Secret)
+
+		assert.True(t, ok)
+		assert.InDelta(t, now.Unix()/30-1, step, 1)
+	})
+
+	t.Run("Old code is rejected", func(t *testing.T) {
+		code, err := totp.GenerateCode(key.Secret, now.Add(-5*time.Minute))
+		require.NoError(t, err)
+
+		_, ok := utils.NewTOTP().Validate(code, key.Secret)
+
+		assert.False(t, ok)
+	})
+}
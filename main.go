@@ -1,72 +1,377 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Beluga-Whale/management-api/config"
+	"github.com/Beluga-Whale/management-api/internal/cli"
 	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/logging"
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
 	"github.com/Beluga-Whale/management-api/internal/repositories"
 	"github.com/Beluga-Whale/management-api/internal/routes"
 	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/tracing"
 	"github.com/Beluga-Whale/management-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// NOTE - ใส่ตอน build ด้วย -ldflags "-X main.gitSHA=<sha> -X main.buildTime=<time>"
+var (
+	gitSHA    string
+	buildTime string
 )
 
 func main() {
+	// NOTE - ไม่มี argument หรือ serve คือรัน API ตามเดิม Dockerfile ใช้ CMD ["./server"] ได้เหมือนเดิม
+	if len(os.Args) < 2 || os.Args[1] == "serve" {
+		serve()
+		return
+	}
+
+	if err := runCLI(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func serve() {
 	// Load environment variables from .env file
 	// err := godotenv.Load()
 	// if err != nil {
 	// 	log.Fatal("Error loading .env file")
 	// }
 
-	config.LoadEnv()
+	// NOTE - โหลด config ครั้งเดียว ค่าไหนผิดหรือขาดให้ start ไม่ขึ้นเลย
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+
+	// NOTE - JSON log ลง stdout ทุก package ใช้ slog.Default() ตัวนี้ รวมถึง log.Printf ของ library
+	slog.SetDefault(logging.New(os.Stdout, cfg.Log.Level))
+
+	// NOTE - ปิดทุกอย่างตามลำดับตอนได้ SIGINT/SIGTERM
+	lifecycle := utils.NewLifecycle(cfg.Shutdown.Timeout, cfg.Shutdown.Delay)
+
+	// NOTE - ลงทะเบียนก่อน DB จะได้ปิดทีหลังสุด flush span ของ request สุดท้ายออกไปก่อน
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	lifecycle.OnStop("tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return shutdownTracing(ctx)
+	})
 
 	// NOTE - Connect DB
-	config.ConnectDB()
+	config.ConnectDB(cfg.Database)
+	lifecycle.OnStop("database", config.CloseDB)
+	if err := config.DB.Use(tracing.NewGormPlugin()); err != nil {
+		fatal("Failed to register GORM tracing", err)
+	}
+
+	// NOTE - Prometheus metrics ของ HTTP, DB และ business event
+	appMetrics := metrics.New()
+	if err := config.DB.Use(metrics.NewGormPlugin(appMetrics)); err != nil {
+		fatal("Failed to register GORM metrics", err)
+	}
+	sqlDB, err := config.DB.DB()
+	if err != nil {
+		fatal("Failed to get DB pool", err)
+	}
+	appMetrics.RegisterDBStats(sqlDB, cfg.Database.Label())
+
+	// NOTE - cookie กับ CORS ต่างกันตาม APP_ENV
+	cookies := cfg.HTTP.Cookie
 
 	// NOTE - Fiber
 	app := fiber.New()
 
+	// NOTE - ต้องอยู่ก่อน middleware ตัวอื่น จะได้จับเวลาและ status ของทุก request
+	app.Use(middleware.NewRequestIDMiddleware())
+	app.Use(middleware.NewTracingMiddleware())
+	app.Use(middleware.NewAccessLogMiddleware(slog.Default()))
+	app.Use(middleware.NewMetricsMiddleware(appMetrics))
+	app.Use(middleware.NewTimeoutMiddleware(cfg.HTTP.RequestTimeout))
+
 	// NOTE - Use cors
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:3000, http://localhost:3001, https://belugatasks.dev",
-		AllowMethods: "GET,POST,PUT,PATCH,DELETE",
-		AllowHeaders: "Content-Type,Authorization",
-		AllowCredentials: true,
-	}))
+	app.Use(cors.New(cfg.HTTP.CORS.Fiber()))
 
 	// NOTE - Create Repository
 	userRepo := repositories.NewUserRepository(config.DB)
 	taskRepo := repositories.NewTaskRepository(config.DB)
+	passwordResetRepo := repositories.NewPasswordResetRepository(config.DB)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(config.DB)
+	webAuthnRepo := repositories.NewWebAuthnRepository(config.DB)
+	accessTokenRepo := repositories.NewAccessTokenRepository(config.DB)
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(config.DB)
+	oauthRepo := repositories.NewOAuthRepository(config.DB)
+	sessionRepo := repositories.NewSessionRepository(config.DB)
+	magicLinkRepo := repositories.NewMagicLinkRepository(config.DB)
+	auditLogRepo := repositories.NewAuditLogRepository(config.DB)
+	appMetrics.RegisterOverdueTasks(taskRepo.CountOverdueByPriority)
 
 	hashUtil := utils.NewHash()
-	jwtUtil := utils.NewJwt()
+	keyring, err := newKeyring(cfg.JWT)
+	if err != nil {
+		fatal("Failed to configure JWT keyring", err)
+	}
+	// NOTE - rotate เองได้แค่ตอนใช้ key ที่ generate (instance เดียว) key จาก config ต้อง rotate ผ่าน JWT_PRIVATE_KEY
+	if cfg.JWT.RotationInterval > 0 {
+		reportRotation := lifecycle.Worker("jwt key rotation", cfg.JWT.RotationInterval)
+		stopRotation := keyring.StartRotation(cfg.JWT.RotationInterval, func(err error) {
+			if err != nil {
+				slog.Error("Failed to rotate JWT signing key", "error", err)
+			}
+			reportRotation(err)
+		})
+		lifecycle.OnStop("jwt key rotation", func() error {
+			stopRotation()
+			return nil
+		})
+	}
+	jwtUtil := utils.NewJwt(keyring,cfg.JWT.Issuer,cfg.JWT.Audience)
+	totpUtil := utils.NewTOTP()
+	mailer := newMailer(cfg.Mail)
+	webAuthn, err := newWebAuthn(cfg.WebAuthn)
+	if err != nil {
+		fatal("Failed to configure WebAuthn", err)
+	}
 	// NOTE - Create Service
-	userService := services.NewUserService(userRepo,hashUtil,jwtUtil)
-	taskService := services.NewTaskService(taskRepo,userRepo,jwtUtil)
+	auditService := services.NewAuditService(userRepo,auditLogRepo,appMetrics)
+	loginThrottle := services.NewLoginThrottle(loginAttemptRepo)
+	sessionService := services.NewSessionService(userRepo,sessionRepo,jwtUtil,auditService)
+	userService := services.NewUserService(userRepo,hashUtil,jwtUtil,loginThrottle,sessionService,auditService)
+	taskService := services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWork(config.DB),appMetrics)
+	passwordService := services.NewPasswordService(userRepo,passwordResetRepo,accessTokenRepo,hashUtil,sessionService,auditService,mailer,cfg.ResetPasswordURL)
+	twoFactorService := services.NewTwoFactorService(userRepo,recoveryCodeRepo,totpUtil,jwtUtil,sessionService,loginThrottle,auditService)
+	webAuthnService := services.NewWebAuthnService(userRepo,webAuthnRepo,webAuthn,sessionService)
+	accessTokenService := services.NewAccessTokenService(userRepo,accessTokenRepo,auditService)
+	magicLinkService := services.NewMagicLinkService(userRepo,magicLinkRepo,jwtUtil,sessionService,mailer,cfg.MagicLink.CallbackURL)
+	healthService := services.NewHealthService(repositories.NewHealthRepository(config.DB),lifecycle,utils.NewBuildInfo(gitSHA,buildTime))
+	oidcService := services.NewOIDCService(userRepo,oauthRepo,hashUtil,jwtUtil,sessionService,newOIDCProviders(cfg.OIDC))
 
 	// NOTE - Handler
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService,cookies)
 	taskHandler := handlers.NewTaskHandler(taskService)
+	passwordHandler := handlers.NewPasswordHandler(passwordService,cookies)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService,cookies)
+	webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService,cookies)
+	accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService)
+	jwksHandler := handlers.NewJWKSHandler(keyring)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	adminHandler := handlers.NewAdminHandler(auditService,userService)
+	csrfHandler := handlers.NewCSRFHandler(cookies)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	magicLinkHandler := handlers.NewMagicLinkHandler(magicLinkService,cfg.MagicLink.SuccessRedirectURL,cookies)
+	oidcHandler := handlers.NewOIDCHandler(oidcService,cfg.OIDC.SuccessRedirectURL,cookies)
+
+	// NOTE - Middleware
+	authMiddleware := middleware.NewAuthMiddleware(userRepo,jwtUtil,accessTokenService,sessionService,cookies)
+
+	authRateLimit, apiRateLimit, stopRateLimitCleanup, err := newRateLimiters(cfg.RateLimit, lifecycle.Worker("rate limit cleanup", rateLimitCleanupInterval))
+	if err != nil {
+		fatal("Failed to configure rate limit", err)
+	}
+	lifecycle.OnStop("rate limit cleanup", func() error {
+		stopRateLimitCleanup()
+		return nil
+	})
 
 	// NOTE - Route 
-	routes.SetupRoutes(app,userHandler,taskHandler)
+	routes.SetupRoutes(app, routes.Handlers{
+		User:        userHandler,
+		Task:        taskHandler,
+		Password:    passwordHandler,
+		TwoFactor:   twoFactorHandler,
+		WebAuthn:    webAuthnHandler,
+		AccessToken: accessTokenHandler,
+		JWKS:        jwksHandler,
+		OIDC:        oidcHandler,
+		MagicLink:   magicLinkHandler,
+		Session:     sessionHandler,
+		Admin:       adminHandler,
+		CSRF:        csrfHandler,
+		Health:      healthHandler,
+		Metrics:     appMetrics.Handler(cfg.Metrics.Token),
+	}, routes.Middlewares{
+		Auth:          authMiddleware,
+		AuthRateLimit: authRateLimit,
+		APIRateLimit:  apiRateLimit,
+		CSRF:          middleware.NewCSRFMiddleware(cookies),
+	})
 
 
-	port := os.Getenv("PORT_API")
+    slog.Info("Server running", "port", cfg.Port)
+    // NOTE -เช็ค error จาก Listen และตอน shutdown
+    if err := lifecycle.Run(app, cfg.Addr()); err != nil {
+        fatal("Server stopped with error", err)
+    }
+    slog.Info("Server stopped")
+
+}
 
-	if port =="" {
-		port =":8080"
+// NOTE - admin CLI (docker exec <container> ./server user create ...) ใช้ config ชุดเดียวกับ serve
+// log ออก stderr ให้ stdout มีแค่ผลลัพธ์ และไม่ migrate เองต้องสั่ง migrate
+func runCLI(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Invalid configuration: %w", err)
 	}
-	 addr := fmt.Sprintf(":%s", port)
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.Level))
 
-    fmt.Printf("Server running on port %s\n", port)
-    // NOTE -เช็ค error จาก Listen
-    if err := app.Listen(addr); err != nil {
-        log.Fatalf("Failed to start server: %v", err)
-    }
+	if err := config.OpenDB(cfg.Database); err != nil {
+		return fmt.Errorf("Fail to connect DB: %w", err)
+	}
+	defer config.CloseDB()
+
+	userRepo := repositories.NewUserRepository(config.DB)
+	taskRepo := repositories.NewTaskRepository(config.DB)
+
+	keyring, err := newKeyring(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("Failed to configure JWT keyring: %w", err)
+	}
+	jwtUtil := utils.NewJwt(keyring,cfg.JWT.Issuer,cfg.JWT.Audience)
+	appMetrics := metrics.New()
+
+	auditService := services.NewAuditService(userRepo,repositories.NewAuditLogRepository(config.DB),appMetrics)
+	sessionService := services.NewSessionService(userRepo,repositories.NewSessionRepository(config.DB),jwtUtil,auditService)
+	loginThrottle := services.NewLoginThrottle(repositories.NewLoginAttemptRepository(config.DB))
+
+	app := &cli.App{
+		Env:     cfg.Env,
+		Users:   services.NewUserService(userRepo,utils.NewHash(),jwtUtil,loginThrottle,sessionService,auditService),
+		Tasks:   services.NewTaskService(taskRepo,userRepo,repositories.NewUnitOfWork(config.DB),appMetrics),
+		Seed:    services.NewSeedService(userRepo,repositories.NewUnitOfWork(config.DB),utils.NewHash()),
+		Migrate: func(ctx context.Context) error { return config.Migrate(config.DB.WithContext(ctx)) },
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return app.Run(ctx, args)
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// NOTE - ถ้าไม่ได้ตั้ง SMTP_HOST จะพิมพ์ email ออก stdout แทน (local dev) หรือเขียนต่อท้ายไฟล์ MAIL_FILE ถ้าตั้งไว้
+func newMailer(cfg config.MailConfig) utils.MailerInterface {
+	if cfg.SMTPHost == "" {
+		if cfg.File != "" {
+			file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+			if err != nil {
+				fatal("Failed to open mail file", err)
+			}
+			return utils.NewLogMailer(file)
+		}
+		return utils.NewLogMailer(os.Stdout)
+	}
+
+	return utils.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+}
+
+// NOTE - RP ID ต้องตรงกับ domain ของหน้าเว็บ ไม่งั้น browser จะไม่ยอมสร้าง passkey
+func newWebAuthn(cfg config.WebAuthnConfig) (*webauthn.WebAuthn, error) {
+	return webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: "BelugaTasks",
+		RPOrigins:     cfg.RPOrigins,
+	})
+}
+
+// NOTE - ถ้าไม่ได้ตั้ง JWT_PRIVATE_KEY จะ generate key ใหม่ทุกครั้งที่ start (user ต้อง login ใหม่) production ไม่ยอมให้ว่าง
+// ทุก replica ต้องใช้ JWT_PRIVATE_KEY ชุดเดียวกัน JWKS กับ token ถึงจะตรงกัน
+func newKeyring(cfg config.JWTConfig) (*utils.Keyring, error) {
+	// NOTE - key เก่าต้องใช้ verify ได้จนกว่า token ที่เซ็นไว้หมดอายุ
+	return utils.NewKeyring(cfg.Algorithm, []byte(cfg.PrivateKey), utils.JWTTTL+time.Minute)
+}
+
+const rateLimitCleanupInterval = 10 * time.Minute
+
+// NOTE - RATE_LIMIT_STORE=postgres ให้ทุก instance ใช้ถังเดียวกัน ค่า default นับใน memory
+func newRateLimiters(cfg config.RateLimitConfig, report func(error)) (fiber.Handler, fiber.Handler, func(), error) {
+	var store repositories.RateLimitRepositoryInterface = repositories.NewRateLimitMemoryRepository()
+	if cfg.Store == "postgres" {
+		store = repositories.NewRateLimitRepository(config.DB)
+	}
+
+	authLimit, err := utils.ParseRateLimit(cfg.Auth)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	apiLimit, err := utils.ParseRateLimit(cfg.API)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// NOTE - ถังที่ว่างนานกว่าช่วง limit ก็เต็มแล้ว ลบทิ้งเป็นระยะกันโตไม่จำกัด
+	idle := authLimit.Per
+	if apiLimit.Per > idle {
+		idle = apiLimit.Per
+	}
+	ticker := time.NewTicker(rateLimitCleanupInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				err := store.DeleteIdle(context.Background(), time.Now().Add(-idle))
+				if err != nil {
+					slog.Error("Failed to clean up rate limit buckets", "error", err)
+				}
+				report(err)
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	stop := func() { close(done) }
+
+	return middleware.NewRateLimiter(store, "auth", authLimit), middleware.NewRateLimiter(store, "api", apiLimit), stop, nil
+}
+
+// NOTE - redirect URL ที่ลงทะเบียนกับ provider คือ <OIDC_REDIRECT_BASE_URL>/<name>/callback
+func newOIDCProviders(cfg config.OIDCConfig) map[string]utils.OIDCProviderInterface {
+	providers := map[string]utils.OIDCProviderInterface{}
+	redirectBaseURL := strings.TrimSuffix(cfg.RedirectBaseURL, "/")
+
+	for _, p := range cfg.Providers {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := utils.NewOIDCProvider(ctx, p.Issuer, p.ClientID, p.ClientSecret, redirectBaseURL+"/"+p.Name+"/callback")
+		cancel()
+
+		// NOTE - provider ตัวไหนต่อไม่ได้ก็ข้ามไป ไม่ให้ทั้ง API start ไม่ขึ้น
+		if err != nil {
+			slog.Error("Failed to configure OIDC provider", "provider", p.Name, "error", err)
+			continue
+		}
+		providers[p.Name] = provider
+	}
 
-}
\ No newline at end of file
+	return providers
+}
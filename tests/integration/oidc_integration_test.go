@@ -0,0 +1,124 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/config"
+	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/services"
+	"github.com/Beluga-Whale/management-api/internal/utils"
+	"github.com/Beluga-Whale/management-api/tests/oidcmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setUpAppOIDC(t *testing.T, provider *oidcmock.Server) *fiber.App {
+	connectTestDB()
+
+	mockProvider, err := utils.NewOIDCProvider(context.Background(), provider.URL, oidcmock.ClientID, oidcmock.ClientSecret, "http://localhost:8080/api/user/oidc/mock/callback")
+	require.NoError(t, err)
+
+	userRepo := repositories.NewUserRepository(config.TestDB)
+	oauthRepo := repositories.NewOAuthRepository(config.TestDB)
+	auditService := services.NewAuditService(userRepo, repositories.NewAuditLogRepository(config.TestDB), metrics.New())
+	sessionService := services.NewSessionService(userRepo, repositories.NewSessionRepository(config.TestDB), testJwt, auditService)
+	oidcService := services.NewOIDCService(userRepo, oauthRepo, utils.NewHash(), testJwt, sessionService, map[string]utils.OIDCProviderInterface{"mock": mockProvider})
+	oidcHandler := handlers.NewOIDCHandler(oidcService, "http://localhost:3000/", testCookies)
+
+	app := fiber.New()
+	app.Get("/api/user/oidc/:provider/login", oidcHandler.Login)
+	app.Get("/api/user/oidc/:provider/callback", oidcHandler.Callback)
+
+	return app
+}
+
+func clearDataBaseOIDC() {
+	config.TestDB.Exec("DELETE FROM o_id_c_login_states")
+	config.TestDB.Exec("DELETE FROM o_auth_identities")
+	clearDataBaseUser()
+}
+
+// NOTE - เดินตาม flow ของ browser: login -> provider -> callback
+func oidcRoundTrip(t *testing.T, app *fiber.App) *http.Response {
+	res, err := app.Test(httptest.NewRequest("GET", "/api/user/oidc/mock/login", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusFound, res.StatusCode)
+
+	var stateCookie string
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "oidc_state" {
+			stateCookie = cookie.Value
+		}
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	providerRes, err := client.Get(res.Header.Get("Location"))
+	require.NoError(t, err)
+	providerRes.Body.Close()
+	require.Equal(t, http.StatusFound, providerRes.StatusCode)
+
+	callback, err := url.Parse(providerRes.Header.Get("Location"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", callback.RequestURI(), nil)
+	req.Header.Set("Cookie", "oidc_state="+stateCookie)
+
+	res, err = app.Test(req)
+	require.NoError(t, err)
+	return res
+}
+
+func TestOIDCLoginIntegration(t *testing.T) {
+	t.Run("Test OIDC login creates and links user", func(t *testing.T) {
+		provider := oidcmock.NewServer()
+		defer provider.Close()
+		provider.Email = fmt.Sprintf("test_oidc_%s@gmail.com", uuid.NewString())
+
+		app := setUpAppOIDC(t, provider)
+
+		res := oidcRoundTrip(t, app)
+
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Equal(t, "http://localhost:3000/", res.Header.Get("Location"))
+		assert.Contains(t, strings.Join(res.Header.Values("Set-Cookie"), "\n"), "jwt=")
+
+		var count int64
+		config.TestDB.Table("o_auth_identities").Where("subject = ?", provider.Subject).Count(&count)
+		assert.Equal(t, int64(1), count)
+
+		// NOTE - login ครั้งที่สองต้องได้ user เดิม ไม่สร้างซ้ำ
+		res = oidcRoundTrip(t, app)
+
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+		config.TestDB.Table("users").Where("email = ?", provider.Email).Count(&count)
+		assert.Equal(t, int64(1), count)
+
+		clearDataBaseOIDC()
+	})
+
+	t.Run("Test OIDC login rejects unverified email", func(t *testing.T) {
+		provider := oidcmock.NewServer()
+		defer provider.Close()
+		provider.EmailVerified = false
+
+		app := setUpAppOIDC(t, provider)
+
+		res := oidcRoundTrip(t, app)
+
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		clearDataBaseOIDC()
+	})
+}
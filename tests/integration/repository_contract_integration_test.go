@@ -0,0 +1,34 @@
+package integration_test
+
+import (
+	"testing"
+
+	"github.com/Beluga-Whale/management-api/config"
+	"github.com/Beluga-Whale/management-api/internal/repositories"
+	"github.com/Beluga-Whale/management-api/internal/repositories/repositorytest"
+)
+
+// NOTE - ตัว GORM ต้องผ่านชุดเดียวกับตัว memory ใน internal/repositories
+func TestTaskRepositoryContract(t *testing.T) {
+	repositorytest.RunTaskRepositoryContract(t, func(t *testing.T) (repositories.TaskRepositoryInterface, repositories.UserRepositoryInterface) {
+		connectTestDB()
+		clearDataBaseTask()
+		return repositories.NewTaskRepository(config.TestDB), repositories.NewUserRepository(config.TestDB)
+	})
+}
+
+func TestUserRepositoryContract(t *testing.T) {
+	repositorytest.RunUserRepositoryContract(t, func(t *testing.T) repositories.UserRepositoryInterface {
+		connectTestDB()
+		clearDataBaseUser()
+		return repositories.NewUserRepository(config.TestDB)
+	})
+}
+
+func TestRateLimitRepositoryContract(t *testing.T) {
+	repositorytest.RunRateLimitRepositoryContract(t, func(t *testing.T) repositories.RateLimitRepositoryInterface {
+		connectTestDB()
+		config.TestDB.Exec("DELETE FROM rate_limit_buckets")
+		return repositories.NewRateLimitRepository(config.TestDB)
+	})
+}
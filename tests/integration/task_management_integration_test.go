@@ -2,14 +2,20 @@ package integration_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/Beluga-Whale/management-api/config"
 	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/Beluga-Whale/management-api/internal/repositories"
 	"github.com/Beluga-Whale/management-api/internal/services"
 	"github.com/Beluga-Whale/management-api/internal/utils"
@@ -18,37 +24,79 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var testCookies = utils.CookieConfig{Name: "jwt", MaxAge: 72 * time.Hour, SameSite: "Lax"}
+
+// NOTE - ใช้ keyring เดียวกันทั้ง package ไม่งั้น token ที่ createJWT สร้างจะ verify ไม่ผ่าน
+var testJwt = newTestJwt()
+
+func newTestJwt() *utils.Jwt {
+	keyring, err := utils.NewKeyring(utils.AlgEdDSA, nil, utils.JWTTTL)
+	if err != nil {
+		log.Fatalf("Failed to create test keyring: %v", err)
+	}
+	return utils.NewJwt(keyring, "belugatasks", "belugatasks-api")
+}
+
+// NOTE - ไม่ตั้ง APP_ENV มาจะใช้ .env.test.sqlite ที่เป็น SQLite ในหน่วยความจำ go test ได้เลยไม่ต้องมี Postgres
+// APP_ENV=test.localhost ใช้ Postgres ในเครื่อง CI ตั้ง APP_ENV=test ไปใช้ Postgres ตัวจริง ต่อครั้งเดียวทั้ง package ไม่งั้น ":memory:" จะได้ DB ว่างใหม่ทุก setUp
+func connectTestDB() {
+	if config.TestDB != nil {
+		return
+	}
+	if os.Getenv("APP_ENV") == "" {
+		os.Setenv("APP_ENV", "test.sqlite")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load test config: %v", err)
+	}
+	config.ConnectTestDB(cfg.Database)
+}
+
 func setUpAppTask() *fiber.App {
-	config.LoadEnv()
-	config.ConnectTestDB()
-	jwtUtil := utils.NewJwt()
+	connectTestDB()
+	jwtUtil := testJwt
 	hashUtil := utils.NewHash()
 
 	userRepo := repositories.NewUserRepository(config.TestDB)
 	taskRepo := repositories.NewTaskRepository(config.TestDB)
 
-	taskService := services.NewTaskService(taskRepo,userRepo, jwtUtil)
-	userService := services.NewUserService(userRepo, hashUtil, jwtUtil)
+	taskService := services.NewTaskService(taskRepo,userRepo, repositories.NewUnitOfWork(config.TestDB), metrics.New())
+	auditService := services.NewAuditService(userRepo, repositories.NewAuditLogRepository(config.TestDB), metrics.New())
+	sessionService := services.NewSessionService(userRepo, repositories.NewSessionRepository(config.TestDB), jwtUtil, auditService)
+	userService := services.NewUserService(userRepo, hashUtil, jwtUtil, services.NewLoginThrottle(repositories.NewLoginAttemptMemoryRepository()), sessionService, auditService)
+	testAccessTokenService = services.NewAccessTokenService(userRepo, repositories.NewAccessTokenRepository(config.TestDB), auditService)
 
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, testCookies)
 	taskHandler := handlers.NewTaskHandler(taskService)
 
+	// NOTE - ใช้ middleware ตัวจริงเหมือน routes.SetupRoutes ทั้ง cookie และ personal access token ต้องผ่านได้
+	auth := middleware.NewAuthMiddleware(userRepo, jwtUtil, testAccessTokenService, sessionService, testCookies)
+	tasksRead := middleware.RequireScope(services.ScopeTasksRead)
+	tasksWrite := middleware.RequireScope(services.ScopeTasksWrite)
+
 	app := fiber.New()
 
 	app.Post("/user/register", userHandler.RegisterUser)
 
-	app.Get("/task", taskHandler.GetAllTask)
-	app.Post("/task", taskHandler.CreateTask)
-	app.Get("/task/complete", taskHandler.GetCompleteTask)
-	app.Get("/task/pending", taskHandler.GetPendingTask)
-	app.Get("/task/overdue", taskHandler.GetOverdueTask)
+	app.Get("/task", auth, tasksRead, taskHandler.GetAllTask)
+	app.Post("/task", auth, tasksWrite, taskHandler.CreateTask)
+	app.Get("/task/complete", auth, tasksRead, taskHandler.GetCompleteTask)
+	app.Get("/task/pending", auth, tasksRead, taskHandler.GetPendingTask)
+	app.Get("/task/overdue", auth, tasksRead, taskHandler.GetOverdueTask)
+	app.Put("/task/:id", auth, tasksWrite, taskHandler.UpdateTask)
 	return app
 }
 
+var testAccessTokenService *services.AccessTokenService
+
 func clearDataBaseTask(){
 	if err := config.TestDB.Exec("DELETE FROM tasks").Error; err != nil {
 		log.Fatalf("Failed to clear test tasks database: %v", err)
 	}
+	if err := config.TestDB.Exec("DELETE FROM access_tokens").Error; err != nil {
+		log.Fatalf("Failed to clear test access tokens database: %v", err)
+	}
 	if err := config.TestDB.Exec("DELETE FROM users").Error; err != nil {
 		log.Fatalf("Failed to clear test users database: %v", err)
 	}
@@ -57,8 +105,7 @@ func clearDataBaseTask(){
 
 // NOTE - Create Fucntion 
 func createJWT(email string) (string, error) {
-	jwtUtil := utils.NewJwt()
-	return jwtUtil.GenerateJWT(email)
+	return testJwt.GenerateSessionJWT(email, "")
 }
 
 func registerUser(t *testing.T, email string){
@@ -198,10 +245,10 @@ func TestGetAllTaskIntegration(t *testing.T){
 		res,err := app.Test(req)
 
 		assert.NoError(t, err)
-		assert.Equal(t,fiber.StatusBadRequest,res.StatusCode)
+		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 		
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"Fail To Check Email")
+		assert.Contains(t,string(body),"Invalid token claims")
 		clearDataBaseTask() 
 	})
 
@@ -250,7 +297,7 @@ func TestGetCompleteTask(t *testing.T){
 		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"User not authenticated")
+		assert.Contains(t,string(body),"Unauthorized")
 		clearDataBaseTask()
 	})
 	
@@ -272,10 +319,10 @@ func TestGetCompleteTask(t *testing.T){
 		res,err := app.Test(req)
 
 		assert.NoError(t, err)
-		assert.Equal(t,fiber.StatusBadRequest,res.StatusCode)
+		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"Fail To Check Email")
+		assert.Contains(t,string(body),"Invalid token claims")
 		clearDataBaseTask()
 	})
 }
@@ -322,7 +369,7 @@ func TestGetPendingTask(t *testing.T){
 		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"User not authenticated")
+		assert.Contains(t,string(body),"Unauthorized")
 		clearDataBaseTask()
 	})
 	
@@ -344,10 +391,10 @@ func TestGetPendingTask(t *testing.T){
 		res,err := app.Test(req)
 
 		assert.NoError(t, err)
-		assert.Equal(t,fiber.StatusBadRequest,res.StatusCode)
+		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"Fail To Check Email")
+		assert.Contains(t,string(body),"Invalid token claims")
 		clearDataBaseTask()
 	})
 }
@@ -395,7 +442,7 @@ func TestGetOverdueTask(t *testing.T){
 		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"User not authenticated")
+		assert.Contains(t,string(body),"Unauthorized")
 		clearDataBaseTask()
 	})
 	
@@ -417,10 +464,89 @@ func TestGetOverdueTask(t *testing.T){
 		res,err := app.Test(req)
 
 		assert.NoError(t, err)
-		assert.Equal(t,fiber.StatusBadRequest,res.StatusCode)
+		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"Fail To Check Email")
+		assert.Contains(t,string(body),"Invalid token claims")
 		clearDataBaseTask()
 	})
-}
\ No newline at end of file
+}
+// NOTE - personal access token ต้องผ่าน AuthMiddleware -> RequireScope -> handler ได้เหมือน cookie
+func TestTaskWithAccessTokenIntegration(t *testing.T) {
+	app := setUpAppTask()
+	email := fmt.Sprintf("test_integration_%s@gmail.com", uuid.NewString())
+	registerUser(t, email)
+	defer clearDataBaseTask()
+
+	ctx := context.Background()
+	client := services.ClientInfo{IP: "127.0.0.1"}
+	writeToken, _, err := testAccessTokenService.CreateToken(ctx, email, "ci", []string{services.ScopeTasksRead, services.ScopeTasksWrite}, 1, client)
+	if err != nil {
+		t.Fatalf("Failed to create access token: %v", err)
+	}
+	readToken, _, err := testAccessTokenService.CreateToken(ctx, email, "dashboard", []string{services.ScopeTasksRead}, 1, client)
+	if err != nil {
+		t.Fatalf("Failed to create access token: %v", err)
+	}
+
+	send := func(method string, url string, body string, token string) (int, string) {
+		req := httptest.NewRequest(method, url, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		resBody, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(resBody)
+	}
+
+	t.Run("Create task with tasks:write", func(t *testing.T) {
+		status, body := send("POST", "/task", `{"title":"From CI","description":"Created with a token"}`, writeToken)
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Contains(t, body, "create task success")
+	})
+
+	t.Run("Read tasks with tasks:read", func(t *testing.T) {
+		status, body := send("GET", "/task", "", readToken)
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Contains(t, body, "From CI")
+	})
+
+	t.Run("Update task with tasks:write", func(t *testing.T) {
+		var task models.Tasks
+		assert.NoError(t, config.TestDB.Where("title = ?", "From CI").First(&task).Error)
+
+		status, _ := send("PUT", fmt.Sprintf("/task/%d", task.ID), `{"completed":true}`, writeToken)
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.NoError(t, config.TestDB.First(&task, task.ID).Error)
+		assert.True(t, task.Completed)
+	})
+
+	t.Run("Missing scope is forbidden", func(t *testing.T) {
+		status, body := send("POST", "/task", `{"title":"Nope","description":"Read only token"}`, readToken)
+
+		assert.Equal(t, fiber.StatusForbidden, status)
+		assert.Contains(t, body, "tasks:write")
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		status, _ := send("GET", "/task", "", services.AccessTokenPrefix+"unknown")
+
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+
+	// NOTE - PasswordService เรียกตัวนี้ตอนเปลี่ยน/reset password
+	t.Run("Every token stops working once revoked for the user", func(t *testing.T) {
+		var user models.Users
+		assert.NoError(t, config.TestDB.Where("email = ?", email).First(&user).Error)
+
+		assert.NoError(t, repositories.NewAccessTokenRepository(config.TestDB).RevokeAccessTokensByUserId(ctx, user.ID))
+
+		status, _ := send("GET", "/task", "", writeToken)
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		status, _ = send("GET", "/task", "", readToken)
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+}
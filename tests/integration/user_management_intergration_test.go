@@ -12,6 +12,9 @@ import (
 
 	"github.com/Beluga-Whale/management-api/config"
 	"github.com/Beluga-Whale/management-api/internal/handlers"
+	"github.com/Beluga-Whale/management-api/internal/metrics"
+	"github.com/Beluga-Whale/management-api/internal/middleware"
+	"github.com/Beluga-Whale/management-api/internal/models"
 	"github.com/Beluga-Whale/management-api/internal/repositories"
 	"github.com/Beluga-Whale/management-api/internal/services"
 	"github.com/Beluga-Whale/management-api/internal/utils"
@@ -21,22 +24,23 @@ import (
 )
 
 func setUpAppUser() *fiber.App {
-	config.LoadEnv()
-	config.ConnectTestDB()
+	connectTestDB()
 	hashUtil := utils.NewHash()
-	jwtUtil := utils.NewJwt()
+	jwtUtil := testJwt
 
 	userRepo := repositories.NewUserRepository(config.TestDB)
-	userService := services.NewUserService(userRepo, hashUtil, jwtUtil)
-	userHandler := handlers.NewUserHandler(userService)
-
-
+	auditService := services.NewAuditService(userRepo, repositories.NewAuditLogRepository(config.TestDB), metrics.New())
+	sessionService := services.NewSessionService(userRepo, repositories.NewSessionRepository(config.TestDB), jwtUtil, auditService)
+	userService := services.NewUserService(userRepo, hashUtil, jwtUtil, services.NewLoginThrottle(repositories.NewLoginAttemptMemoryRepository()), sessionService, auditService)
+	userHandler := handlers.NewUserHandler(userService, testCookies)
+	accessTokenService := services.NewAccessTokenService(userRepo, repositories.NewAccessTokenRepository(config.TestDB), auditService)
+	auth := middleware.NewAuthMiddleware(userRepo, jwtUtil, accessTokenService, sessionService, testCookies)
 
 	app := fiber.New()
 
 	app.Post("/user/register", userHandler.RegisterUser)
 	app.Post("/user/login", userHandler.Login)
-	app.Put("/user/:id", userHandler.EditUser)
+	app.Put("/user/:id", auth, middleware.RequireScope(services.ScopeUserWrite), userHandler.EditUser)
 
 	return app
 }
@@ -87,6 +91,27 @@ func TestUserRegisterIntegration(t *testing.T){
 		clearDataBaseUser()
 	})
 
+	t.Run("Test User Registration ignores role", func(t *testing.T) {
+		app := setUpAppUser()
+
+		email := fmt.Sprintf("test_integration_%s@gmail.com", uuid.NewString())
+		reqBody := []byte(fmt.Sprintf(`{"email":"%s","password":"password1234","name":"Test User","role":"admin","TwoFactorEnabled":true}`, email))
+
+		req := httptest.NewRequest("POST", "/user/register", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t,fiber.StatusCreated,res.StatusCode)
+
+		var stored models.Users
+		assert.NoError(t, config.TestDB.Where("email = ?", email).First(&stored).Error)
+		assert.Equal(t, models.User, stored.Role)
+		assert.False(t, stored.TwoFactorEnabled)
+		clearDataBaseUser()
+	})
+
 	t.Run("Test User Registration Success Already Exist", func(t *testing.T) {
 		app := setUpAppUser()
 	
@@ -213,10 +238,10 @@ func TestEditUserIntegration(t *testing.T){
 		res,err := app.Test(req)
 
 		assert.NoError(t, err)	
-		assert.Equal(t,fiber.StatusBadRequest,res.StatusCode)
+		assert.Equal(t,fiber.StatusUnauthorized,res.StatusCode)
 		
 		body,_ := io.ReadAll(res.Body)
-		assert.Contains(t,string(body),"Invalid request")
+		assert.Contains(t,string(body),"Invalid token claims")
 		clearDataBaseUser()
 	})
 